@@ -12,6 +12,10 @@ import (
 	"kek-backend/internal/config"
 	"kek-backend/internal/database"
 	"kek-backend/internal/metric"
+	"kek-backend/internal/notify"
+	"kek-backend/internal/price"
+	priceDB "kek-backend/internal/price/database"
+	"kek-backend/internal/stream"
 	"kek-backend/pkg/logging"
 	"net/http"
 	"time"
@@ -84,20 +88,36 @@ func runApplication() {
 			// setup account packages
 			accountDB.NewAccountDB,
 			account.NewAuthMiddleware,
+			account.NewStreamAuthMiddleware,
 			account.NewHandler,
 			// setup article packages
 			articleDB.NewArticleDB,
 			article.NewHandler,
+			// setup price packages
+			priceDB.NewPriceDB,
+			price.NewHandler,
+			// setup notification packages
+			notify.NewFCMClient,
+			notify.NewNotifiers,
+			// setup stream packages
+			stream.NewHub,
+			stream.NewHandler,
 			// setup alert packages
 			alertDB.NewAlertDB,
 			alert.NewHandler,
+			alert.NewPriceSource,
+			alert.NewScanner,
+			alert.NewDispatcher,
 			// server
 			newServer,
 		),
 		fx.Invoke(
 			account.RouteV1,
 			article.RouteV1,
+			price.RouteV1,
 			alert.RouteV1,
+			stream.RouteV1,
+			alert.StartCron,
 			printAppInfo,
 		),
 	)
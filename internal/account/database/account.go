@@ -5,6 +5,7 @@ import (
 	"kek-backend/internal/account/model"
 	"kek-backend/internal/database"
 	"kek-backend/pkg/logging"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -14,11 +15,41 @@ type AccountDB interface {
 	// Save saves a given account
 	Save(ctx context.Context, account *model.Account) error
 
-	// Update updates a given account
+	// Update updates non empty profile fields of a given account.
+	// The webhook url and secret are always written so that an empty webhook clears it
 	Update(ctx context.Context, email string, account *model.Account) error
 
 	// FindByEmail returns an account with given email if exist
 	FindByEmail(ctx context.Context, email string) (*model.Account, error)
+
+	// UpdatePreferences replaces notification preferences of an account with given id
+	UpdatePreferences(ctx context.Context, accountId uint, preferences *model.Preferences) error
+
+	// SaveDevice saves a given device, moving it to the account of the device if the token is already registered
+	SaveDevice(ctx context.Context, device *model.AccountDevice) error
+
+	// FindDevices returns devices of an account with given id
+	FindDevices(ctx context.Context, accountId uint) ([]*model.AccountDevice, error)
+
+	// DeleteDevice deletes a device with given id of an account.
+	// database.ErrNotFound is returned if not exist
+	DeleteDevice(ctx context.Context, accountId, id uint) error
+
+	// DeleteDevicesByToken deletes devices with given tokens
+	DeleteDevicesByToken(ctx context.Context, tokens []string) error
+
+	// SaveInboxItem saves a given inbox item unless the account already has an item of the alert event
+	SaveInboxItem(ctx context.Context, item *model.InboxItem) error
+
+	// FindInboxItems returns inbox items of an account with given criteria from the newest
+	FindInboxItems(ctx context.Context, criteria IterateInboxCriteria) ([]*model.InboxItem, error)
+
+	// MarkInboxItemsRead marks unread inbox items of an account with given ids as read at readAt.
+	// All unread items of the account are marked if ids is nil
+	MarkInboxItemsRead(ctx context.Context, accountId uint, ids []uint, readAt time.Time) error
+
+	// CountUnreadInboxItems returns the number of unread inbox items of an account
+	CountUnreadInboxItems(ctx context.Context, accountId uint) (int64, error)
 }
 
 type accountDB struct {
@@ -45,7 +76,10 @@ func (a *accountDB) Update(ctx context.Context, email string, account *model.Acc
 	db := database.FromContext(ctx, a.db)
 	logger.Debugw("account.db.Update", "account", account)
 
-	fields := make(map[string]interface{})
+	fields := map[string]interface{}{
+		"webhook_url":    account.WebhookURL,
+		"webhook_secret": account.WebhookSecret,
+	}
 	if account.Username != "" {
 		fields["username"] = account.Username
 	}
@@ -58,9 +92,6 @@ func (a *accountDB) Update(ctx context.Context, email string, account *model.Acc
 	if account.Image != "" {
 		fields["image"] = account.Image
 	}
-	if account.Token != "" {
-		fields["token"] = account.Token
-	}
 
 	chain := db.WithContext(ctx).
 		Model(&model.Account{}).
@@ -76,6 +107,30 @@ func (a *accountDB) Update(ctx context.Context, email string, account *model.Acc
 	return nil
 }
 
+func (a *accountDB) UpdatePreferences(ctx context.Context, accountId uint, preferences *model.Preferences) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("account.db.UpdatePreferences", "accountId", accountId, "preferences", preferences)
+
+	chain := db.WithContext(ctx).
+		Model(&model.Account{}).
+		Where("id = ?", accountId).
+		UpdateColumns(map[string]interface{}{
+			"default_channels": preferences.DefaultChannels,
+			"timezone":         preferences.Timezone,
+			"quiet_hours":      preferences.QuietHours,
+			"max_per_hour":     preferences.MaxPerHour,
+		})
+	if chain.Error != nil {
+		logger.Error("account.db.UpdatePreferences failed to update", "err", chain.Error)
+		return chain.Error
+	}
+	if chain.RowsAffected == 0 {
+		return database.ErrNotFound
+	}
+	return nil
+}
+
 func (a *accountDB) FindByEmail(ctx context.Context, email string) (*model.Account, error) {
 	logger := logging.FromContext(ctx)
 	db := database.FromContext(ctx, a.db)
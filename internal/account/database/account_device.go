@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"kek-backend/internal/account/model"
+	"kek-backend/internal/database"
+	"kek-backend/pkg/logging"
+
+	"gorm.io/gorm/clause"
+)
+
+func (a *accountDB) SaveDevice(ctx context.Context, device *model.AccountDevice) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("account.db.SaveDevice", "accountId", device.AccountID, "platform", device.Platform)
+
+	// a token registered again moves to the account and is seen again
+	err := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"account_id", "platform", "last_seen_at"}),
+	}).Create(device).Error
+	if err != nil {
+		logger.Errorw("account.db.SaveDevice failed to save device", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (a *accountDB) FindDevices(ctx context.Context, accountId uint) ([]*model.AccountDevice, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("account.db.FindDevices", "accountId", accountId)
+
+	var ret []*model.AccountDevice
+	err := db.WithContext(ctx).
+		Where("account_id = ?", accountId).
+		Order("id ASC").
+		Find(&ret).Error
+	if err != nil {
+		logger.Errorw("account.db.FindDevices failed to find devices", "err", err)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (a *accountDB) DeleteDevice(ctx context.Context, accountId, id uint) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("account.db.DeleteDevice", "accountId", accountId, "id", id)
+
+	chain := db.WithContext(ctx).
+		Where("id = ? AND account_id = ?", id, accountId).
+		Delete(&model.AccountDevice{})
+	if chain.Error != nil {
+		logger.Errorw("account.db.DeleteDevice failed to delete device", "err", chain.Error)
+		return chain.Error
+	}
+	if chain.RowsAffected == 0 {
+		logger.Error("account.db.DeleteDevice failed to delete device because not found")
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+func (a *accountDB) DeleteDevicesByToken(ctx context.Context, tokens []string) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("account.db.DeleteDevicesByToken", "count", len(tokens))
+
+	if len(tokens) == 0 {
+		return nil
+	}
+	if err := db.WithContext(ctx).Where("token IN ?", tokens).Delete(&model.AccountDevice{}).Error; err != nil {
+		logger.Errorw("account.db.DeleteDevicesByToken failed to delete devices", "err", err)
+		return err
+	}
+	return nil
+}
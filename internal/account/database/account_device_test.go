@@ -0,0 +1,121 @@
+package database
+
+import (
+	"kek-backend/internal/account/model"
+	"kek-backend/internal/database"
+	"time"
+)
+
+func (s *DBSuite) TestSaveDevice() {
+	// given
+	acc := s.newAccount("user1")
+	device := newDevice(acc.ID, "token1", time.Now())
+
+	// when
+	err := s.db.SaveDevice(nil, device)
+
+	// then
+	s.NoError(err)
+	s.NotZero(device.ID)
+	devices, err := s.db.FindDevices(nil, acc.ID)
+	s.NoError(err)
+	s.Len(devices, 1)
+	s.Equal(device.ID, devices[0].ID)
+	s.Equal(model.PlatformAndroid, devices[0].Platform)
+	s.Equal("token1", devices[0].Token)
+}
+
+func (s *DBSuite) TestSaveDevice_MoveRegisteredToken() {
+	// given
+	acc1 := s.newAccount("user1")
+	acc2 := s.newAccount("user2")
+	now := time.Now()
+	s.NoError(s.db.SaveDevice(nil, newDevice(acc1.ID, "token1", now.Add(-time.Hour))))
+	device := newDevice(acc2.ID, "token1", now)
+	device.Platform = model.PlatformIOS
+
+	// when
+	err := s.db.SaveDevice(nil, device)
+
+	// then
+	s.NoError(err)
+	devices, err := s.db.FindDevices(nil, acc1.ID)
+	s.NoError(err)
+	s.Empty(devices)
+	devices, err = s.db.FindDevices(nil, acc2.ID)
+	s.NoError(err)
+	s.Len(devices, 1)
+	s.Equal(model.PlatformIOS, devices[0].Platform)
+	s.WithinDuration(now, devices[0].LastSeenAt, time.Second)
+}
+
+func (s *DBSuite) TestDeleteDevice() {
+	// given
+	acc := s.newAccount("user1")
+	device1 := newDevice(acc.ID, "token1", time.Now())
+	device2 := newDevice(acc.ID, "token2", time.Now())
+	s.NoError(s.db.SaveDevice(nil, device1))
+	s.NoError(s.db.SaveDevice(nil, device2))
+
+	// when
+	err := s.db.DeleteDevice(nil, acc.ID, device1.ID)
+
+	// then
+	s.NoError(err)
+	devices, err := s.db.FindDevices(nil, acc.ID)
+	s.NoError(err)
+	s.Len(devices, 1)
+	s.Equal(device2.ID, devices[0].ID)
+}
+
+func (s *DBSuite) TestDeleteDevice_FailIfNotOwner() {
+	// given
+	acc1 := s.newAccount("user1")
+	acc2 := s.newAccount("user2")
+	device := newDevice(acc1.ID, "token1", time.Now())
+	s.NoError(s.db.SaveDevice(nil, device))
+
+	// when
+	err := s.db.DeleteDevice(nil, acc2.ID, device.ID)
+
+	// then
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestDeleteDevicesByToken() {
+	// given
+	acc := s.newAccount("user1")
+	for _, token := range []string{"token1", "token2", "token3"} {
+		s.NoError(s.db.SaveDevice(nil, newDevice(acc.ID, token, time.Now())))
+	}
+
+	// when
+	err := s.db.DeleteDevicesByToken(nil, []string{"token1", "token3", "unknown"})
+
+	// then
+	s.NoError(err)
+	devices, err := s.db.FindDevices(nil, acc.ID)
+	s.NoError(err)
+	s.Len(devices, 1)
+	s.Equal("token2", devices[0].Token)
+}
+
+func (s *DBSuite) newAccount(username string) *model.Account {
+	acc := model.Account{
+		Username: username,
+		Email:    username + "@gmail.com",
+		Password: "pass",
+	}
+	s.NoError(s.db.Save(nil, &acc))
+	return &acc
+}
+
+func newDevice(accountId uint, token string, seenAt time.Time) *model.AccountDevice {
+	return &model.AccountDevice{
+		AccountID:  accountId,
+		Platform:   model.PlatformAndroid,
+		Token:      token,
+		CreatedAt:  seenAt,
+		LastSeenAt: seenAt,
+	}
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"kek-backend/internal/account/model"
+	"kek-backend/internal/database"
+	"kek-backend/pkg/logging"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+type IterateInboxCriteria struct {
+	AccountID uint
+	// Unread filters items not read yet if true
+	Unread bool
+	// Cursor is the last item id of the previous page, the first page if zero
+	Cursor uint
+	Limit  uint
+}
+
+func (a *accountDB) SaveInboxItem(ctx context.Context, item *model.InboxItem) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("account.db.SaveInboxItem", "accountId", item.AccountID, "alertEventId", item.AlertEventID)
+
+	// an alert event delivered over several actions is kept once
+	err := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_id"}, {Name: "alert_event_id"}},
+		DoNothing: true,
+	}).Create(item).Error
+	if err != nil {
+		logger.Errorw("account.db.SaveInboxItem failed to save inbox item", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (a *accountDB) FindInboxItems(ctx context.Context, criteria IterateInboxCriteria) ([]*model.InboxItem, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("account.db.FindInboxItems", "criteria", criteria)
+
+	chain := db.WithContext(ctx).Where("account_id = ?", criteria.AccountID)
+	if criteria.Unread {
+		chain = chain.Where("read_at IS NULL")
+	}
+	if criteria.Cursor > 0 {
+		chain = chain.Where("id < ?", criteria.Cursor)
+	}
+	var ret []*model.InboxItem
+	if err := chain.Order("id DESC").Limit(int(criteria.Limit)).Find(&ret).Error; err != nil {
+		logger.Errorw("account.db.FindInboxItems failed to find inbox items", "err", err)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (a *accountDB) MarkInboxItemsRead(ctx context.Context, accountId uint, ids []uint, readAt time.Time) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("account.db.MarkInboxItemsRead", "accountId", accountId, "ids", ids)
+
+	chain := db.WithContext(ctx).Model(&model.InboxItem{}).Where("account_id = ? AND read_at IS NULL", accountId)
+	if ids != nil {
+		chain = chain.Where("id IN ?", ids)
+	}
+	if err := chain.UpdateColumn("read_at", readAt).Error; err != nil {
+		logger.Errorw("account.db.MarkInboxItemsRead failed to update inbox items", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (a *accountDB) CountUnreadInboxItems(ctx context.Context, accountId uint) (int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("account.db.CountUnreadInboxItems", "accountId", accountId)
+
+	var count int64
+	err := db.WithContext(ctx).Model(&model.InboxItem{}).
+		Where("account_id = ? AND read_at IS NULL", accountId).
+		Count(&count).Error
+	if err != nil {
+		logger.Errorw("account.db.CountUnreadInboxItems failed to count inbox items", "err", err)
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"kek-backend/internal/account/model"
+	"time"
+)
+
+func (s *DBSuite) TestSaveInboxItem() {
+	// given
+	acc := s.newAccount("user1")
+	item := newInboxItem(acc.ID, 10)
+
+	// when
+	err := s.db.SaveInboxItem(nil, item)
+
+	// then
+	s.NoError(err)
+	s.NotZero(item.ID)
+	// an item of the same alert event is kept once
+	s.NoError(s.db.SaveInboxItem(nil, newInboxItem(acc.ID, 10)))
+	finds, err := s.db.FindInboxItems(nil, IterateInboxCriteria{AccountID: acc.ID, Limit: 10})
+	s.NoError(err)
+	s.Len(finds, 1)
+	s.Equal(item.ID, finds[0].ID)
+	s.False(finds[0].IsRead())
+}
+
+func (s *DBSuite) TestFindInboxItems() {
+	// given
+	acc := s.newAccount("user1")
+	other := s.newAccount("user2")
+	var items []*model.InboxItem
+	for i := 0; i < 4; i++ {
+		item := newInboxItem(acc.ID, uint(i+1))
+		s.NoError(s.db.SaveInboxItem(nil, item))
+		items = append(items, item)
+	}
+	s.NoError(s.db.SaveInboxItem(nil, newInboxItem(other.ID, 1)))
+	s.NoError(s.db.MarkInboxItemsRead(nil, acc.ID, []uint{items[2].ID}, time.Now()))
+
+	// when
+	first, err := s.db.FindInboxItems(nil, IterateInboxCriteria{AccountID: acc.ID, Limit: 2})
+	s.NoError(err)
+	next, err := s.db.FindInboxItems(nil, IterateInboxCriteria{AccountID: acc.ID, Cursor: first[1].ID, Limit: 2})
+	s.NoError(err)
+	unread, err := s.db.FindInboxItems(nil, IterateInboxCriteria{AccountID: acc.ID, Unread: true, Limit: 10})
+	s.NoError(err)
+
+	// then
+	s.Equal([]uint{items[3].ID, items[2].ID}, inboxItemIDs(first))
+	s.Equal([]uint{items[1].ID, items[0].ID}, inboxItemIDs(next))
+	s.Equal([]uint{items[3].ID, items[1].ID, items[0].ID}, inboxItemIDs(unread))
+}
+
+func (s *DBSuite) TestMarkInboxItemsRead() {
+	// given
+	acc := s.newAccount("user1")
+	other := s.newAccount("user2")
+	var items []*model.InboxItem
+	for i := 0; i < 3; i++ {
+		item := newInboxItem(acc.ID, uint(i+1))
+		s.NoError(s.db.SaveInboxItem(nil, item))
+		items = append(items, item)
+	}
+	otherItem := newInboxItem(other.ID, 1)
+	s.NoError(s.db.SaveInboxItem(nil, otherItem))
+
+	// when
+	err := s.db.MarkInboxItemsRead(nil, acc.ID, []uint{items[0].ID, otherItem.ID}, time.Now())
+
+	// then
+	s.NoError(err)
+	count, err := s.db.CountUnreadInboxItems(nil, acc.ID)
+	s.NoError(err)
+	s.Equal(int64(2), count)
+	count, err = s.db.CountUnreadInboxItems(nil, other.ID)
+	s.NoError(err)
+	s.Equal(int64(1), count)
+
+	// mark all
+	s.NoError(s.db.MarkInboxItemsRead(nil, acc.ID, nil, time.Now()))
+	count, err = s.db.CountUnreadInboxItems(nil, acc.ID)
+	s.NoError(err)
+	s.Zero(count)
+}
+
+func newInboxItem(accountId, alertEventId uint) *model.InboxItem {
+	return &model.InboxItem{
+		AccountID:     accountId,
+		AlertEventID:  alertEventId,
+		AlertID:       1,
+		Slug:          "weth-above-1500",
+		Title:         "WETH above 1500",
+		Body:          "WETH is 1520.5",
+		Condition:     "price above 1500",
+		ObservedPrice: 1520.5,
+		TriggeredAt:   time.Now(),
+	}
+}
+
+func inboxItemIDs(items []*model.InboxItem) []uint {
+	var ids []uint
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
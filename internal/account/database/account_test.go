@@ -27,6 +27,8 @@ func (s *DBSuite) SetupSuite() {
 }
 
 func (s *DBSuite) SetupTest() {
+	s.originDB.Where("id > 0").Delete(&model.InboxItem{})
+	s.originDB.Where("id > 0").Delete(&model.AccountDevice{})
 	s.originDB.Where("id > 0").Delete(&model.Account{})
 }
 
@@ -97,6 +99,9 @@ func (s *DBSuite) TestUpdate() {
 		Email:    "updated-email@gamil.com",
 		Bio:      "updated-bio",
 		Image:    "updated-image",
+		// webhook
+		WebhookURL:    "https://bot.example.com/alerts",
+		WebhookSecret: "0123456789abcdef",
 	}
 
 	// when
@@ -115,6 +120,31 @@ func (s *DBSuite) TestUpdate() {
 	s.Equal(updated.Username, find.Username)
 	s.Equal(updated.Bio, find.Bio)
 	s.Equal(updated.Image, find.Image)
+	s.Equal(updated.WebhookURL, find.WebhookURL)
+	s.Equal(updated.WebhookSecret, find.WebhookSecret)
+}
+
+func (s *DBSuite) TestUpdate_ClearWebhook() {
+	// given
+	acc := model.Account{
+		Username:      "user1",
+		Email:         "user@gmail.com",
+		Password:      "pass1",
+		WebhookURL:    "https://bot.example.com/alerts",
+		WebhookSecret: "0123456789abcdef",
+	}
+	s.NoError(s.db.Save(nil, &acc))
+
+	// when
+	err := s.db.Update(nil, acc.Email, &model.Account{Username: acc.Username})
+
+	// then
+	s.NoError(err)
+	find, err := s.db.FindByEmail(nil, acc.Email)
+	s.NoError(err)
+	s.Equal(acc.Username, find.Username)
+	s.Empty(find.WebhookURL)
+	s.Empty(find.WebhookSecret)
 }
 
 func (s *DBSuite) TestUpdate_FailIfNotExist() {
@@ -128,6 +158,39 @@ func (s *DBSuite) TestUpdate_FailIfNotExist() {
 	s.Equal(database.ErrNotFound, err)
 }
 
+func (s *DBSuite) TestUpdatePreferences() {
+	// given
+	acc := model.Account{
+		Username: "user1",
+		Email:    "user@gmail.com",
+		Password: "pass1",
+	}
+	s.NoError(s.db.Save(nil, &acc))
+	preferences := model.Preferences{
+		DefaultChannels: "push,email",
+		Timezone:        "Asia/Seoul",
+		QuietHours:      "22:00-07:00",
+		MaxPerHour:      5,
+	}
+
+	// when
+	err := s.db.UpdatePreferences(nil, acc.ID, &preferences)
+
+	// then
+	s.NoError(err)
+	find, err := s.db.FindByEmail(nil, acc.Email)
+	s.NoError(err)
+	s.Equal(preferences, find.Preferences)
+}
+
+func (s *DBSuite) TestUpdatePreferences_FailIfNotExist() {
+	// when
+	err := s.db.UpdatePreferences(nil, 1000, &model.Preferences{MaxPerHour: 1})
+
+	// then
+	s.Equal(database.ErrNotFound, err)
+}
+
 func (s *DBSuite) TestFindByEmail() {
 	// given
 	now := time.Now()
@@ -4,10 +4,13 @@ package mocks
 
 import (
 	context "context"
+	database "kek-backend/internal/account/database"
 
 	mock "github.com/stretchr/testify/mock"
 
 	model "kek-backend/internal/account/model"
+
+	time "time"
 )
 
 // AccountDB is an autogenerated mock type for the AccountDB type
@@ -15,6 +18,55 @@ type AccountDB struct {
 	mock.Mock
 }
 
+// CountUnreadInboxItems provides a mock function with given fields: ctx, accountId
+func (_m *AccountDB) CountUnreadInboxItems(ctx context.Context, accountId uint) (int64, error) {
+	ret := _m.Called(ctx, accountId)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, uint) int64); ok {
+		r0 = rf(ctx, accountId)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, accountId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteDevice provides a mock function with given fields: ctx, accountId, id
+func (_m *AccountDB) DeleteDevice(ctx context.Context, accountId uint, id uint) error {
+	ret := _m.Called(ctx, accountId, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(ctx, accountId, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteDevicesByToken provides a mock function with given fields: ctx, tokens
+func (_m *AccountDB) DeleteDevicesByToken(ctx context.Context, tokens []string) error {
+	ret := _m.Called(ctx, tokens)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) error); ok {
+		r0 = rf(ctx, tokens)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // FindByEmail provides a mock function with given fields: ctx, email
 func (_m *AccountDB) FindByEmail(ctx context.Context, email string) (*model.Account, error) {
 	ret := _m.Called(ctx, email)
@@ -38,6 +90,66 @@ func (_m *AccountDB) FindByEmail(ctx context.Context, email string) (*model.Acco
 	return r0, r1
 }
 
+// FindDevices provides a mock function with given fields: ctx, accountId
+func (_m *AccountDB) FindDevices(ctx context.Context, accountId uint) ([]*model.AccountDevice, error) {
+	ret := _m.Called(ctx, accountId)
+
+	var r0 []*model.AccountDevice
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*model.AccountDevice); ok {
+		r0 = rf(ctx, accountId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.AccountDevice)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, accountId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindInboxItems provides a mock function with given fields: ctx, criteria
+func (_m *AccountDB) FindInboxItems(ctx context.Context, criteria database.IterateInboxCriteria) ([]*model.InboxItem, error) {
+	ret := _m.Called(ctx, criteria)
+
+	var r0 []*model.InboxItem
+	if rf, ok := ret.Get(0).(func(context.Context, database.IterateInboxCriteria) []*model.InboxItem); ok {
+		r0 = rf(ctx, criteria)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.InboxItem)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, database.IterateInboxCriteria) error); ok {
+		r1 = rf(ctx, criteria)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkInboxItemsRead provides a mock function with given fields: ctx, accountId, ids, readAt
+func (_m *AccountDB) MarkInboxItemsRead(ctx context.Context, accountId uint, ids []uint, readAt time.Time) error {
+	ret := _m.Called(ctx, accountId, ids, readAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, []uint, time.Time) error); ok {
+		r0 = rf(ctx, accountId, ids, readAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Save provides a mock function with given fields: ctx, account
 func (_m *AccountDB) Save(ctx context.Context, account *model.Account) error {
 	ret := _m.Called(ctx, account)
@@ -52,6 +164,34 @@ func (_m *AccountDB) Save(ctx context.Context, account *model.Account) error {
 	return r0
 }
 
+// SaveDevice provides a mock function with given fields: ctx, device
+func (_m *AccountDB) SaveDevice(ctx context.Context, device *model.AccountDevice) error {
+	ret := _m.Called(ctx, device)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.AccountDevice) error); ok {
+		r0 = rf(ctx, device)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveInboxItem provides a mock function with given fields: ctx, item
+func (_m *AccountDB) SaveInboxItem(ctx context.Context, item *model.InboxItem) error {
+	ret := _m.Called(ctx, item)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.InboxItem) error); ok {
+		r0 = rf(ctx, item)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, email, account
 func (_m *AccountDB) Update(ctx context.Context, email string, account *model.Account) error {
 	ret := _m.Called(ctx, email, account)
@@ -65,3 +205,17 @@ func (_m *AccountDB) Update(ctx context.Context, email string, account *model.Ac
 
 	return r0
 }
+
+// UpdatePreferences provides a mock function with given fields: ctx, accountId, preferences
+func (_m *AccountDB) UpdatePreferences(ctx context.Context, accountId uint, preferences *model.Preferences) error {
+	ret := _m.Called(ctx, accountId, preferences)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, *model.Preferences) error); ok {
+		r0 = rf(ctx, accountId, preferences)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
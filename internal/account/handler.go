@@ -7,9 +7,11 @@ import (
 	"kek-backend/internal/database"
 	"kek-backend/internal/middleware"
 	"kek-backend/internal/middleware/handler"
+	"kek-backend/internal/notify"
 	"kek-backend/pkg/logging"
 	"kek-backend/pkg/validate"
 	"net/http"
+	"net/url"
 	"time"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
@@ -19,6 +21,7 @@ import (
 
 type Handler struct {
 	accountDB accountDB.AccountDB
+	notifiers *notify.Registry
 }
 
 // signUp handles POST /v1/api/users
@@ -30,7 +33,6 @@ func (h *Handler) signUp(c *gin.Context) {
 				Username string `json:"username" binding:"required"`
 				Email    string `json:"email" binding:"required,email"`
 				Password string `json:"password" binding:"required,min=5"`
-				Token    string `json:"token" binding:"required,min=5"`
 			} `json:"user"`
 		}
 		var body RequestBody
@@ -52,7 +54,6 @@ func (h *Handler) signUp(c *gin.Context) {
 			Username: body.User.Username,
 			Email:    body.User.Email,
 			Password: password,
-			Token:    body.User.Token,
 		}
 		err = h.accountDB.Save(c.Request.Context(), &acc)
 		if err != nil {
@@ -79,7 +80,13 @@ func (h *Handler) currentUser(c *gin.Context) {
 		if find.Disabled {
 			return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found current user", nil)
 		}
-		return handler.NewSuccessResponse(http.StatusOK, NewUserResponse(find))
+		unread, err := h.accountDB.CountUnreadInboxItems(c.Request.Context(), find.ID)
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		res := NewUserResponse(find)
+		res.User.UnreadCount = &unread
+		return handler.NewSuccessResponse(http.StatusOK, res)
 	})
 }
 
@@ -90,11 +97,12 @@ func (h *Handler) update(c *gin.Context) {
 		currentUser := MustCurrentUser(c)
 		type RequestBody struct {
 			User struct {
-				Username string `json:"username" binding:"omitempty"`
-				Password string `json:"password" binding:"omitempty,min=5"`
-				Bio      string `json:"bio"`
-				Image    string `json:"image"`
-				Token    string `json:"token"`
+				Username      string  `json:"username" binding:"omitempty"`
+				Password      string  `json:"password" binding:"omitempty,min=5"`
+				Bio           string  `json:"bio"`
+				Image         string  `json:"image"`
+				WebhookURL    *string `json:"webhookUrl"`
+				WebhookSecret string  `json:"webhookSecret" binding:"omitempty,min=16"`
 			} `json:"user"`
 		}
 		var body RequestBody
@@ -132,8 +140,23 @@ func (h *Handler) update(c *gin.Context) {
 		if body.User.Image != "" {
 			acc.Image = body.User.Image
 		}
-		if body.User.Token != "" {
-			acc.Token = body.User.Token
+		if body.User.WebhookSecret != "" {
+			acc.WebhookSecret = body.User.WebhookSecret
+		}
+		// an absent webhookUrl keeps the webhook and an empty one clears it with its secret
+		if body.User.WebhookURL != nil {
+			if *body.User.WebhookURL != "" && !isHTTPSURL(*body.User.WebhookURL) {
+				details := validate.NewValidationErrorDetails("webhookUrl", "webhookUrl must be an https url", *body.User.WebhookURL)
+				return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid user request in body", details)
+			}
+			acc.WebhookURL = *body.User.WebhookURL
+			if acc.WebhookURL == "" {
+				acc.WebhookSecret = ""
+			}
+		}
+		if acc.WebhookURL != "" && acc.WebhookSecret == "" {
+			details := validate.NewValidationErrorDetails("webhookSecret", "webhookSecret is required with webhookUrl", "")
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid user request in body", details)
 		}
 		err = h.accountDB.Update(c.Request.Context(), currentUser.Email, acc)
 		if err != nil {
@@ -146,6 +169,12 @@ func (h *Handler) update(c *gin.Context) {
 	})
 }
 
+// isHTTPSURL returns true if given value is an absolute https url with a host
+func isHTTPSURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
+
 // RouteV1 routes user api given config and gin.Engine
 func RouteV1(cfg *config.Config, h *Handler, r *gin.Engine, auth *jwt.GinJWTMiddleware) {
 	v1 := r.Group("v1/api")
@@ -162,11 +191,19 @@ func RouteV1(cfg *config.Config, h *Handler, r *gin.Engine, auth *jwt.GinJWTMidd
 	{
 		v1.GET("user/me", h.currentUser)
 		v1.PUT("user", h.update)
+		v1.POST("user/devices", h.saveDevice)
+		v1.GET("user/devices", h.devices)
+		v1.DELETE("user/devices/:id", h.deleteDevice)
+		v1.GET("user/preferences", h.preferences)
+		v1.PUT("user/preferences", h.updatePreferences)
+		v1.GET("user/notifications", h.inbox)
+		v1.POST("user/notifications/read", h.readInbox)
 	}
 }
 
-func NewHandler(accountDB accountDB.AccountDB) *Handler {
+func NewHandler(accountDB accountDB.AccountDB, notifiers *notify.Registry) *Handler {
 	return &Handler{
 		accountDB: accountDB,
+		notifiers: notifiers,
 	}
 }
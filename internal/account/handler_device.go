@@ -0,0 +1,91 @@
+package account
+
+import (
+	"kek-backend/internal/account/model"
+	"kek-backend/internal/database"
+	"kek-backend/internal/middleware/handler"
+	"kek-backend/pkg/logging"
+	"kek-backend/pkg/validate"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// saveDevice handles POST /v1/api/user/devices
+func (h *Handler) saveDevice(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		currentUser := MustCurrentUser(c)
+		type RequestBody struct {
+			Device struct {
+				Platform string `json:"platform" binding:"required,oneof=android ios web"`
+				Token    string `json:"token" binding:"required,min=5,max=255"`
+			} `json:"device"`
+		}
+		var body RequestBody
+		if err := c.ShouldBindJSON(&body); err != nil {
+			logger.Errorw("account.handler.saveDevice failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&body.Device, "json", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid device request in body", details)
+		}
+
+		now := time.Now()
+		device := model.AccountDevice{
+			AccountID:  currentUser.ID,
+			Platform:   body.Device.Platform,
+			Token:      body.Device.Token,
+			CreatedAt:  now,
+			LastSeenAt: now,
+		}
+		if err := h.accountDB.SaveDevice(c.Request.Context(), &device); err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusCreated, NewDeviceResponse(&device))
+	})
+}
+
+// devices handles GET /v1/api/user/devices
+func (h *Handler) devices(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		currentUser := MustCurrentUser(c)
+		devices, err := h.accountDB.FindDevices(c.Request.Context(), currentUser.ID)
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, NewDevicesResponse(devices))
+	})
+}
+
+// deleteDevice handles DELETE /v1/api/user/devices/:id
+func (h *Handler) deleteDevice(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		currentUser := MustCurrentUser(c)
+		type RequestUri struct {
+			ID uint `uri:"id" binding:"required"`
+		}
+		var uri RequestUri
+		if err := c.ShouldBindUri(&uri); err != nil {
+			logger.Errorw("account.handler.deleteDevice failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&uri, "uri", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid device request in uri", details)
+		}
+
+		err := h.accountDB.DeleteDevice(c.Request.Context(), currentUser.ID, uri.ID)
+		if err != nil {
+			if database.IsRecordNotFoundErr(err) {
+				return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found device", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, nil)
+	})
+}
@@ -0,0 +1,140 @@
+package account
+
+import (
+	"bytes"
+	"encoding/json"
+	"kek-backend/internal/account/model"
+	"kek-backend/internal/database"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/tidwall/gjson"
+)
+
+func (s *HandlerSuite) TestSaveDevice() {
+	// given
+	acc, token := s.newLoggedInAccount()
+	matcher := func(device *model.AccountDevice) bool {
+		return device.AccountID == acc.ID && device.Platform == model.PlatformIOS && device.Token == "device-token1" &&
+			!device.LastSeenAt.IsZero()
+	}
+	s.db.On("SaveDevice", mock.Anything, mock.MatchedBy(matcher)).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.AccountDevice).ID = 10
+	}).Return(nil)
+
+	// when
+	body := map[string]interface{}{
+		"device": map[string]interface{}{
+			"platform": "ios",
+			"token":    "device-token1",
+		},
+	}
+	b, _ := json.Marshal(body)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/user/devices", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "SaveDevice", mock.Anything, mock.MatchedBy(matcher))
+	s.Equal(http.StatusCreated, res.Code)
+	s.Equal(int64(10), gjson.Get(res.Body.String(), "device.id").Int())
+	s.Equal("ios", gjson.Get(res.Body.String(), "device.platform").String())
+	s.Equal("device-token1", gjson.Get(res.Body.String(), "device.token").String())
+}
+
+func (s *HandlerSuite) TestSaveDevice_FailIfInvalidPlatform() {
+	// given
+	_, token := s.newLoggedInAccount()
+
+	// when
+	body := map[string]interface{}{
+		"device": map[string]interface{}{
+			"platform": "windows",
+			"token":    "device-token1",
+		},
+	}
+	b, _ := json.Marshal(body)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/user/devices", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "SaveDevice", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.Equal("platform", gjson.Get(res.Body.String(), "errors.0.field").String())
+}
+
+func (s *HandlerSuite) TestDevices() {
+	// given
+	acc, token := s.newLoggedInAccount()
+	s.db.On("FindDevices", mock.Anything, acc.ID).Return([]*model.AccountDevice{
+		{ID: 1, AccountID: acc.ID, Platform: model.PlatformAndroid, Token: "device-token1"},
+		{ID: 2, AccountID: acc.ID, Platform: model.PlatformIOS, Token: "device-token2"},
+	}, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/user/devices", nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal(int64(2), gjson.Get(res.Body.String(), "devicesCount").Int())
+	s.Equal("device-token1", gjson.Get(res.Body.String(), "devices.0.token").String())
+	s.Equal("ios", gjson.Get(res.Body.String(), "devices.1.platform").String())
+}
+
+func (s *HandlerSuite) TestDeleteDevice() {
+	// given
+	acc, token := s.newLoggedInAccount()
+	s.db.On("DeleteDevice", mock.Anything, acc.ID, uint(1)).Return(nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/v1/api/user/devices/1", nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "DeleteDevice", mock.Anything, acc.ID, uint(1))
+	s.Equal(http.StatusOK, res.Code)
+}
+
+func (s *HandlerSuite) TestDeleteDevice_FailIfNotExist() {
+	// given
+	acc, token := s.newLoggedInAccount()
+	s.db.On("DeleteDevice", mock.Anything, acc.ID, uint(1)).Return(database.ErrNotFound)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/v1/api/user/devices/1", nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusNotFound, res.Code)
+}
+
+func (s *HandlerSuite) newLoggedInAccount() (*model.Account, string) {
+	password := "password1"
+	encodedPassword, _ := EncodePassword(password)
+	acc := model.Account{
+		ID:        1,
+		Username:  "user1",
+		Email:     "user1@gmail.com",
+		Password:  encodedPassword,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	return &acc, s.getBearerToken(&acc, password)
+}
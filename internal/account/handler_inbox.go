@@ -0,0 +1,84 @@
+package account
+
+import (
+	accountDB "kek-backend/internal/account/database"
+	"kek-backend/internal/middleware/handler"
+	"kek-backend/pkg/logging"
+	"kek-backend/pkg/validate"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// inbox handles GET /v1/api/user/notifications
+func (h *Handler) inbox(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		type QueryParameter struct {
+			Unread bool `form:"unread"`
+			Cursor uint `form:"cursor"`
+			Limit  uint `form:"limit,default=20" binding:"min=1,max=100"`
+		}
+		var query QueryParameter
+		if err := c.ShouldBindQuery(&query); err != nil {
+			logger.Errorw("account.handler.inbox failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&query, "form", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid notifications request in query", details)
+		}
+
+		currentUser := MustCurrentUser(c)
+		items, err := h.accountDB.FindInboxItems(c.Request.Context(), accountDB.IterateInboxCriteria{
+			AccountID: currentUser.ID,
+			Unread:    query.Unread,
+			Cursor:    query.Cursor,
+			Limit:     query.Limit,
+		})
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, NewInboxResponse(items, query.Limit))
+	})
+}
+
+// readInbox handles POST /v1/api/user/notifications/read
+func (h *Handler) readInbox(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		type RequestBody struct {
+			IDs []uint `json:"ids" binding:"max=100"`
+			All bool   `json:"all"`
+		}
+		var body RequestBody
+		if err := c.ShouldBindJSON(&body); err != nil {
+			logger.Errorw("account.handler.readInbox failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&body, "json", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid notifications request in body", details)
+		}
+		if !body.All && len(body.IDs) == 0 {
+			details := validate.NewValidationErrorDetails("ids", "ids are required unless all is true", body.IDs)
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid notifications request in body", details)
+		}
+
+		currentUser := MustCurrentUser(c)
+		ids := body.IDs
+		if body.All {
+			ids = nil
+		}
+		if err := h.accountDB.MarkInboxItemsRead(c.Request.Context(), currentUser.ID, ids, time.Now()); err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		unread, err := h.accountDB.CountUnreadInboxItems(c.Request.Context(), currentUser.ID)
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, &UnreadCountResponse{UnreadCount: unread})
+	})
+}
@@ -0,0 +1,131 @@
+package account
+
+import (
+	"bytes"
+	"encoding/json"
+	accountDB "kek-backend/internal/account/database"
+	"kek-backend/internal/account/model"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/tidwall/gjson"
+)
+
+func (s *HandlerSuite) TestInbox() {
+	// given
+	acc, token := s.newLoggedInAccount()
+	readAt := time.Now()
+	criteria := accountDB.IterateInboxCriteria{AccountID: acc.ID, Unread: true, Cursor: 10, Limit: 2}
+	s.db.On("FindInboxItems", mock.Anything, criteria).Return([]*model.InboxItem{
+		{ID: 9, AccountID: acc.ID, Slug: "weth-above-1500", Title: "WETH above 1500", TriggeredAt: time.Now()},
+		{ID: 7, AccountID: acc.ID, Slug: "weth-below-1000", Title: "WETH below 1000", TriggeredAt: time.Now(), ReadAt: &readAt},
+	}, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/user/notifications?unread=true&cursor=10&limit=2", nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "FindInboxItems", mock.Anything, criteria)
+	s.Equal(http.StatusOK, res.Code)
+	body := res.Body.String()
+	s.Len(gjson.Get(body, "notifications").Array(), 2)
+	s.Equal(int64(9), gjson.Get(body, "notifications.0.id").Int())
+	s.Equal("WETH above 1500", gjson.Get(body, "notifications.0.title").String())
+	s.False(gjson.Get(body, "notifications.0.read").Bool())
+	s.True(gjson.Get(body, "notifications.1.read").Bool())
+	s.Equal(int64(7), gjson.Get(body, "nextCursor").Int())
+}
+
+func (s *HandlerSuite) TestInbox_LastPage() {
+	// given
+	acc, token := s.newLoggedInAccount()
+	criteria := accountDB.IterateInboxCriteria{AccountID: acc.ID, Limit: 20}
+	s.db.On("FindInboxItems", mock.Anything, criteria).Return([]*model.InboxItem{
+		{ID: 1, AccountID: acc.ID, Slug: "weth-above-1500", Title: "WETH above 1500", TriggeredAt: time.Now()},
+	}, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/user/notifications", nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.Len(gjson.Get(res.Body.String(), "notifications").Array(), 1)
+	s.False(gjson.Get(res.Body.String(), "nextCursor").Exists())
+}
+
+func (s *HandlerSuite) TestInbox_FailIfInvalidLimit() {
+	// given
+	_, token := s.newLoggedInAccount()
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/user/notifications?limit=101", nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "FindInboxItems", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+}
+
+func (s *HandlerSuite) TestReadInbox() {
+	cases := []struct {
+		Name string
+		Body map[string]interface{}
+		IDs  []uint
+	}{
+		{Name: "ids", Body: map[string]interface{}{"ids": []uint{1, 2}}, IDs: []uint{1, 2}},
+		{Name: "all", Body: map[string]interface{}{"ids": []uint{1}, "all": true}},
+	}
+
+	for _, tc := range cases {
+		s.Run(tc.Name, func() {
+			// given
+			s.SetupTest()
+			acc, token := s.newLoggedInAccount()
+			s.db.On("MarkInboxItemsRead", mock.Anything, acc.ID, tc.IDs, mock.Anything).Return(nil)
+			s.db.On("CountUnreadInboxItems", mock.Anything, acc.ID).Return(int64(1), nil)
+
+			// when
+			b, _ := json.Marshal(tc.Body)
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/v1/api/user/notifications/read", bytes.NewBuffer(b))
+			req.Header.Add("Authorization", "Bearer "+token)
+
+			s.r.ServeHTTP(res, req)
+
+			// then
+			s.db.AssertCalled(s.T(), "MarkInboxItemsRead", mock.Anything, acc.ID, tc.IDs, mock.Anything)
+			s.Equal(http.StatusOK, res.Code)
+			s.JSONEq(`{"unreadCount": 1}`, res.Body.String())
+		})
+	}
+}
+
+func (s *HandlerSuite) TestReadInbox_FailIfNoIds() {
+	// given
+	_, token := s.newLoggedInAccount()
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/user/notifications/read", bytes.NewBufferString(`{"ids":[]}`))
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "MarkInboxItemsRead", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.Equal("ids", gjson.Get(res.Body.String(), "errors.0.field").String())
+}
@@ -0,0 +1,102 @@
+package account
+
+import (
+	"kek-backend/internal/account/model"
+	"kek-backend/internal/database"
+	"kek-backend/internal/middleware/handler"
+	"kek-backend/pkg/logging"
+	"kek-backend/pkg/validate"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// preferences handles GET /v1/api/user/preferences
+func (h *Handler) preferences(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		currentUser := MustCurrentUser(c)
+		return handler.NewSuccessResponse(http.StatusOK, NewPreferencesResponse(&currentUser.Preferences))
+	})
+}
+
+// updatePreferences handles PUT /v1/api/user/preferences
+func (h *Handler) updatePreferences(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		currentUser := MustCurrentUser(c)
+		type RequestBody struct {
+			Preferences struct {
+				DefaultChannels []string `json:"defaultChannels"`
+				Timezone        string   `json:"timezone"`
+				QuietHours      []string `json:"quietHours"`
+				MaxPerHour      int      `json:"maxPerHour" binding:"min=0"`
+			} `json:"preferences"`
+		}
+		var body RequestBody
+		if err := c.ShouldBindJSON(&body); err != nil {
+			logger.Errorw("account.handler.updatePreferences failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&body.Preferences, "json", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid preferences request in body", details)
+		}
+
+		preferences := model.Preferences{
+			DefaultChannels: strings.Join(body.Preferences.DefaultChannels, ","),
+			Timezone:        body.Preferences.Timezone,
+			QuietHours:      strings.Join(body.Preferences.QuietHours, ","),
+			MaxPerHour:      body.Preferences.MaxPerHour,
+		}
+		if details := h.validatePreferences(&preferences); details != nil {
+			logger.Errorw("account.handler.updatePreferences invalid preferences", "preferences", preferences)
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid preferences request in body", details)
+		}
+
+		err := h.accountDB.UpdatePreferences(c.Request.Context(), currentUser.ID, &preferences)
+		if err != nil {
+			if database.IsRecordNotFoundErr(err) {
+				return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found account", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, NewPreferencesResponse(&preferences))
+	})
+}
+
+// validatePreferences normalizes given preferences and returns validation error details
+// if it has a channel without notifier, an unknown timezone or invalid quiet hours
+func (h *Handler) validatePreferences(preferences *model.Preferences) []*validate.ValidationErrDetail {
+	var channels []string
+	seen := make(map[string]bool)
+	for _, channel := range preferences.Channels() {
+		if _, err := h.notifiers.Notifier(channel); err != nil {
+			return validate.NewValidationErrorDetails("defaultChannels", err.Error(), preferences.DefaultChannels)
+		}
+		if !seen[channel] {
+			seen[channel] = true
+			channels = append(channels, channel)
+		}
+	}
+	preferences.DefaultChannels = strings.Join(channels, ",")
+
+	if preferences.Timezone != "" {
+		if _, err := time.LoadLocation(preferences.Timezone); err != nil {
+			return validate.NewValidationErrorDetails("timezone", "unknown timezone", preferences.Timezone)
+		}
+	}
+
+	windows, err := model.ParseQuietHours(preferences.QuietHours)
+	if err != nil {
+		return validate.NewValidationErrorDetails("quietHours", err.Error(), preferences.QuietHours)
+	}
+	var quietHours []string
+	for _, w := range windows {
+		quietHours = append(quietHours, w.String())
+	}
+	preferences.QuietHours = strings.Join(quietHours, ",")
+	return nil
+}
@@ -0,0 +1,110 @@
+package account
+
+import (
+	"bytes"
+	"encoding/json"
+	"kek-backend/internal/account/model"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/tidwall/gjson"
+)
+
+func (s *HandlerSuite) TestPreferences() {
+	// given
+	_, token := s.newLoggedInAccount()
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/user/preferences", nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	expected := `
+	{
+	  "preferences": {
+		"defaultChannels": [],
+		"timezone": "UTC",
+		"quietHours": [],
+		"maxPerHour": 0
+	  }
+	}`
+	s.JSONEq(expected, res.Body.String())
+}
+
+func (s *HandlerSuite) TestUpdatePreferences() {
+	// given
+	acc, token := s.newLoggedInAccount()
+	matcher := func(p *model.Preferences) bool {
+		return p.DefaultChannels == "push,email" && p.Timezone == "Asia/Seoul" &&
+			p.QuietHours == "22:00-07:00,12:00-13:30" && p.MaxPerHour == 5
+	}
+	s.db.On("UpdatePreferences", mock.Anything, acc.ID, mock.MatchedBy(matcher)).Return(nil)
+
+	// when
+	body := map[string]interface{}{
+		"preferences": map[string]interface{}{
+			"defaultChannels": []string{"Push", "email", "push"},
+			"timezone":        "Asia/Seoul",
+			"quietHours":      []string{"22:00-7:00", " 12:00-13:30"},
+			"maxPerHour":      5,
+		},
+	}
+	b, _ := json.Marshal(body)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/v1/api/user/preferences", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "UpdatePreferences", mock.Anything, acc.ID, mock.MatchedBy(matcher))
+	s.Equal(http.StatusOK, res.Code)
+	expected := `
+	{
+	  "preferences": {
+		"defaultChannels": ["push", "email"],
+		"timezone": "Asia/Seoul",
+		"quietHours": ["22:00-07:00", "12:00-13:30"],
+		"maxPerHour": 5
+	  }
+	}`
+	s.JSONEq(expected, res.Body.String())
+}
+
+func (s *HandlerSuite) TestUpdatePreferences_FailIfInvalid() {
+	cases := []struct {
+		Name        string
+		Preferences map[string]interface{}
+		Field       string
+	}{
+		{Name: "unknown channel", Preferences: map[string]interface{}{"defaultChannels": []string{"push", "sms"}}, Field: "defaultChannels"},
+		{Name: "unknown timezone", Preferences: map[string]interface{}{"timezone": "Mars/Olympus"}, Field: "timezone"},
+		{Name: "invalid quiet hours", Preferences: map[string]interface{}{"quietHours": []string{"22:00"}}, Field: "quietHours"},
+		{Name: "negative max per hour", Preferences: map[string]interface{}{"maxPerHour": -1}, Field: "maxPerHour"},
+	}
+
+	for _, tc := range cases {
+		s.Run(tc.Name, func() {
+			// given
+			_, token := s.newLoggedInAccount()
+
+			// when
+			b, _ := json.Marshal(map[string]interface{}{"preferences": tc.Preferences})
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", "/v1/api/user/preferences", bytes.NewBuffer(b))
+			req.Header.Add("Authorization", "Bearer "+token)
+
+			s.r.ServeHTTP(res, req)
+
+			// then
+			s.db.AssertNotCalled(s.T(), "UpdatePreferences", mock.Anything, mock.Anything, mock.Anything)
+			s.Equal(http.StatusBadRequest, res.Code)
+			s.Equal(tc.Field, gjson.Get(res.Body.String(), "errors.0.field").String())
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"kek-backend/internal/account/model"
 	"kek-backend/internal/config"
 	"kek-backend/internal/database"
+	"kek-backend/internal/notify"
 	"kek-backend/pkg/logging"
 	"net/http"
 	"net/http/httptest"
@@ -36,7 +37,10 @@ func (s *HandlerSuite) SetupTest() {
 	s.NoError(err)
 
 	s.db = &mocks.AccountDB{}
-	s.handler = NewHandler(s.db)
+	notifiers := notify.NewRegistry()
+	notifiers.Register(notify.ActionPush, &notify.Fake{})
+	notifiers.Register(notify.ActionEmail, &notify.Fake{})
+	s.handler = NewHandler(s.db, notifiers)
 
 	jwtMiddleware, err := NewAuthMiddleware(cfg, s.db)
 	s.NoError(err)
@@ -288,6 +292,7 @@ func (s *HandlerSuite) TestCurrentUser() {
 		Disabled:  false,
 	}
 	token := s.getBearerToken(&acc, password)
+	s.db.On("CountUnreadInboxItems", mock.Anything, acc.ID).Return(int64(3), nil)
 
 	// when
 	res := httptest.NewRecorder()
@@ -305,12 +310,30 @@ func (s *HandlerSuite) TestCurrentUser() {
 		"username": "user1",
 		"email": "user1@gmail.com",
 		"bio": "user1 bio",
-		"image": "user1 image"
+		"image": "user1 image",
+		"unreadCount": 3
 	  }
 	}`
 	s.JSONEq(expected, res.Body.String())
 }
 
+func (s *HandlerSuite) TestCurrentUser_FailIfTokenInQuery() {
+	// given
+	password := "password1"
+	encodedPassword, _ := EncodePassword(password)
+	acc := model.Account{ID: 1, Username: "user1", Email: "user1@gmail.com", Password: encodedPassword}
+	token := s.getBearerToken(&acc, password)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/user/me?token="+token, nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusUnauthorized, res.Code)
+}
+
 func (s *HandlerSuite) TestUpdate() {
 	// given
 	password := "password1"
@@ -361,6 +384,141 @@ func (s *HandlerSuite) TestUpdate() {
 	s.JSONEq(expected, res.Body.String())
 }
 
+func (s *HandlerSuite) TestUpdate_Webhook() {
+	// given
+	password := "password1"
+	encodedPassword, _ := EncodePassword(password)
+	acc := model.Account{
+		ID:       1,
+		Username: "user1",
+		Email:    "user1@gmail.com",
+		Password: encodedPassword,
+	}
+	token := s.getBearerToken(&acc, password)
+	s.db.On("Update", mock.Anything, acc.Email, mock.Anything).Return(nil)
+
+	// when
+	updateRequest := map[string]interface{}{
+		"user": map[string]interface{}{
+			"webhookUrl":    "https://bot.example.com/alerts",
+			"webhookSecret": "0123456789abcdef",
+		},
+	}
+	b, _ := json.Marshal(updateRequest)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/v1/api/user", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "Update", mock.Anything, acc.Email, mock.MatchedBy(func(a *model.Account) bool {
+		return a.WebhookURL == "https://bot.example.com/alerts" && a.WebhookSecret == "0123456789abcdef"
+	}))
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal("https://bot.example.com/alerts", gjson.Get(res.Body.String(), "user.webhookUrl").String())
+	s.False(gjson.Get(res.Body.String(), "user.webhookSecret").Exists())
+}
+
+func (s *HandlerSuite) TestUpdate_ClearWebhook() {
+	// given
+	password := "password1"
+	encodedPassword, _ := EncodePassword(password)
+	acc := model.Account{
+		ID:            1,
+		Username:      "user1",
+		Email:         "user1@gmail.com",
+		Password:      encodedPassword,
+		WebhookURL:    "https://bot.example.com/alerts",
+		WebhookSecret: "0123456789abcdef",
+	}
+	token := s.getBearerToken(&acc, password)
+	s.db.On("Update", mock.Anything, acc.Email, mock.Anything).Return(nil)
+
+	// when
+	updateRequest := map[string]interface{}{
+		"user": map[string]interface{}{
+			"webhookUrl": "",
+		},
+	}
+	b, _ := json.Marshal(updateRequest)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/v1/api/user", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "Update", mock.Anything, acc.Email, mock.MatchedBy(func(a *model.Account) bool {
+		return a.WebhookURL == "" && a.WebhookSecret == ""
+	}))
+	s.Equal(http.StatusOK, res.Code)
+	s.False(gjson.Get(res.Body.String(), "user.webhookUrl").Exists())
+}
+
+func (s *HandlerSuite) TestUpdate_FailIfWebhookNotHTTPS() {
+	// given
+	password := "password1"
+	encodedPassword, _ := EncodePassword(password)
+	acc := model.Account{
+		ID:       1,
+		Username: "user1",
+		Email:    "user1@gmail.com",
+		Password: encodedPassword,
+	}
+	token := s.getBearerToken(&acc, password)
+
+	// when
+	updateRequest := map[string]interface{}{
+		"user": map[string]interface{}{
+			"webhookUrl":    "http://169.254.169.254/latest/meta-data",
+			"webhookSecret": "0123456789abcdef",
+		},
+	}
+	b, _ := json.Marshal(updateRequest)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/v1/api/user", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.Equal("webhookUrl", gjson.Get(res.Body.String(), "errors.0.field").String())
+}
+
+func (s *HandlerSuite) TestUpdate_FailIfWebhookWithoutSecret() {
+	// given
+	password := "password1"
+	encodedPassword, _ := EncodePassword(password)
+	acc := model.Account{
+		ID:       1,
+		Username: "user1",
+		Email:    "user1@gmail.com",
+		Password: encodedPassword,
+	}
+	token := s.getBearerToken(&acc, password)
+
+	// when
+	updateRequest := map[string]interface{}{
+		"user": map[string]interface{}{
+			"webhookUrl": "https://bot.example.com/alerts",
+		},
+	}
+	b, _ := json.Marshal(updateRequest)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/v1/api/user", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.Equal("webhookSecret", gjson.Get(res.Body.String(), "errors.0.field").String())
+}
+
 func (s *HandlerSuite) getBearerToken(acc *model.Account, rawPassword string) string {
 	s.db.On("FindByEmail", mock.Anything, acc.Email).Return(acc, nil)
 	body := map[string]interface{}{
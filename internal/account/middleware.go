@@ -1,9 +1,12 @@
 package account
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	accountDB "kek-backend/internal/account/database"
 	"kek-backend/internal/account/model"
 	"kek-backend/internal/config"
+	"kek-backend/internal/middleware/handler"
 	"kek-backend/pkg/logging"
 	"net/http"
 	"time"
@@ -39,12 +42,63 @@ func MustCurrentUser(c *gin.Context) *model.Account {
 	panic("no account in gin.Context")
 }
 
+// AdminMiddleware aborts a request with 403 unless the current user is an admin.
+// It must be used after the auth middleware
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		acc, ok := CurrentUser(c)
+		if !ok || !acc.IsAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, &handler.ErrorResponse{Code: handler.PermissionDenied, Message: "admin only"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// StreamAuthMiddleware authenticates stream requests with a short-lived ticket in query
+// for clients such as EventSource which cannot set headers. Tickets are signed with a key of their own
+// so that neither a ticket nor a session token is accepted in place of the other
+type StreamAuthMiddleware struct {
+	*jwt.GinJWTMiddleware
+}
+
+// NewAuthMiddleware creates a middleware authenticating requests with a session token in the Authorization header
 func NewAuthMiddleware(cfg *config.Config, accountDB accountDB.AccountDB) (*jwt.GinJWTMiddleware, error) {
-	return jwt.New(&jwt.GinJWTMiddleware{
-		Realm:       "test zone",
+	return newJWTMiddleware(accountDB, &jwt.GinJWTMiddleware{
 		Key:         []byte(cfg.JwtConfig.Secret),
 		Timeout:     time.Duration(cfg.JwtConfig.SessionTime) * time.Millisecond,
 		MaxRefresh:  time.Hour,
+		TokenLookup: "header: Authorization",
+	})
+}
+
+// NewStreamAuthMiddleware creates a middleware authenticating requests with a stream ticket in query.
+// A ticket is issued with TokenGenerator of the middleware
+func NewStreamAuthMiddleware(cfg *config.Config, accountDB accountDB.AccountDB) (*StreamAuthMiddleware, error) {
+	mac := hmac.New(sha256.New, []byte(cfg.JwtConfig.Secret))
+	mac.Write([]byte("stream-ticket"))
+	ticketTime := time.Duration(cfg.StreamConfig.TicketSecs) * time.Second
+	if ticketTime <= 0 {
+		ticketTime = time.Minute
+	}
+	mw, err := newJWTMiddleware(accountDB, &jwt.GinJWTMiddleware{
+		Key:         mac.Sum(nil),
+		Timeout:     ticketTime,
+		TokenLookup: "query: ticket",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &StreamAuthMiddleware{GinJWTMiddleware: mw}, nil
+}
+
+// newJWTMiddleware creates a jwt middleware of accounts with the key, timeout and token lookup of given middleware
+func newJWTMiddleware(accountDB accountDB.AccountDB, mw *jwt.GinJWTMiddleware) (*jwt.GinJWTMiddleware, error) {
+	return jwt.New(&jwt.GinJWTMiddleware{
+		Realm:       "test zone",
+		Key:         mw.Key,
+		Timeout:     mw.Timeout,
+		MaxRefresh:  mw.MaxRefresh,
 		IdentityKey: identityKey,
 		PayloadFunc: func(data interface{}) jwt.MapClaims {
 			if v, ok := data.(*model.Account); ok {
@@ -108,7 +162,7 @@ func NewAuthMiddleware(cfg *config.Config, accountDB accountDB.AccountDB) (*jwt.
 				"expire": expire,
 			})
 		},
-		TokenLookup:   "header: Authorization",
+		TokenLookup:   mw.TokenLookup,
 		TokenHeadName: "Bearer",
 		TimeFunc:      time.Now,
 	})
@@ -13,10 +13,31 @@ type Account struct {
 	Password  string    `gorm:"column:password"`
 	Bio       string    `gorm:"column:bio"`
 	Image     string    `gorm:"column:image"`
-	Token     string    `gorm:"column:token"`
 	CreatedAt time.Time `gorm:"column:created_at"`
 	UpdatedAt time.Time `gorm:"column:updated_at"`
 	Disabled  bool      `gorm:"column:disabled"`
+	// WebhookURL receives alert notifications signed with WebhookSecret
+	WebhookURL    string      `gorm:"column:webhook_url"`
+	WebhookSecret string      `gorm:"column:webhook_secret"`
+	IsAdmin       bool        `gorm:"column:is_admin"`
+	Preferences   Preferences `gorm:"embedded"`
+}
+
+// device platforms
+const (
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+	PlatformWeb     = "web"
+)
+
+// AccountDevice is a device of an account receiving push notifications with its FCM token
+type AccountDevice struct {
+	ID         uint      `gorm:"column:id"`
+	AccountID  uint      `gorm:"column:account_id"`
+	Platform   string    `gorm:"column:platform"`
+	Token      string    `gorm:"column:token"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+	LastSeenAt time.Time `gorm:"column:last_seen_at"`
 }
 
 func (a Account) String() string {
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// InboxItem is an alert event triggered for an account, kept once per alert event to be read in app
+// whether or not its notifications are delivered
+type InboxItem struct {
+	ID            uint       `gorm:"column:id"`
+	AccountID     uint       `gorm:"column:account_id"`
+	AlertEventID  uint       `gorm:"column:alert_event_id"`
+	AlertID       uint       `gorm:"column:alert_id"`
+	Slug          string     `gorm:"column:slug"`
+	Title         string     `gorm:"column:title"`
+	Body          string     `gorm:"column:body"`
+	Condition     string     `gorm:"column:condition"`
+	ObservedPrice float64    `gorm:"column:observed_price"`
+	TriggeredAt   time.Time  `gorm:"column:triggered_at"`
+	ReadAt        *time.Time `gorm:"column:read_at"`
+	CreatedAt     time.Time  `gorm:"column:created_at"`
+}
+
+// IsRead returns true if the item has been marked as read
+func (i *InboxItem) IsRead() bool {
+	return i.ReadAt != nil
+}
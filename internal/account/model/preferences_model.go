@@ -0,0 +1,122 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Preferences is notification preferences of an account
+type Preferences struct {
+	// DefaultChannels is alert actions separated by comma used for alerts created without actions
+	// and for notifications of subscribed alerts
+	DefaultChannels string `gorm:"column:default_channels"`
+	// Timezone is an IANA time zone name of QuietHours such as "Asia/Seoul". UTC is used if empty
+	Timezone string `gorm:"column:timezone"`
+	// QuietHours is windows in the timezone separated by comma such as "22:00-07:00,12:00-13:00"
+	QuietHours string `gorm:"column:quiet_hours"`
+	// MaxPerHour is the maximum number of notifications sent in an hour. 0 is unlimited
+	MaxPerHour int `gorm:"column:max_per_hour"`
+}
+
+// QuietWindow is a daily window of quiet hours in minutes of a day.
+// The window spans midnight if End is less than Start
+type QuietWindow struct {
+	Start int
+	End   int
+}
+
+// String returns the window such as "22:00-07:00"
+func (w QuietWindow) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", w.Start/60, w.Start%60, w.End/60, w.End%60)
+}
+
+// contains returns true if given minute of a day is in the window
+func (w QuietWindow) contains(minute int) bool {
+	if w.Start < w.End {
+		return w.Start <= minute && minute < w.End
+	}
+	return minute >= w.Start || minute < w.End
+}
+
+// ParseQuietHours parses windows such as "22:00-07:00" separated by comma
+func ParseQuietHours(s string) ([]QuietWindow, error) {
+	var ret []QuietWindow
+	for _, value := range strings.Split(s, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		parts := strings.Split(value, "-")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid quiet hours %q, must be HH:MM-HH:MM", value)
+		}
+		start, err := parseMinute(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid quiet hours %q, must be HH:MM-HH:MM", value)
+		}
+		end, err := parseMinute(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid quiet hours %q, must be HH:MM-HH:MM", value)
+		}
+		if start == end {
+			return nil, fmt.Errorf("invalid quiet hours %q, start and end must be different", value)
+		}
+		ret = append(ret, QuietWindow{Start: start, End: end})
+	}
+	return ret, nil
+}
+
+// parseMinute parses given "HH:MM" to minutes of a day
+func parseMinute(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Location returns the location of the timezone, or UTC if empty or unknown
+func (p *Preferences) Location() *time.Location {
+	if p.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Channels returns default channels listed in DefaultChannels separated by comma
+func (p *Preferences) Channels() []string {
+	var ret []string
+	for _, channel := range strings.Split(p.DefaultChannels, ",") {
+		if channel = strings.ToLower(strings.TrimSpace(channel)); channel != "" {
+			ret = append(ret, channel)
+		}
+	}
+	return ret
+}
+
+// QuietUntil returns the end of the quiet window containing given time and true
+// if the time is in quiet hours, otherwise false
+func (p *Preferences) QuietUntil(now time.Time) (time.Time, bool) {
+	windows, err := ParseQuietHours(p.QuietHours)
+	if err != nil || len(windows) == 0 {
+		return time.Time{}, false
+	}
+	local := now.In(p.Location())
+	minute := local.Hour()*60 + local.Minute()
+	for _, w := range windows {
+		if !w.contains(minute) {
+			continue
+		}
+		until := time.Date(local.Year(), local.Month(), local.Day(), w.End/60, w.End%60, 0, 0, local.Location())
+		if !until.After(local) {
+			until = until.AddDate(0, 0, 1)
+		}
+		return until, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuietHours(t *testing.T) {
+	cases := []struct {
+		Value    string
+		Expected []QuietWindow
+		Message  string
+	}{
+		{Value: ""},
+		{Value: "22:00-07:00", Expected: []QuietWindow{{Start: 22 * 60, End: 7 * 60}}},
+		{Value: " 12:00-13:30 , 1:00-2:00", Expected: []QuietWindow{{Start: 12 * 60, End: 13*60 + 30}, {Start: 60, End: 120}}},
+		{Value: "22:00", Message: `invalid quiet hours "22:00", must be HH:MM-HH:MM`},
+		{Value: "25:00-07:00", Message: `invalid quiet hours "25:00-07:00", must be HH:MM-HH:MM`},
+		{Value: "07:00-07:00", Message: `invalid quiet hours "07:00-07:00", start and end must be different`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Value, func(t *testing.T) {
+			windows, err := ParseQuietHours(tc.Value)
+
+			if tc.Message != "" {
+				assert.EqualError(t, err, tc.Message)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expected, windows)
+		})
+	}
+}
+
+func TestQuietWindow_String(t *testing.T) {
+	assert.Equal(t, "22:00-07:05", QuietWindow{Start: 22 * 60, End: 7*60 + 5}.String())
+}
+
+func TestPreferences_QuietUntil(t *testing.T) {
+	seoul, err := time.LoadLocation("Asia/Seoul")
+	assert.NoError(t, err)
+
+	cases := []struct {
+		Name       string
+		Preference Preferences
+		Now        time.Time
+		Until      time.Time
+		Quiet      bool
+	}{
+		{Name: "no quiet hours", Now: time.Date(2021, 11, 1, 23, 0, 0, 0, time.UTC)},
+		{Name: "before midnight", Preference: Preferences{QuietHours: "22:00-07:00"},
+			Now: time.Date(2021, 11, 1, 23, 0, 0, 0, time.UTC), Until: time.Date(2021, 11, 2, 7, 0, 0, 0, time.UTC), Quiet: true},
+		{Name: "after midnight", Preference: Preferences{QuietHours: "22:00-07:00"},
+			Now: time.Date(2021, 11, 2, 6, 59, 0, 0, time.UTC), Until: time.Date(2021, 11, 2, 7, 0, 0, 0, time.UTC), Quiet: true},
+		{Name: "end of window", Preference: Preferences{QuietHours: "22:00-07:00"},
+			Now: time.Date(2021, 11, 2, 7, 0, 0, 0, time.UTC)},
+		{Name: "second window", Preference: Preferences{QuietHours: "22:00-07:00,12:00-13:00"},
+			Now: time.Date(2021, 11, 2, 12, 30, 0, 0, time.UTC), Until: time.Date(2021, 11, 2, 13, 0, 0, 0, time.UTC), Quiet: true},
+		{Name: "in timezone", Preference: Preferences{Timezone: "Asia/Seoul", QuietHours: "22:00-07:00"},
+			Now: time.Date(2021, 11, 1, 14, 0, 0, 0, time.UTC), Until: time.Date(2021, 11, 2, 7, 0, 0, 0, seoul), Quiet: true},
+		{Name: "out of timezone", Preference: Preferences{Timezone: "Asia/Seoul", QuietHours: "22:00-07:00"},
+			Now: time.Date(2021, 11, 1, 23, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			until, quiet := tc.Preference.QuietUntil(tc.Now)
+
+			assert.Equal(t, tc.Quiet, quiet)
+			assert.True(t, tc.Until.Equal(until), "expected %v, actual %v", tc.Until, until)
+		})
+	}
+}
+
+func TestPreferences_Channels(t *testing.T) {
+	p := Preferences{DefaultChannels: " Push, ,email"}
+
+	assert.Equal(t, []string{"push", "email"}, p.Channels())
+	assert.Nil(t, (&Preferences{}).Channels())
+}
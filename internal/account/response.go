@@ -1,6 +1,10 @@
 package account
 
-import "kek-backend/internal/account/model"
+import (
+	"kek-backend/internal/account/model"
+	"strings"
+	"time"
+)
 
 type UserResponse struct {
 	User User `json:"user"`
@@ -11,15 +15,145 @@ type User struct {
 	Email    string `json:"email"`
 	Bio      string `json:"bio"`
 	Image    string `json:"image"`
+	// WebhookURL is shown without the secret
+	WebhookURL string `json:"webhookUrl,omitempty"`
+	// UnreadCount is the number of unread inbox items, only shown to the user itself
+	UnreadCount *int64 `json:"unreadCount,omitempty"`
 }
 
 func NewUserResponse(acc *model.Account) *UserResponse {
 	return &UserResponse{
 		User: User{
-			Username: acc.Username,
-			Email:    acc.Email,
-			Bio:      acc.Bio,
-			Image:    acc.Image,
+			Username:   acc.Username,
+			Email:      acc.Email,
+			Bio:        acc.Bio,
+			Image:      acc.Image,
+			WebhookURL: acc.WebhookURL,
 		},
 	}
 }
+
+type DeviceResponse struct {
+	Device Device `json:"device"`
+}
+
+type DevicesResponse struct {
+	Devices      []Device `json:"devices"`
+	DevicesCount int      `json:"devicesCount"`
+}
+
+type Device struct {
+	ID         uint      `json:"id"`
+	Platform   string    `json:"platform"`
+	Token      string    `json:"token"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+}
+
+func NewDeviceResponse(device *model.AccountDevice) *DeviceResponse {
+	return &DeviceResponse{
+		Device: newDevice(device),
+	}
+}
+
+func NewDevicesResponse(devices []*model.AccountDevice) *DevicesResponse {
+	ret := make([]Device, 0, len(devices))
+	for _, device := range devices {
+		ret = append(ret, newDevice(device))
+	}
+	return &DevicesResponse{
+		Devices:      ret,
+		DevicesCount: len(ret),
+	}
+}
+
+func newDevice(device *model.AccountDevice) Device {
+	return Device{
+		ID:         device.ID,
+		Platform:   device.Platform,
+		Token:      device.Token,
+		CreatedAt:  device.CreatedAt,
+		LastSeenAt: device.LastSeenAt,
+	}
+}
+
+type PreferencesResponse struct {
+	Preferences Preferences `json:"preferences"`
+}
+
+type Preferences struct {
+	DefaultChannels []string `json:"defaultChannels"`
+	Timezone        string   `json:"timezone"`
+	QuietHours      []string `json:"quietHours"`
+	MaxPerHour      int      `json:"maxPerHour"`
+}
+
+func NewPreferencesResponse(preferences *model.Preferences) *PreferencesResponse {
+	quietHours := make([]string, 0)
+	for _, w := range strings.Split(preferences.QuietHours, ",") {
+		if w != "" {
+			quietHours = append(quietHours, w)
+		}
+	}
+	channels := preferences.Channels()
+	if channels == nil {
+		channels = make([]string, 0)
+	}
+	timezone := preferences.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	return &PreferencesResponse{
+		Preferences: Preferences{
+			DefaultChannels: channels,
+			Timezone:        timezone,
+			QuietHours:      quietHours,
+			MaxPerHour:      preferences.MaxPerHour,
+		},
+	}
+}
+
+type InboxResponse struct {
+	Notifications []InboxItem `json:"notifications"`
+	// NextCursor is the cursor of the next page if it may exist
+	NextCursor uint `json:"nextCursor,omitempty"`
+}
+
+type InboxItem struct {
+	ID            uint       `json:"id"`
+	Slug          string     `json:"slug"`
+	Title         string     `json:"title"`
+	Body          string     `json:"body"`
+	Condition     string     `json:"condition"`
+	ObservedPrice float64    `json:"observedPrice"`
+	TriggeredAt   time.Time  `json:"triggeredAt"`
+	Read          bool       `json:"read"`
+	ReadAt        *time.Time `json:"readAt"`
+}
+
+type UnreadCountResponse struct {
+	UnreadCount int64 `json:"unreadCount"`
+}
+
+// NewInboxResponse converts inbox item models of a page with given limit to InboxResponse
+func NewInboxResponse(items []*model.InboxItem, limit uint) *InboxResponse {
+	ret := make([]InboxItem, 0, len(items))
+	for _, item := range items {
+		ret = append(ret, InboxItem{
+			ID:            item.ID,
+			Slug:          item.Slug,
+			Title:         item.Title,
+			Body:          item.Body,
+			Condition:     item.Condition,
+			ObservedPrice: item.ObservedPrice,
+			TriggeredAt:   item.TriggeredAt,
+			Read:          item.IsRead(),
+			ReadAt:        item.ReadAt,
+		})
+	}
+	res := InboxResponse{Notifications: ret}
+	if len(items) > 0 && uint(len(items)) == limit {
+		res.NextCursor = items[len(items)-1].ID
+	}
+	return &res
+}
@@ -0,0 +1,377 @@
+package alert
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/uniswap"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TypePrice compares the USD price of a token with a threshold
+	TypePrice = "price"
+	// TypePercentChange compares the USD price change of a token over a window with a percentage.
+	// The alert option is the window and the alert value is the percentage such as "5", "+5" or "-5"
+	// which matches a move of either direction, a rise and a drop respectively
+	TypePercentChange = "percent_change"
+	// TypeLiquidity compares the total liquidity of a token in token units with a threshold
+	TypeLiquidity = "liquidity"
+	// TypeLiquidityUSD compares the total liquidity of a token in USD with a threshold
+	TypeLiquidityUSD = "liquidity_usd"
+	// TypeExpression combines predicates on metrics of tokens with AND and OR.
+	// The expression is stored in the condition of the alert
+	TypeExpression = "expression"
+)
+
+const (
+	// OptionAbove matches if the observed value is greater than or equal to the threshold
+	OptionAbove = "above"
+	// OptionBelow matches if the observed value is less than or equal to the threshold
+	OptionBelow = "below"
+	// OptionEqual matches if the observed value is within a tolerance of the threshold.
+	// The tolerance can be given after the threshold such as "1500:5",
+	// otherwise defaultTolerance of the threshold is used.
+	OptionEqual = "equal"
+
+	defaultTolerance = 0.005
+)
+
+var (
+	ErrNoQuote    = errors.New("no quote")
+	ErrNoBaseline = errors.New("no baseline price")
+)
+
+// windows is supported windows of TypePercentChange
+var windows = map[string]time.Duration{
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+	"24h": 24 * time.Hour,
+}
+
+// minRetention is the minimum retention of price snapshots covering baselines of all windows
+const minRetention = 2 * 24 * time.Hour
+
+// Quote is a price observation of a token
+type Quote struct {
+	Address        string
+	Name           string
+	Symbol         string
+	DerivedETH     float64
+	EthPrice       float64
+	TotalLiquidity float64
+}
+
+// USDPrice returns the token price in USD
+func (q *Quote) USDPrice() float64 {
+	return q.DerivedETH * q.EthPrice
+}
+
+// LiquidityUSD returns the total liquidity of the token in USD
+func (q *Quote) LiquidityUSD() float64 {
+	return q.TotalLiquidity * q.USDPrice()
+}
+
+// Quotes is a set of quotes keyed by lower cased token address
+type Quotes map[string]*Quote
+
+// Quote returns a quote of given token address
+// ErrNoQuote is returned if not exist
+func (qs Quotes) Quote(address string) (*Quote, error) {
+	q, ok := qs[strings.ToLower(address)]
+	if !ok {
+		return nil, ErrNoQuote
+	}
+	return q, nil
+}
+
+// NewQuote converts a uniswap token with given ETH/USD price to Quote
+func NewQuote(ethPrice float64, token uniswap.Token) (*Quote, error) {
+	derivedETH, err := strconv.ParseFloat(token.DerivedETH, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse derivedETH")
+	}
+	liquidity, err := strconv.ParseFloat(token.TotalLiquidity, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse totalLiquidity")
+	}
+	return &Quote{
+		Address:        strings.ToLower(token.Id),
+		Name:           token.Name,
+		Symbol:         token.Symbol,
+		DerivedETH:     derivedETH,
+		EthPrice:       ethPrice,
+		TotalLiquidity: liquidity,
+	}, nil
+}
+
+// NewQuotes converts uniswap bundles and tokens to Quotes
+func NewQuotes(bundles *uniswap.Bundles, tokens *uniswap.Tokens) (Quotes, error) {
+	ethPrice, err := ParseEthPrice(bundles)
+	if err != nil {
+		return nil, err
+	}
+	quotes := make(Quotes, len(tokens.Tokens))
+	for _, token := range tokens.Tokens {
+		q, err := NewQuote(ethPrice, token)
+		if err != nil {
+			return nil, errors.Wrapf(err, "token %s", token.Id)
+		}
+		quotes[q.Address] = q
+	}
+	return quotes, nil
+}
+
+// ParseEthPrice returns the ETH/USD price of given bundles
+func ParseEthPrice(bundles *uniswap.Bundles) (float64, error) {
+	if len(bundles.Bundles) == 0 {
+		return 0, errors.New("empty bundles")
+	}
+	ethPrice, err := strconv.ParseFloat(bundles.Bundles[0].EthPrice, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "parse ethPrice")
+	}
+	return ethPrice, nil
+}
+
+// ConditionError is returned if an alert has an invalid condition field
+type ConditionError struct {
+	Field   string
+	Value   interface{}
+	Message string
+}
+
+func (e *ConditionError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Message)
+}
+
+// Market is market data which conditions are evaluated against
+type Market interface {
+	// Quote returns the current quote of given token address
+	// ErrNoQuote is returned if not exist
+	Quote(address string) (*Quote, error)
+
+	// Baseline returns the USD price of given token address observed the given window ago
+	// ErrNoBaseline is returned if not exist
+	Baseline(address string, window time.Duration) (float64, error)
+}
+
+// Condition is a rule of an alert evaluated against a market
+type Condition interface {
+	// Evaluate returns true if the condition matches given market
+	Evaluate(m Market) (bool, error)
+
+	// Rearmed returns true if the observed value of given market has moved back
+	// past the condition by the re-arm margin so that a triggered alert can fire again
+	Rearmed(m Market) (bool, error)
+
+	// Addresses returns lower cased addresses of tokens the condition observes
+	Addresses() []string
+}
+
+// ParseCondition returns a Condition built from alert type, value and option of given alert.
+// *ConditionError is returned if the alert has an invalid condition
+func ParseCondition(a *model.Alert) (Condition, error) {
+	if math.IsNaN(a.RearmMargin) || math.IsInf(a.RearmMargin, 0) || a.RearmMargin < 0 {
+		return nil, &ConditionError{Field: "rearmMargin", Value: a.RearmMargin, Message: "rearmMargin must be a non negative number"}
+	}
+	switch a.AlertType {
+	case TypePrice:
+		return newThresholdCondition(a, func(q *Quote) float64 {
+			return q.USDPrice()
+		})
+	case TypePercentChange:
+		return newPercentChangeCondition(a)
+	case TypeLiquidity:
+		return newThresholdCondition(a, func(q *Quote) float64 {
+			return q.TotalLiquidity
+		})
+	case TypeLiquidityUSD:
+		return newThresholdCondition(a, func(q *Quote) float64 {
+			return q.LiquidityUSD()
+		})
+	case TypeExpression:
+		return newExpressionCondition(a.ConditionExpr, a.RearmMargin)
+	default:
+		return nil, &ConditionError{Field: "alertType", Value: a.AlertType, Message: "unsupported alert type"}
+	}
+}
+
+// thresholdCondition compares a value of a token quote with a threshold
+type thresholdCondition struct {
+	address   string
+	option    string
+	threshold float64
+	tolerance float64
+	margin    float64
+	value     func(q *Quote) float64
+}
+
+func newThresholdCondition(a *model.Alert, value func(q *Quote) float64) (*thresholdCondition, error) {
+	c := thresholdCondition{
+		address: strings.ToLower(a.PairAddress),
+		option:  a.AlertOption,
+		margin:  a.RearmMargin,
+		value:   value,
+	}
+	switch a.AlertOption {
+	case OptionAbove, OptionBelow, OptionEqual:
+	default:
+		return nil, &ConditionError{Field: "alertOption", Value: a.AlertOption, Message: "alertOption must be one of above, below, equal"}
+	}
+
+	thresholdValue, toleranceValue := a.AlertValue, ""
+	if a.AlertOption == OptionEqual {
+		if i := strings.Index(a.AlertValue, ":"); i >= 0 {
+			thresholdValue, toleranceValue = a.AlertValue[:i], a.AlertValue[i+1:]
+		}
+	}
+	threshold, err := strconv.ParseFloat(strings.TrimSpace(thresholdValue), 64)
+	if err != nil || math.IsNaN(threshold) || math.IsInf(threshold, 0) || threshold < 0 {
+		return nil, &ConditionError{Field: "alertValue", Value: a.AlertValue, Message: "alertValue must be a non negative number"}
+	}
+	c.threshold = threshold
+
+	if a.AlertOption == OptionEqual {
+		c.tolerance = threshold * defaultTolerance
+		if toleranceValue != "" {
+			tolerance, err := strconv.ParseFloat(strings.TrimSpace(toleranceValue), 64)
+			if err != nil || math.IsNaN(tolerance) || math.IsInf(tolerance, 0) || tolerance < 0 {
+				return nil, &ConditionError{Field: "alertValue", Value: a.AlertValue, Message: "tolerance must be a non negative number"}
+			}
+			c.tolerance = tolerance
+		}
+	}
+	return &c, nil
+}
+
+func (c *thresholdCondition) Evaluate(m Market) (bool, error) {
+	q, err := m.Quote(c.address)
+	if err != nil {
+		return false, err
+	}
+	v := c.value(q)
+	switch c.option {
+	case OptionAbove:
+		return v >= c.threshold, nil
+	case OptionBelow:
+		return v <= c.threshold, nil
+	default:
+		return math.Abs(v-c.threshold) <= c.tolerance, nil
+	}
+}
+
+func (c *thresholdCondition) Addresses() []string {
+	return []string{c.address}
+}
+
+func (c *thresholdCondition) Rearmed(m Market) (bool, error) {
+	q, err := m.Quote(c.address)
+	if err != nil {
+		return false, err
+	}
+	v := c.value(q)
+	switch c.option {
+	case OptionAbove:
+		return v < c.threshold-c.margin, nil
+	case OptionBelow:
+		return v > c.threshold+c.margin, nil
+	default:
+		return math.Abs(v-c.threshold) > c.tolerance+c.margin, nil
+	}
+}
+
+// percentChangeCondition compares the USD price change of a token over a window with a percentage
+type percentChangeCondition struct {
+	address string
+	window  time.Duration
+	percent float64
+	margin  float64
+	// direction is 1 for a rise, -1 for a drop and 0 for either
+	direction int
+}
+
+func newPercentChangeCondition(a *model.Alert) (*percentChangeCondition, error) {
+	window, ok := windows[a.AlertOption]
+	if !ok {
+		return nil, &ConditionError{Field: "alertOption", Value: a.AlertOption, Message: "alertOption must be one of 5m, 15m, 1h, 4h, 24h"}
+	}
+	c := percentChangeCondition{
+		address: strings.ToLower(a.PairAddress),
+		window:  window,
+		margin:  a.RearmMargin,
+	}
+	value := strings.TrimSpace(a.AlertValue)
+	switch {
+	case strings.HasPrefix(value, "+"):
+		c.direction, value = 1, value[1:]
+	case strings.HasPrefix(value, "-"):
+		c.direction, value = -1, value[1:]
+	}
+	percent, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(percent) || math.IsInf(percent, 0) || percent <= 0 {
+		return nil, &ConditionError{Field: "alertValue", Value: a.AlertValue, Message: "alertValue must be a positive percentage"}
+	}
+	c.percent = percent
+	if c.margin >= percent {
+		return nil, &ConditionError{Field: "rearmMargin", Value: a.RearmMargin, Message: "rearmMargin must be less than the percentage"}
+	}
+	return &c, nil
+}
+
+func (c *percentChangeCondition) Evaluate(m Market) (bool, error) {
+	change, err := c.change(m)
+	if err != nil {
+		return false, err
+	}
+	switch c.direction {
+	case 1:
+		return change >= c.percent, nil
+	case -1:
+		return change <= -c.percent, nil
+	default:
+		return math.Abs(change) >= c.percent, nil
+	}
+}
+
+func (c *percentChangeCondition) Rearmed(m Market) (bool, error) {
+	change, err := c.change(m)
+	if err != nil {
+		return false, err
+	}
+	switch c.direction {
+	case 1:
+		return change < c.percent-c.margin, nil
+	case -1:
+		return change > -(c.percent - c.margin), nil
+	default:
+		return math.Abs(change) < c.percent-c.margin, nil
+	}
+}
+
+func (c *percentChangeCondition) Addresses() []string {
+	return []string{c.address}
+}
+
+// change returns the USD price change of the token over the window in percent
+func (c *percentChangeCondition) change(m Market) (float64, error) {
+	q, err := m.Quote(c.address)
+	if err != nil {
+		return 0, err
+	}
+	baseline, err := m.Baseline(c.address, c.window)
+	if err != nil {
+		return 0, err
+	}
+	if baseline <= 0 {
+		return 0, ErrNoBaseline
+	}
+	return (q.USDPrice() - baseline) / baseline * 100, nil
+}
@@ -0,0 +1,359 @@
+package alert
+
+import (
+	"encoding/json"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/uniswap"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	wethAddress = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	usdcAddress = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+
+	bundlesPayload = `{"data":{"bundles":[{"ethPrice":"2000"}]}}`
+	tokensPayload  = `
+	{
+	  "data": {
+	    "tokens": [
+	      {
+	        "id": "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2",
+	        "name": "Wrapped Ether",
+	        "symbol": "WETH",
+	        "derivedETH": "1",
+	        "totalLiquidity": "150000"
+	      },
+	      {
+	        "id": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48",
+	        "name": "USD Coin",
+	        "symbol": "USDC",
+	        "derivedETH": "0.0005",
+	        "totalLiquidity": "300000000"
+	      }
+	    ]
+	  }
+	}`
+)
+
+func TestNewQuotes(t *testing.T) {
+	quotes := cannedQuotes(t)
+
+	weth, err := quotes.Quote(wethAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, "WETH", weth.Symbol)
+	assert.Equal(t, 2000.0, weth.USDPrice())
+	assert.Equal(t, 150000.0, weth.TotalLiquidity)
+	assert.Equal(t, 300000000.0, weth.LiquidityUSD())
+
+	usdc, err := quotes.Quote("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, usdc.USDPrice())
+
+	_, err = quotes.Quote("0x0000000000000000000000000000000000000000")
+	assert.Equal(t, ErrNoQuote, err)
+}
+
+func TestNewQuotes_FailIfInvalidPayload(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Bundles string
+		Tokens  string
+	}{
+		{
+			Name:    "empty bundles",
+			Bundles: `{"data":{"bundles":[]}}`,
+			Tokens:  tokensPayload,
+		}, {
+			Name:    "invalid ethPrice",
+			Bundles: `{"data":{"bundles":[{"ethPrice":"abc"}]}}`,
+			Tokens:  tokensPayload,
+		}, {
+			Name:    "invalid derivedETH",
+			Bundles: bundlesPayload,
+			Tokens:  `{"data":{"tokens":[{"id":"0x1","derivedETH":"abc","totalLiquidity":"1"}]}}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var (
+				bundles uniswap.Bundles
+				tokens  uniswap.Tokens
+			)
+			unmarshalData(t, tc.Bundles, &bundles)
+			unmarshalData(t, tc.Tokens, &tokens)
+
+			_, err := NewQuotes(&bundles, &tokens)
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCondition_Price(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Address  string
+		Option   string
+		Value    string
+		Expected bool
+	}{
+		// above
+		{Name: "above matches higher price", Address: wethAddress, Option: OptionAbove, Value: "1500", Expected: true},
+		{Name: "above matches same price", Address: wethAddress, Option: OptionAbove, Value: "2000", Expected: true},
+		{Name: "above does not match lower price", Address: wethAddress, Option: OptionAbove, Value: "2500", Expected: false},
+		// below
+		{Name: "below matches lower price", Address: wethAddress, Option: OptionBelow, Value: "2500", Expected: true},
+		{Name: "below matches same price", Address: wethAddress, Option: OptionBelow, Value: "2000", Expected: true},
+		{Name: "below does not match higher price", Address: wethAddress, Option: OptionBelow, Value: "1500", Expected: false},
+		// equal
+		{Name: "equal matches within default tolerance", Address: wethAddress, Option: OptionEqual, Value: "2005", Expected: true},
+		{Name: "equal does not match out of default tolerance", Address: wethAddress, Option: OptionEqual, Value: "2100", Expected: false},
+		{Name: "equal matches within explicit tolerance", Address: wethAddress, Option: OptionEqual, Value: "2100:100", Expected: true},
+		{Name: "equal does not match out of explicit tolerance", Address: wethAddress, Option: OptionEqual, Value: "2100:99", Expected: false},
+		{Name: "equal matches stable coin", Address: usdcAddress, Option: OptionEqual, Value: "1:0.01", Expected: true},
+	}
+
+	quotes := cannedQuotes(t)
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			cond, err := ParseCondition(newConditionAlert(tc.Address, TypePrice, tc.Option, tc.Value))
+			assert.NoError(t, err)
+
+			matched, err := cond.Evaluate(&testMarket{Quotes: quotes})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expected, matched)
+		})
+	}
+}
+
+func TestCondition_Liquidity(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Type     string
+		Option   string
+		Value    string
+		Expected bool
+	}{
+		// weth has 150000 WETH and 300000000 USD of liquidity
+		{Name: "token units below matches pulled liquidity", Type: TypeLiquidity, Option: OptionBelow, Value: "200000", Expected: true},
+		{Name: "token units below does not match", Type: TypeLiquidity, Option: OptionBelow, Value: "100000", Expected: false},
+		{Name: "token units above matches", Type: TypeLiquidity, Option: OptionAbove, Value: "100000", Expected: true},
+		{Name: "token units above does not match", Type: TypeLiquidity, Option: OptionAbove, Value: "200000", Expected: false},
+		{Name: "usd below matches pulled liquidity", Type: TypeLiquidityUSD, Option: OptionBelow, Value: "500000000", Expected: true},
+		{Name: "usd below does not match", Type: TypeLiquidityUSD, Option: OptionBelow, Value: "1000000", Expected: false},
+		{Name: "usd above matches", Type: TypeLiquidityUSD, Option: OptionAbove, Value: "1000000", Expected: true},
+		{Name: "usd above does not match token units", Type: TypeLiquidityUSD, Option: OptionAbove, Value: "200000000000", Expected: false},
+	}
+
+	quotes := cannedQuotes(t)
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			cond, err := ParseCondition(newConditionAlert(wethAddress, tc.Type, tc.Option, tc.Value))
+			assert.NoError(t, err)
+
+			matched, err := cond.Evaluate(&testMarket{Quotes: quotes})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expected, matched)
+		})
+	}
+}
+
+func TestCondition_Rearmed(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Type     string
+		Option   string
+		Value    string
+		Margin   float64
+		Baseline float64
+		Expected bool
+	}{
+		// weth is 2000 now
+		{Name: "above rearms below threshold minus margin", Type: TypePrice, Option: OptionAbove, Value: "2100", Margin: 50, Expected: true},
+		{Name: "above does not rearm within margin", Type: TypePrice, Option: OptionAbove, Value: "2040", Margin: 50, Expected: false},
+		{Name: "above does not rearm at threshold", Type: TypePrice, Option: OptionAbove, Value: "2000", Expected: false},
+		{Name: "below rearms above threshold plus margin", Type: TypePrice, Option: OptionBelow, Value: "1900", Margin: 50, Expected: true},
+		{Name: "below does not rearm within margin", Type: TypePrice, Option: OptionBelow, Value: "1960", Margin: 50, Expected: false},
+		{Name: "equal rearms out of tolerance plus margin", Type: TypePrice, Option: OptionEqual, Value: "2100:50", Margin: 40, Expected: true},
+		{Name: "equal does not rearm within tolerance plus margin", Type: TypePrice, Option: OptionEqual, Value: "2100:50", Margin: 60, Expected: false},
+		{Name: "liquidity below rearms out of margin", Type: TypeLiquidity, Option: OptionBelow, Value: "100000", Margin: 10000, Expected: true},
+		{Name: "rise rearms under percent minus margin", Type: TypePercentChange, Option: "1h", Value: "+10", Margin: 2, Baseline: 1900, Expected: true},
+		{Name: "rise does not rearm within margin", Type: TypePercentChange, Option: "1h", Value: "+10", Margin: 6, Baseline: 1900, Expected: false},
+		{Name: "drop rearms over minus percent plus margin", Type: TypePercentChange, Option: "1h", Value: "-10", Margin: 2, Baseline: 2100, Expected: true},
+		{Name: "either does not rearm within margin", Type: TypePercentChange, Option: "1h", Value: "6", Margin: 2, Baseline: 1900, Expected: false},
+	}
+
+	quotes := cannedQuotes(t)
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			alert := newConditionAlert(wethAddress, tc.Type, tc.Option, tc.Value)
+			alert.RearmMargin = tc.Margin
+			cond, err := ParseCondition(alert)
+			assert.NoError(t, err)
+			m := testMarket{
+				Quotes:    quotes,
+				baselines: map[time.Duration]float64{time.Hour: tc.Baseline},
+			}
+
+			rearmed, err := cond.Rearmed(&m)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expected, rearmed)
+		})
+	}
+}
+
+func TestCondition_FailIfNoQuote(t *testing.T) {
+	cond, err := ParseCondition(newConditionAlert("0x0000000000000000000000000000000000000000", TypePrice, OptionAbove, "1"))
+	assert.NoError(t, err)
+
+	matched, err := cond.Evaluate(&testMarket{Quotes: cannedQuotes(t)})
+
+	assert.False(t, matched)
+	assert.Equal(t, ErrNoQuote, err)
+}
+
+func TestCondition_PercentChange(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Option   string
+		Value    string
+		Baseline float64
+		Expected bool
+	}{
+		// weth is 2000 now
+		{Name: "either matches rise", Option: "1h", Value: "5", Baseline: 1900, Expected: true},
+		{Name: "either matches drop", Option: "1h", Value: "5", Baseline: 2200, Expected: true},
+		{Name: "either does not match small move", Option: "1h", Value: "5", Baseline: 1980, Expected: false},
+		{Name: "rise matches rise", Option: "5m", Value: "+5", Baseline: 1900, Expected: true},
+		{Name: "rise does not match drop", Option: "5m", Value: "+5", Baseline: 2200, Expected: false},
+		{Name: "drop matches drop", Option: "24h", Value: "-5", Baseline: 2200, Expected: true},
+		{Name: "drop does not match rise", Option: "24h", Value: "-5", Baseline: 1900, Expected: false},
+		{Name: "drop matches exact percent", Option: "4h", Value: "-20", Baseline: 2500, Expected: true},
+	}
+
+	quotes := cannedQuotes(t)
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			cond, err := ParseCondition(newConditionAlert(wethAddress, TypePercentChange, tc.Option, tc.Value))
+			assert.NoError(t, err)
+			m := testMarket{
+				Quotes:    quotes,
+				baselines: map[time.Duration]float64{windows[tc.Option]: tc.Baseline},
+			}
+
+			matched, err := cond.Evaluate(&m)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expected, matched)
+		})
+	}
+}
+
+func TestCondition_FailIfNoBaseline(t *testing.T) {
+	cond, err := ParseCondition(newConditionAlert(wethAddress, TypePercentChange, "15m", "5"))
+	assert.NoError(t, err)
+
+	// no baseline of the window
+	matched, err := cond.Evaluate(&testMarket{Quotes: cannedQuotes(t)})
+	assert.False(t, matched)
+	assert.Equal(t, ErrNoBaseline, err)
+
+	// zero baseline
+	m := testMarket{
+		Quotes:    cannedQuotes(t),
+		baselines: map[time.Duration]float64{15 * time.Minute: 0},
+	}
+	matched, err = cond.Evaluate(&m)
+	assert.False(t, matched)
+	assert.Equal(t, ErrNoBaseline, err)
+}
+
+func TestParseCondition_FailIfInvalid(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Type   string
+		Option string
+		Value  string
+		Margin float64
+		Field  string
+	}{
+		{Name: "negative rearm margin", Type: TypePrice, Option: OptionAbove, Value: "1", Margin: -1, Field: "rearmMargin"},
+		{Name: "rearm margin over percent", Type: TypePercentChange, Option: "1h", Value: "5", Margin: 5, Field: "rearmMargin"},
+		{Name: "unknown type", Type: "volume", Option: OptionAbove, Value: "1", Field: "alertType"},
+		{Name: "unknown option", Type: TypePrice, Option: "cross", Value: "1", Field: "alertOption"},
+		{Name: "not a number", Type: TypePrice, Option: OptionAbove, Value: "abc", Field: "alertValue"},
+		{Name: "negative value", Type: TypePrice, Option: OptionBelow, Value: "-1", Field: "alertValue"},
+		{Name: "tolerance for non equal", Type: TypePrice, Option: OptionAbove, Value: "1:1", Field: "alertValue"},
+		{Name: "invalid tolerance", Type: TypePrice, Option: OptionEqual, Value: "1:abc", Field: "alertValue"},
+		{Name: "negative liquidity", Type: TypeLiquidity, Option: OptionBelow, Value: "-1", Field: "alertValue"},
+		{Name: "unknown liquidity option", Type: TypeLiquidityUSD, Option: "1h", Value: "1", Field: "alertOption"},
+		{Name: "unsupported window", Type: TypePercentChange, Option: "2h", Value: "5", Field: "alertOption"},
+		{Name: "zero percent", Type: TypePercentChange, Option: "1h", Value: "0", Field: "alertValue"},
+		{Name: "double sign percent", Type: TypePercentChange, Option: "1h", Value: "+-5", Field: "alertValue"},
+		{Name: "not a percent", Type: TypePercentChange, Option: "1h", Value: "5%", Field: "alertValue"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			alert := newConditionAlert(wethAddress, tc.Type, tc.Option, tc.Value)
+			alert.RearmMargin = tc.Margin
+			cond, err := ParseCondition(alert)
+
+			assert.Nil(t, cond)
+			cErr, ok := err.(*ConditionError)
+			assert.True(t, ok)
+			assert.Equal(t, tc.Field, cErr.Field)
+		})
+	}
+}
+
+func cannedQuotes(t *testing.T) Quotes {
+	var (
+		bundles uniswap.Bundles
+		tokens  uniswap.Tokens
+	)
+	unmarshalData(t, bundlesPayload, &bundles)
+	unmarshalData(t, tokensPayload, &tokens)
+	quotes, err := NewQuotes(&bundles, &tokens)
+	assert.NoError(t, err)
+	return quotes
+}
+
+// unmarshalData decodes the data of given GraphQL response payload into v
+func unmarshalData(t *testing.T, payload string, v interface{}) {
+	res := struct {
+		Data interface{} `json:"data"`
+	}{Data: v}
+	assert.NoError(t, json.Unmarshal([]byte(payload), &res))
+}
+
+func newConditionAlert(address, alertType, option, value string) *model.Alert {
+	return &model.Alert{
+		Slug:        "condition",
+		PairAddress: address,
+		AlertType:   alertType,
+		AlertOption: option,
+		AlertValue:  value,
+	}
+}
+
+// testMarket is a Market of given quotes and baselines of any token
+type testMarket struct {
+	Quotes
+	baselines map[time.Duration]float64
+}
+
+func (m *testMarket) Baseline(_ string, window time.Duration) (float64, error) {
+	baseline, ok := m.baselines[window]
+	if !ok {
+		return 0, ErrNoBaseline
+	}
+	return baseline, nil
+}
@@ -1,101 +1,61 @@
 package alert
 
 import (
-	"encoding/json"
-	"fmt"
-	"log"
-	"strconv"
-	"sync"
+	"context"
 
-	alertDB "kek-backend/internal/alert/database"
-	"kek-backend/internal/uniswap"
+	"kek-backend/internal/config"
+	"kek-backend/pkg/logging"
 
-	"github.com/appleboy/go-fcm"
+	"github.com/pkg/errors"
 	"github.com/robfig/cron/v3"
+	"go.uber.org/fx"
 )
 
-func sendMessage(title string, body string, token string) {
-	// Create the message to be sent.
-	msg := &fcm.Message{
-		To: token,
-		Data: map[string]interface{}{
-			"foo": "bar",
-		},
-		Notification: &fcm.Notification{
-			Title: title,
-			Body:  body,
-		},
-	}
-
-	// Create a FCM client to send the message.
-	client, err := fcm.NewClient("AAAAlSnRveU:APA91bF_XWeThMJnZuUGUyQ5wIBBBRyqGfryJ818ItRFUcJg0HubP6ekcNw0FF-ebQMHFZwva2wfEBIViv9qTh7QTeafiyHk8BWPgdE-j3DQEe2orVHpyayxF7DOyOujlarj2_SEhIr_")
-	if err != nil {
-		log.Fatalln(err)
-	}
-
-	// Send the message and receive the response without retries.
-	response, err := client.Send(msg)
+// StartCron runs the scanner, the price snapshot pruning and the notification dispatcher with the cron specs
+// of given config while the application is running. A tick is skipped if the previous one is still running
+func StartCron(lc fx.Lifecycle, cfg *config.Config, scanner *Scanner, dispatcher *Dispatcher) error {
+	c := cron.New(cron.WithSeconds(), cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	_, err := c.AddFunc(cfg.AlertConfig.Cron, func() {
+		if err := scanner.Scan(context.Background()); err != nil {
+			logging.DefaultLogger().Errorw("alert.cron failed to scan alerts", "err", err)
+		}
+	})
 	if err != nil {
-		log.Fatalln(err)
+		return errors.Wrap(err, "add alert cron")
 	}
-
-	log.Printf("%#v\n", response)
-}
-
-func StartCron(db alertDB.AlertDB) {
-	c := cron.New(cron.WithSeconds())
-	c.AddFunc("@every 5s", func() {
-		criteria := alertDB.IterateAlertCriteria{
-			Account: 1,
-			Offset:  0,
-			Limit:   1,
-		}
-		alerts, _, err := db.FindAlertsWithoutContext(criteria)
+	if cfg.PriceConfig.RetentionHours > 0 {
+		_, err = c.AddFunc(cfg.PriceConfig.PruneCron, func() {
+			if err := scanner.Prune(context.Background()); err != nil {
+				logging.DefaultLogger().Errorw("alert.cron failed to prune price snapshots", "err", err)
+			}
+		})
 		if err != nil {
-			return
+			return errors.Wrap(err, "add price prune cron")
 		}
+	}
+	_, err = c.AddFunc(cfg.NotifyConfig.Outbox.Cron, func() {
+		if err := dispatcher.Dispatch(context.Background()); err != nil {
+			logging.DefaultLogger().Errorw("alert.cron failed to dispatch notifications", "err", err)
+		}
+	})
+	if err != nil {
+		return errors.Wrap(err, "add notification cron")
+	}
 
-		var wg sync.WaitGroup
-		wg.Add(2)
-
-		ch := make(chan int)
-
-		var ethPrice float64
-		var tokenPrice float64
-
-		go func() {
-			c1 := make(chan string, 1)
-			query1 := uniswap.QueryBundles()
-			uniswap.Request(query1, c1)
-
-			msg1 := <-c1
-			var bundles uniswap.Bundles
-			json.Unmarshal([]byte(msg1), &bundles)
-			ethPrice, _ = strconv.ParseFloat(bundles.Data.Bundles[0].EthPrice, 64)
-			ch <- 1
-			wg.Done()
-		}()
-
-		go func() {
-			<-ch
-
-			for _, alert := range alerts {
-				c2 := make(chan string, 1)
-				query2 := uniswap.QueryToken(alert.PairAddress)
-				uniswap.Request(query2, c2)
-
-				msg2 := <-c2
-				var tokens uniswap.Tokens
-				json.Unmarshal([]byte(msg2), &tokens)
-				tokenPrice, _ = strconv.ParseFloat(tokens.Data.Tokens[0].DerivedETH, 64)
-				go sendMessage(alert.Title, alert.Body, alert.Account.Token)
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			logging.FromContext(ctx).Infof("Start alert cron %s and notification cron %s", cfg.AlertConfig.Cron, cfg.NotifyConfig.Outbox.Cron)
+			c.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			logging.FromContext(ctx).Infof("Stopped alert cron")
+			select {
+			case <-c.Stop().Done():
+			case <-ctx.Done():
 			}
-
-			wg.Done()
-		}()
-
-		wg.Wait()
-		fmt.Println("$$$$$:   ", ethPrice*tokenPrice)
+			return nil
+		},
 	})
-	c.Start()
+	return nil
 }
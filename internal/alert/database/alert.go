@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	accountModel "kek-backend/internal/account/model"
 	"kek-backend/internal/alert/model"
 	"kek-backend/internal/database"
 	"kek-backend/pkg/logging"
@@ -14,17 +15,51 @@ import (
 
 type IterateAlertCriteria struct {
 	Account uint
+	// Author filters alerts by the username of the account if not empty
+	Author string
+	// Visibility filters alerts by visibility if not empty
+	Visibility string
+	Offset     uint
+	Limit      uint
+}
+
+type IterateActiveAlertCriteria struct {
+	// AfterID is the last alert id of the previous batch
+	AfterID uint
+	Limit   uint
+	Now     time.Time
+}
+
+type IterateAlertEventCriteria struct {
+	AlertID uint
 	Offset  uint
 	Limit   uint
 }
 
+type IterateNotificationCriteria struct {
+	// Status filters notifications by status if not empty
+	Status string
+	Offset uint
+	Limit  uint
+}
+
 //go:generate mockery --name AlertDB --filename alert_mock.go
 type AlertDB interface {
 	RunInTx(ctx context.Context, f func(ctx context.Context) error) error
 
+	// RunLocked runs given function while holding an advisory lock of given id and returns true.
+	// It returns false without running the function if another session holds the lock
+	RunLocked(ctx context.Context, lockId int64, f func(ctx context.Context) error) (bool, error)
+
 	// SaveAlert saves a given alert.
+	// database.ErrKeyConflict error is returned if the slug is already taken
 	SaveAlert(ctx context.Context, alert *model.Alert) error
 
+	// UpdateAlert updates editable fields of an alert of given account with the id of given alert.
+	// The slug is kept so that links to the alert stay valid
+	// database.ErrNotFound error is returned if not exist
+	UpdateAlert(ctx context.Context, accountId uint, alert *model.Alert) error
+
 	// FindAlertBySlug returns a alert with given slug
 	// database.ErrNotFound error is returned if not exist
 	FindAlertBySlug(ctx context.Context, slug string) (*model.Alert, error)
@@ -32,12 +67,79 @@ type AlertDB interface {
 	// FindAlerts returns alert list with given criteria and total count
 	FindAlerts(ctx context.Context, criteria IterateAlertCriteria) ([]*model.Alert, int64, error)
 
-	// FindAlertsWithoutContext returns alert list with given criteria and total count
-	FindAlertsWithoutContext(criteria IterateAlertCriteria) ([]*model.Alert, int64, error)
+	// FindActiveAlerts returns not deleted and unexpired alerts of all accounts
+	// whose status is active or triggered in ascending id order with given criteria
+	FindActiveAlerts(ctx context.Context, criteria IterateActiveAlertCriteria) ([]*model.Alert, error)
 
-	// DeleteAlertBySlug deletes a alert with given slug
+	// DeleteAlertBySlug deletes a alert with given slug and marks it as cancelled
 	// and returns nil if success to delete, otherwise returns an error
 	DeleteAlertBySlug(ctx context.Context, accountId uint, slug string) error
+
+	// UpdateAlertStatus updates the status of an alert with given id from given status to a new status
+	// database.ErrNotFound error is returned if not exist or the status is not the given from status
+	UpdateAlertStatus(ctx context.Context, id uint, from, to string) error
+
+	// TriggerAlert marks an active alert with given id as triggered at given time
+	// database.ErrNotFound error is returned if not exist or the status is not active
+	TriggerAlert(ctx context.Context, id uint, at time.Time) error
+
+	// ExpireAlerts marks not finished alerts whose expiration time has passed at given time as expired
+	// and returns expired records count
+	ExpireAlerts(ctx context.Context, now time.Time) (int64, error)
+
+	// SaveAlertEvent saves a given alert event
+	SaveAlertEvent(ctx context.Context, event *model.AlertEvent) error
+
+	// UpdateAlertEventDelivery updates the delivery status and error of an alert event with given id
+	// database.ErrNotFound error is returned if not exist
+	UpdateAlertEventDelivery(ctx context.Context, id uint, status, deliveryErr string) error
+
+	// FindAlertEvents returns events of an alert in latest first order with given criteria and total count
+	FindAlertEvents(ctx context.Context, criteria IterateAlertEventCriteria) ([]*model.AlertEvent, int64, error)
+
+	// SaveSubscription subscribes an account with given id to an alert with given id
+	// database.ErrKeyConflict error is returned if already subscribed
+	SaveSubscription(ctx context.Context, alertId, accountId uint) error
+
+	// DeleteSubscription unsubscribes an account with given id from an alert with given id
+	// database.ErrNotFound error is returned if not subscribed
+	DeleteSubscription(ctx context.Context, alertId, accountId uint) error
+
+	// FindSubscribers returns accounts subscribed to an alert with given id
+	FindSubscribers(ctx context.Context, alertId uint) ([]*accountModel.Account, error)
+
+	// SaveNotifications saves given notifications to the outbox
+	SaveNotifications(ctx context.Context, notifications []*model.Notification) error
+
+	// ClaimDueNotifications claims pending notifications whose next attempt time has come at given time
+	// and returns them with account in ascending id order. A claimed notification is not due again for given lease,
+	// so that concurrent dispatchers do not claim the same notification
+	ClaimDueNotifications(ctx context.Context, now time.Time, lease time.Duration, limit uint) ([]*model.Notification, error)
+
+	// FindNotificationsByEvent returns notifications of an alert event with given id
+	FindNotificationsByEvent(ctx context.Context, eventId uint) ([]*model.Notification, error)
+
+	// FindNotification returns a notification with given id
+	// database.ErrNotFound error is returned if not exist
+	FindNotification(ctx context.Context, id uint) (*model.Notification, error)
+
+	// FindNotifications returns notifications of all accounts in latest first order with given criteria and total count
+	FindNotifications(ctx context.Context, criteria IterateNotificationCriteria) ([]*model.Notification, int64, error)
+
+	// UpdateNotification updates the status, attempts, next attempt time and last error of a pending notification
+	// if it has not been attempted since given attempts
+	// database.ErrNotFound error is returned if not exist or already updated
+	UpdateNotification(ctx context.Context, notification *model.Notification, fromAttempts int) error
+
+	// ReplayNotification moves a dead notification with given id back to pending to be attempted at given time
+	// database.ErrNotFound error is returned if not exist or the status is not dead
+	ReplayNotification(ctx context.Context, id uint, at time.Time) error
+
+	// SaveNotificationAttempt saves a given notification attempt
+	SaveNotificationAttempt(ctx context.Context, attempt *model.NotificationAttempt) error
+
+	// CountSentNotifications returns the number of notifications sent to an account with given id since given time
+	CountSentNotifications(ctx context.Context, accountId uint, since time.Time) (int64, error)
 }
 
 type alertDB struct {
@@ -63,6 +165,24 @@ func (a *alertDB) RunInTx(ctx context.Context, f func(ctx context.Context) error
 	return nil
 }
 
+func (a *alertDB) RunLocked(ctx context.Context, lockId int64, f func(ctx context.Context) error) (bool, error) {
+	// the transaction level lock is released with the transaction on the same connection
+	tx := a.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return false, errors.Wrap(tx.Error, "start tx")
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", lockId).Scan(&locked).Error; err != nil {
+		return false, errors.Wrap(err, "try advisory lock")
+	}
+	if !locked {
+		return false, nil
+	}
+	return true, f(ctx)
+}
+
 func (a *alertDB) SaveAlert(ctx context.Context, alert *model.Alert) error {
 	logger := logging.FromContext(ctx)
 	db := database.FromContext(ctx, a.db)
@@ -78,6 +198,40 @@ func (a *alertDB) SaveAlert(ctx context.Context, alert *model.Alert) error {
 	return nil
 }
 
+func (a *alertDB) UpdateAlert(ctx context.Context, accountId uint, alert *model.Alert) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.UpdateAlert", "accountId", accountId, "alert", alert)
+
+	alert.UpdatedAt = time.Now()
+	chain := db.WithContext(ctx).Model(&model.Alert{}).
+		Where("id = ? AND deleted_at_unix = 0", alert.ID).
+		Where("account_id = ?", accountId).
+		UpdateColumns(map[string]interface{}{
+			"title":           alert.Title,
+			"body":            alert.Body,
+			"alert_value":     alert.AlertValue,
+			"alert_option":    alert.AlertOption,
+			"condition_expr":  alert.ConditionExpr,
+			"expiration_time": alert.ExpirationTime,
+			"alert_actions":   alert.AlertActions,
+			"visibility":      alert.Visibility,
+			"cooldown_secs":   alert.CooldownSecs,
+			"rearm_margin":    alert.RearmMargin,
+			"critical":        alert.Critical,
+			"updated_at":      alert.UpdatedAt,
+		})
+	if chain.Error != nil {
+		logger.Errorw("failed to update an alert", "err", chain.Error)
+		return chain.Error
+	}
+	if chain.RowsAffected == 0 {
+		logger.Error("failed to update an alert because not found")
+		return database.ErrNotFound
+	}
+	return nil
+}
+
 func (a *alertDB) FindAlertBySlug(ctx context.Context, slug string) (*model.Alert, error) {
 	logger := logging.FromContext(ctx)
 	db := database.FromContext(ctx, a.db)
@@ -108,7 +262,16 @@ func (a *alertDB) FindAlerts(ctx context.Context, criteria IterateAlertCriteria)
 
 	chain := db.WithContext(ctx).Table("alerts a").Where("deleted_at_unix = 0")
 	if criteria.Account != 0 {
-		chain = chain.Where("au.id = ?", criteria.Account).Joins("LEFT JOIN accounts au on au.id = a.account_id")
+		chain = chain.Where("au.id = ?", criteria.Account)
+	}
+	if criteria.Author != "" {
+		chain = chain.Where("au.username = ?", criteria.Author)
+	}
+	if criteria.Account != 0 || criteria.Author != "" {
+		chain = chain.Joins("LEFT JOIN accounts au on au.id = a.account_id")
+	}
+	if criteria.Visibility != "" {
+		chain = chain.Where("a.visibility = ?", criteria.Visibility)
 	}
 
 	// get total count
@@ -156,53 +319,25 @@ func (a *alertDB) FindAlerts(ctx context.Context, criteria IterateAlertCriteria)
 	return ret, totalCount, nil
 }
 
-func (a *alertDB) FindAlertsWithoutContext(criteria IterateAlertCriteria) ([]*model.Alert, int64, error) {
-	db := a.db
-
-	chain := db.Table("alerts a").Where("deleted_at_unix = 0")
-	if criteria.Account != 0 {
-		chain = chain.Where("au.id = ?", criteria.Account).Joins("LEFT JOIN accounts au on au.id = a.account_id")
-	}
-
-	// get total count
-	var totalCount int64
-	err := chain.Distinct("a.id").Count(&totalCount).Error
-	if err != nil {
-	}
-
-	// get alert ids
-	rows, err := chain.Select("(a.id) id").
-		Offset(int(criteria.Offset)).
-		Limit(int(criteria.Limit)).
-		Order("a.id DESC").
-		Rows()
-	if err != nil {
-		return nil, 0, err
-	}
-	var ids []uint
-	for rows.Next() {
-		var id uint
-		err := rows.Scan(&id)
-		if err != nil {
-			return nil, 0, err
-		}
-		ids = append(ids, id)
-	}
+func (a *alertDB) FindActiveAlerts(ctx context.Context, criteria IterateActiveAlertCriteria) ([]*model.Alert, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.FindActiveAlerts", "criteria", criteria)
 
-	// get alerts with account by ids
 	var ret []*model.Alert
-	if len(ids) == 0 {
-		return []*model.Alert{}, totalCount, nil
-	}
-	err = db.Joins("Account").
-		Where("alerts.id IN (?)", ids).
-		Order("alerts.id DESC").
+	err := db.WithContext(ctx).Joins("Account").
+		Where("alerts.id > ? AND alerts.deleted_at_unix = 0", criteria.AfterID).
+		Where("alerts.alert_status IN ?", []string{model.StatusActive, model.StatusTriggered}).
+		// an alert without expiration time never expires
+		Where("alerts.expiration_time > ? OR alerts.expiration_time IS NULL", criteria.Now).
+		Order("alerts.id ASC").
+		Limit(int(criteria.Limit)).
 		Find(&ret).Error
 	if err != nil {
-		return nil, 0, err
+		logger.Errorw("failed to find active alerts", "err", err)
+		return nil, err
 	}
-
-	return ret, totalCount, nil
+	return ret, nil
 }
 
 func (a *alertDB) DeleteAlertBySlug(ctx context.Context, accountId uint, slug string) error {
@@ -214,7 +349,10 @@ func (a *alertDB) DeleteAlertBySlug(ctx context.Context, accountId uint, slug st
 	chain := db.WithContext(ctx).Model(&model.Alert{}).
 		Where("slug = ? AND deleted_at_unix = 0", slug).
 		Where("account_id = ?", accountId).
-		Update("deleted_at_unix", time.Now().Unix())
+		UpdateColumns(map[string]interface{}{
+			"deleted_at_unix": time.Now().Unix(),
+			"alert_status":    model.StatusCancelled,
+		})
 	if chain.Error != nil {
 		logger.Errorw("failed to delete an alert", "err", chain.Error)
 		return chain.Error
@@ -226,6 +364,70 @@ func (a *alertDB) DeleteAlertBySlug(ctx context.Context, accountId uint, slug st
 	return nil
 }
 
+func (a *alertDB) UpdateAlertStatus(ctx context.Context, id uint, from, to string) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.UpdateAlertStatus", "id", id, "from", from, "to", to)
+
+	chain := db.WithContext(ctx).Model(&model.Alert{}).
+		Where("id = ? AND alert_status = ? AND deleted_at_unix = 0", id, from).
+		UpdateColumns(map[string]interface{}{
+			"alert_status": to,
+			"updated_at":   time.Now(),
+		})
+	if chain.Error != nil {
+		logger.Errorw("failed to update an alert status", "err", chain.Error)
+		return chain.Error
+	}
+	if chain.RowsAffected == 0 {
+		logger.Error("failed to update an alert status because not found")
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+func (a *alertDB) TriggerAlert(ctx context.Context, id uint, at time.Time) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.TriggerAlert", "id", id, "at", at)
+
+	chain := db.WithContext(ctx).Model(&model.Alert{}).
+		Where("id = ? AND alert_status = ? AND deleted_at_unix = 0", id, model.StatusActive).
+		UpdateColumns(map[string]interface{}{
+			"alert_status":      model.StatusTriggered,
+			"last_triggered_at": at,
+			"updated_at":        at,
+		})
+	if chain.Error != nil {
+		logger.Errorw("failed to trigger an alert", "err", chain.Error)
+		return chain.Error
+	}
+	if chain.RowsAffected == 0 {
+		logger.Error("failed to trigger an alert because not found")
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+func (a *alertDB) ExpireAlerts(ctx context.Context, now time.Time) (int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.ExpireAlerts", "now", now)
+
+	chain := db.WithContext(ctx).Model(&model.Alert{}).
+		Where("alert_status IN ? AND deleted_at_unix = 0", []string{model.StatusActive, model.StatusTriggered, model.StatusPaused}).
+		Where("expiration_time <= ?", now).
+		UpdateColumns(map[string]interface{}{
+			"alert_status": model.StatusExpired,
+			"updated_at":   now,
+		})
+	if chain.Error != nil {
+		logger.Errorw("failed to expire alerts", "err", chain.Error)
+		return 0, chain.Error
+	}
+	return chain.RowsAffected, nil
+}
+
 // NewAlertDB creates a new alert db with given db
 func NewAlertDB(db *gorm.DB) AlertDB {
 	return &alertDB{
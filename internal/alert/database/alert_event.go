@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	"kek-backend/pkg/logging"
+	"time"
+)
+
+func (a *alertDB) SaveAlertEvent(ctx context.Context, event *model.AlertEvent) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.SaveAlertEvent", "event", event)
+
+	if err := db.WithContext(ctx).Create(event).Error; err != nil {
+		logger.Errorw("alert.db.SaveAlertEvent failed to save alert event", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (a *alertDB) UpdateAlertEventDelivery(ctx context.Context, id uint, status, deliveryErr string) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.UpdateAlertEventDelivery", "id", id, "status", status, "deliveryErr", deliveryErr)
+
+	chain := db.WithContext(ctx).Model(&model.AlertEvent{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"delivery_status": status,
+			"delivery_error":  deliveryErr,
+			"updated_at":      time.Now(),
+		})
+	if chain.Error != nil {
+		logger.Errorw("alert.db.UpdateAlertEventDelivery failed to update an alert event", "err", chain.Error)
+		return chain.Error
+	}
+	if chain.RowsAffected == 0 {
+		logger.Error("alert.db.UpdateAlertEventDelivery failed to update an alert event because not found")
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+func (a *alertDB) FindAlertEvents(ctx context.Context, criteria IterateAlertEventCriteria) ([]*model.AlertEvent, int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.FindAlertEvents", "criteria", criteria)
+
+	chain := db.WithContext(ctx).Model(&model.AlertEvent{}).Where("alert_id = ?", criteria.AlertID)
+
+	var totalCount int64
+	if err := chain.Count(&totalCount).Error; err != nil {
+		logger.Errorw("alert.db.FindAlertEvents failed to get total count", "err", err)
+		return nil, 0, err
+	}
+
+	var ret []*model.AlertEvent
+	err := chain.Order("triggered_at DESC, id DESC").
+		Offset(int(criteria.Offset)).
+		Limit(int(criteria.Limit)).
+		Find(&ret).Error
+	if err != nil {
+		logger.Errorw("alert.db.FindAlertEvents failed to find alert events", "err", err)
+		return nil, 0, err
+	}
+	return ret, totalCount, nil
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	"time"
+)
+
+func (s *DBSuite) TestSaveAlertEvent() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	event := newAlertEvent(alert.ID, time.Now())
+
+	// when
+	err := s.db.SaveAlertEvent(nil, event)
+
+	// then
+	s.NoError(err)
+	s.NotZero(event.ID)
+	events, total, err := s.db.FindAlertEvents(nil, IterateAlertEventCriteria{AlertID: alert.ID, Limit: 10})
+	s.NoError(err)
+	s.Equal(int64(1), total)
+	s.Len(events, 1)
+	s.Equal(event.ObservedPrice, events[0].ObservedPrice)
+	s.Equal(event.Condition, events[0].Condition)
+	s.Equal(model.DeliveryPending, events[0].DeliveryStatus)
+}
+
+func (s *DBSuite) TestUpdateAlertEventDelivery() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	event := newAlertEvent(alert.ID, time.Now())
+	s.NoError(s.db.SaveAlertEvent(nil, event))
+
+	// when
+	err := s.db.UpdateAlertEventDelivery(nil, event.ID, model.DeliveryFailed, "invalid registration")
+
+	// then
+	s.NoError(err)
+	events, _, err := s.db.FindAlertEvents(nil, IterateAlertEventCriteria{AlertID: alert.ID, Limit: 10})
+	s.NoError(err)
+	s.Equal(model.DeliveryFailed, events[0].DeliveryStatus)
+	s.Equal("invalid registration", events[0].DeliveryError)
+}
+
+func (s *DBSuite) TestUpdateAlertEventDelivery_FailIfNotExist() {
+	// when
+	err := s.db.UpdateAlertEventDelivery(nil, 100, model.DeliverySent, "")
+
+	// then
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestFindAlertEvents() {
+	// given
+	now := time.Now()
+	alert1 := newAlert("alert1", "alert1", "body1", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert1))
+	alert2 := newAlert("alert2", "alert2", "body2", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert2))
+	var events []*model.AlertEvent
+	for i := 0; i < 5; i++ {
+		event := newAlertEvent(alert1.ID, now.Add(time.Duration(i)*time.Minute))
+		s.NoError(s.db.SaveAlertEvent(nil, event))
+		events = append(events, event)
+	}
+	s.NoError(s.db.SaveAlertEvent(nil, newAlertEvent(alert2.ID, now)))
+
+	// when
+	results, total, err := s.db.FindAlertEvents(nil, IterateAlertEventCriteria{AlertID: alert1.ID, Offset: 1, Limit: 2})
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(5), total)
+	s.Len(results, 2)
+	s.Equal(events[3].ID, results[0].ID)
+	s.Equal(events[2].ID, results[1].ID)
+}
+
+func newAlertEvent(alertID uint, triggeredAt time.Time) *model.AlertEvent {
+	return &model.AlertEvent{
+		AlertID:        alertID,
+		TriggeredAt:    triggeredAt,
+		ObservedPrice:  1450.5,
+		Condition:      "price below 1500",
+		DeliveryStatus: model.DeliveryPending,
+	}
+}
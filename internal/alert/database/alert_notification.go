@@ -0,0 +1,205 @@
+package database
+
+import (
+	"context"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	"kek-backend/pkg/logging"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (a *alertDB) SaveNotifications(ctx context.Context, notifications []*model.Notification) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.SaveNotifications", "count", len(notifications))
+
+	if len(notifications) == 0 {
+		return nil
+	}
+	if err := db.WithContext(ctx).Omit("Account").Create(&notifications).Error; err != nil {
+		logger.Errorw("alert.db.SaveNotifications failed to save notifications", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (a *alertDB) ClaimDueNotifications(ctx context.Context, now time.Time, lease time.Duration, limit uint) ([]*model.Notification, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.ClaimDueNotifications", "now", now, "lease", lease, "limit", limit)
+
+	var ret []*model.Notification
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// rows locked by another dispatcher are skipped instead of waited for
+		var ids []uint
+		err := tx.Model(&model.Notification{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.NotificationPending, now).
+			Order("id ASC").
+			Limit(int(limit)).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		err = tx.Model(&model.Notification{}).
+			Where("id IN ?", ids).
+			UpdateColumns(map[string]interface{}{
+				"next_attempt_at": now.Add(lease).UTC(),
+				"updated_at":      time.Now(),
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Joins("Account").
+			Where("notifications.id IN ?", ids).
+			Order("notifications.id ASC").
+			Find(&ret).Error
+	})
+	if err != nil {
+		logger.Errorw("alert.db.ClaimDueNotifications failed to claim notifications", "err", err)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (a *alertDB) FindNotificationsByEvent(ctx context.Context, eventId uint) ([]*model.Notification, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.FindNotificationsByEvent", "eventId", eventId)
+
+	var ret []*model.Notification
+	err := db.WithContext(ctx).
+		Where("alert_event_id = ?", eventId).
+		Order("id ASC").
+		Find(&ret).Error
+	if err != nil {
+		logger.Errorw("alert.db.FindNotificationsByEvent failed to find notifications", "err", err)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (a *alertDB) FindNotification(ctx context.Context, id uint) (*model.Notification, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.FindNotification", "id", id)
+
+	var ret model.Notification
+	if err := db.WithContext(ctx).Joins("Account").First(&ret, "notifications.id = ?", id).Error; err != nil {
+		logger.Errorw("alert.db.FindNotification failed to find notification", "err", err)
+		if database.IsRecordNotFoundErr(err) {
+			return nil, database.ErrNotFound
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (a *alertDB) FindNotifications(ctx context.Context, criteria IterateNotificationCriteria) ([]*model.Notification, int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.FindNotifications", "criteria", criteria)
+
+	chain := db.WithContext(ctx).Model(&model.Notification{})
+	if criteria.Status != "" {
+		chain = chain.Where("notifications.status = ?", criteria.Status)
+	}
+
+	var totalCount int64
+	if err := chain.Count(&totalCount).Error; err != nil {
+		logger.Errorw("alert.db.FindNotifications failed to get total count", "err", err)
+		return nil, 0, err
+	}
+
+	var ret []*model.Notification
+	err := chain.Joins("Account").
+		Order("notifications.id DESC").
+		Offset(int(criteria.Offset)).
+		Limit(int(criteria.Limit)).
+		Find(&ret).Error
+	if err != nil {
+		logger.Errorw("alert.db.FindNotifications failed to find notifications", "err", err)
+		return nil, 0, err
+	}
+	return ret, totalCount, nil
+}
+
+func (a *alertDB) UpdateNotification(ctx context.Context, notification *model.Notification, fromAttempts int) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.UpdateNotification", "notification", notification, "fromAttempts", fromAttempts)
+
+	chain := db.WithContext(ctx).Model(&model.Notification{}).
+		Where("id = ? AND status = ? AND attempts = ?", notification.ID, model.NotificationPending, fromAttempts).
+		UpdateColumns(map[string]interface{}{
+			"status":          notification.Status,
+			"attempts":        notification.Attempts,
+			"next_attempt_at": notification.NextAttemptAt,
+			"last_error":      notification.LastError,
+			"updated_at":      time.Now(),
+		})
+	if chain.Error != nil {
+		logger.Errorw("alert.db.UpdateNotification failed to update a notification", "err", chain.Error)
+		return chain.Error
+	}
+	if chain.RowsAffected == 0 {
+		logger.Error("alert.db.UpdateNotification failed to update a notification because not found")
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+func (a *alertDB) ReplayNotification(ctx context.Context, id uint, at time.Time) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.ReplayNotification", "id", id, "at", at)
+
+	chain := db.WithContext(ctx).Model(&model.Notification{}).
+		Where("id = ? AND status = ?", id, model.NotificationDead).
+		UpdateColumns(map[string]interface{}{
+			"status":          model.NotificationPending,
+			"attempts":        0,
+			"next_attempt_at": at,
+			"updated_at":      time.Now(),
+		})
+	if chain.Error != nil {
+		logger.Errorw("alert.db.ReplayNotification failed to update a notification", "err", chain.Error)
+		return chain.Error
+	}
+	if chain.RowsAffected == 0 {
+		logger.Error("alert.db.ReplayNotification failed to update a notification because not found")
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+func (a *alertDB) SaveNotificationAttempt(ctx context.Context, attempt *model.NotificationAttempt) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.SaveNotificationAttempt", "attempt", attempt)
+
+	if err := db.WithContext(ctx).Create(attempt).Error; err != nil {
+		logger.Errorw("alert.db.SaveNotificationAttempt failed to save notification attempt", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (a *alertDB) CountSentNotifications(ctx context.Context, accountId uint, since time.Time) (int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.CountSentNotifications", "accountId", accountId, "since", since)
+
+	var count int64
+	err := db.WithContext(ctx).Model(&model.Notification{}).
+		Where("account_id = ? AND status = ? AND updated_at >= ?", accountId, model.NotificationSent, since).
+		Count(&count).Error
+	if err != nil {
+		logger.Errorw("alert.db.CountSentNotifications failed to count notifications", "err", err)
+		return 0, err
+	}
+	return count, nil
+}
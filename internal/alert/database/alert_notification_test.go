@@ -0,0 +1,217 @@
+package database
+
+import (
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	"time"
+)
+
+func (s *DBSuite) TestSaveNotifications() {
+	// given
+	now := time.Now()
+	event := s.newSavedAlertEvent(now)
+	notifications := []*model.Notification{
+		newNotification(event, "push", now),
+		newNotification(event, "email", now),
+	}
+
+	// when
+	err := s.db.SaveNotifications(nil, notifications)
+
+	// then
+	s.NoError(err)
+	s.NotZero(notifications[0].ID)
+	s.NotZero(notifications[1].ID)
+	finds, err := s.db.FindNotificationsByEvent(nil, event.ID)
+	s.NoError(err)
+	s.Len(finds, 2)
+	s.Equal("push", finds[0].Action)
+	s.Equal("email", finds[1].Action)
+	s.Equal(model.NotificationPending, finds[0].Status)
+}
+
+func (s *DBSuite) TestClaimDueNotifications() {
+	// given
+	now := time.Now()
+	event := s.newSavedAlertEvent(now)
+	due := newNotification(event, "push", now.Add(-time.Minute))
+	later := newNotification(event, "webhook", now.Add(time.Minute))
+	sent := newNotification(event, "email", now.Add(-time.Minute))
+	sent.Status = model.NotificationSent
+	s.NoError(s.db.SaveNotifications(nil, []*model.Notification{due, later, sent}))
+
+	// when
+	finds, err := s.db.ClaimDueNotifications(nil, now, time.Hour, 10)
+
+	// then
+	s.NoError(err)
+	s.Len(finds, 1)
+	s.Equal(due.ID, finds[0].ID)
+	s.Equal(dUser.Username, finds[0].Account.Username)
+	s.WithinDuration(now.Add(time.Hour), finds[0].NextAttemptAt, time.Second)
+	// a claimed notification is not claimed again within the lease
+	claimed, err := s.db.ClaimDueNotifications(nil, now, time.Hour, 10)
+	s.NoError(err)
+	s.Empty(claimed)
+}
+
+func (s *DBSuite) TestFindNotifications() {
+	// given
+	now := time.Now()
+	event := s.newSavedAlertEvent(now)
+	var dead []*model.Notification
+	for i := 0; i < 3; i++ {
+		n := newNotification(event, "push", now)
+		n.Status = model.NotificationDead
+		dead = append(dead, n)
+	}
+	s.NoError(s.db.SaveNotifications(nil, append(dead, newNotification(event, "push", now))))
+
+	// when
+	finds, total, err := s.db.FindNotifications(nil, IterateNotificationCriteria{Status: model.NotificationDead, Offset: 1, Limit: 1})
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(3), total)
+	s.Len(finds, 1)
+	s.Equal(dead[1].ID, finds[0].ID)
+	s.Equal(dUser.Username, finds[0].Account.Username)
+}
+
+func (s *DBSuite) TestFindNotification_FailIfNotExist() {
+	// when
+	find, err := s.db.FindNotification(nil, 1)
+
+	// then
+	s.Nil(find)
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestUpdateNotification() {
+	// given
+	now := time.Now()
+	event := s.newSavedAlertEvent(now)
+	n := newNotification(event, "push", now)
+	s.NoError(s.db.SaveNotifications(nil, []*model.Notification{n}))
+	n.Status, n.Attempts, n.LastError = model.NotificationDead, 1, "timeout"
+
+	// when
+	err := s.db.UpdateNotification(nil, n, 0)
+
+	// then
+	s.NoError(err)
+	find, err := s.db.FindNotification(nil, n.ID)
+	s.NoError(err)
+	s.Equal(model.NotificationDead, find.Status)
+	s.Equal(1, find.Attempts)
+	s.Equal("timeout", find.LastError)
+}
+
+func (s *DBSuite) TestUpdateNotification_FailIfAttemptsChanged() {
+	// given
+	now := time.Now()
+	event := s.newSavedAlertEvent(now)
+	n := newNotification(event, "push", now)
+	s.NoError(s.db.SaveNotifications(nil, []*model.Notification{n}))
+	n.Status, n.Attempts = model.NotificationSent, 2
+
+	// when
+	err := s.db.UpdateNotification(nil, n, 1)
+
+	// then
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestReplayNotification() {
+	// given
+	now := time.Now()
+	event := s.newSavedAlertEvent(now)
+	n := newNotification(event, "push", now)
+	n.Status, n.Attempts = model.NotificationDead, 5
+	s.NoError(s.db.SaveNotifications(nil, []*model.Notification{n}))
+
+	// when
+	err := s.db.ReplayNotification(nil, n.ID, now.Add(time.Minute))
+
+	// then
+	s.NoError(err)
+	find, err := s.db.FindNotification(nil, n.ID)
+	s.NoError(err)
+	s.Equal(model.NotificationPending, find.Status)
+	s.Equal(0, find.Attempts)
+	s.WithinDuration(now.Add(time.Minute), find.NextAttemptAt, time.Second)
+}
+
+func (s *DBSuite) TestReplayNotification_FailIfNotDead() {
+	// given
+	now := time.Now()
+	event := s.newSavedAlertEvent(now)
+	n := newNotification(event, "push", now)
+	s.NoError(s.db.SaveNotifications(nil, []*model.Notification{n}))
+
+	// when
+	err := s.db.ReplayNotification(nil, n.ID, now)
+
+	// then
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestSaveNotificationAttempt() {
+	// given
+	now := time.Now()
+	event := s.newSavedAlertEvent(now)
+	n := newNotification(event, "push", now)
+	s.NoError(s.db.SaveNotifications(nil, []*model.Notification{n}))
+	attempt := model.NotificationAttempt{NotificationID: n.ID, AttemptedAt: now, Error: "timeout"}
+
+	// when
+	err := s.db.SaveNotificationAttempt(nil, &attempt)
+
+	// then
+	s.NoError(err)
+	s.NotZero(attempt.ID)
+}
+
+func (s *DBSuite) TestCountSentNotifications() {
+	// given
+	now := time.Now()
+	event := s.newSavedAlertEvent(now)
+	sent := newNotification(event, "push", now)
+	sent.Status = model.NotificationSent
+	s.NoError(s.db.SaveNotifications(nil, []*model.Notification{sent, newNotification(event, "email", now)}))
+
+	// when
+	count, err := s.db.CountSentNotifications(nil, dUser.ID, now.Add(-time.Hour))
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(1), count)
+	count, err = s.db.CountSentNotifications(nil, dUser.ID, now.Add(time.Minute))
+	s.NoError(err)
+	s.Zero(count)
+}
+
+func (s *DBSuite) newSavedAlertEvent(triggeredAt time.Time) *model.AlertEvent {
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	event := newAlertEvent(alert.ID, triggeredAt)
+	s.NoError(s.db.SaveAlertEvent(nil, event))
+	return event
+}
+
+func newNotification(event *model.AlertEvent, action string, nextAttemptAt time.Time) *model.Notification {
+	return &model.Notification{
+		AlertEventID:  event.ID,
+		AlertID:       event.AlertID,
+		Action:        action,
+		Title:         "title1",
+		Body:          "body",
+		Slug:          "title1",
+		Condition:     event.Condition,
+		ObservedPrice: event.ObservedPrice,
+		TriggeredAt:   event.TriggeredAt,
+		Status:        model.NotificationPending,
+		NextAttemptAt: nextAttemptAt,
+		AccountId:     dUser.ID,
+	}
+}
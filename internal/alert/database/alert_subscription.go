@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	accountModel "kek-backend/internal/account/model"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	"kek-backend/pkg/logging"
+	"time"
+)
+
+func (a *alertDB) SaveSubscription(ctx context.Context, alertId, accountId uint) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.SaveSubscription", "alertId", alertId, "accountId", accountId)
+
+	subscription := model.AlertSubscription{
+		AlertID:   alertId,
+		AccountID: accountId,
+		CreatedAt: time.Now(),
+	}
+	if err := db.WithContext(ctx).Create(&subscription).Error; err != nil {
+		logger.Errorw("alert.db.SaveSubscription failed to save subscription", "err", err)
+		if database.IsKeyConflictErr(err) {
+			return database.ErrKeyConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (a *alertDB) DeleteSubscription(ctx context.Context, alertId, accountId uint) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.DeleteSubscription", "alertId", alertId, "accountId", accountId)
+
+	chain := db.WithContext(ctx).
+		Where("alert_id = ? AND account_id = ?", alertId, accountId).
+		Delete(&model.AlertSubscription{})
+	if chain.Error != nil {
+		logger.Errorw("alert.db.DeleteSubscription failed to delete subscription", "err", chain.Error)
+		return chain.Error
+	}
+	if chain.RowsAffected == 0 {
+		logger.Error("alert.db.DeleteSubscription failed to delete subscription because not found")
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+func (a *alertDB) FindSubscribers(ctx context.Context, alertId uint) ([]*accountModel.Account, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.FindSubscribers", "alertId", alertId)
+
+	var ret []*accountModel.Account
+	err := db.WithContext(ctx).Model(&accountModel.Account{}).
+		Joins("JOIN alert_subscriptions s ON s.account_id = accounts.id").
+		Where("s.alert_id = ?", alertId).
+		Order("s.id ASC").
+		Find(&ret).Error
+	if err != nil {
+		logger.Errorw("alert.db.FindSubscribers failed to find subscribers", "err", err)
+		return nil, err
+	}
+	return ret, nil
+}
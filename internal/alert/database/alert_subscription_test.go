@@ -0,0 +1,102 @@
+package database
+
+import (
+	accountModel "kek-backend/internal/account/model"
+	"kek-backend/internal/database"
+)
+
+func (s *DBSuite) TestSaveSubscription() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	subscriber := newSubscriber(s, "user2")
+
+	// when
+	err := s.db.SaveSubscription(nil, alert.ID, subscriber.ID)
+
+	// then
+	s.NoError(err)
+	subscribers, err := s.db.FindSubscribers(nil, alert.ID)
+	s.NoError(err)
+	s.Len(subscribers, 1)
+	s.Equal(subscriber.ID, subscribers[0].ID)
+	s.Equal(subscriber.Username, subscribers[0].Username)
+}
+
+func (s *DBSuite) TestSaveSubscription_FailIfDuplicate() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	subscriber := newSubscriber(s, "user2")
+	s.NoError(s.db.SaveSubscription(nil, alert.ID, subscriber.ID))
+
+	// when
+	err := s.db.SaveSubscription(nil, alert.ID, subscriber.ID)
+
+	// then
+	s.Equal(database.ErrKeyConflict, err)
+}
+
+func (s *DBSuite) TestDeleteSubscription() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	subscriber1 := newSubscriber(s, "user2")
+	subscriber2 := newSubscriber(s, "user3")
+	s.NoError(s.db.SaveSubscription(nil, alert.ID, subscriber1.ID))
+	s.NoError(s.db.SaveSubscription(nil, alert.ID, subscriber2.ID))
+
+	// when
+	err := s.db.DeleteSubscription(nil, alert.ID, subscriber1.ID)
+
+	// then
+	s.NoError(err)
+	subscribers, err := s.db.FindSubscribers(nil, alert.ID)
+	s.NoError(err)
+	s.Len(subscribers, 1)
+	s.Equal(subscriber2.ID, subscribers[0].ID)
+}
+
+func (s *DBSuite) TestDeleteSubscription_FailIfNotExist() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+
+	// when
+	err := s.db.DeleteSubscription(nil, alert.ID, dUser.ID)
+
+	// then
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestFindSubscribers() {
+	// given
+	alert1 := newAlert("alert1", "alert1", "body1", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert1))
+	alert2 := newAlert("alert2", "alert2", "body2", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert2))
+	subscriber1 := newSubscriber(s, "user2")
+	subscriber2 := newSubscriber(s, "user3")
+	s.NoError(s.db.SaveSubscription(nil, alert1.ID, subscriber2.ID))
+	s.NoError(s.db.SaveSubscription(nil, alert1.ID, subscriber1.ID))
+	s.NoError(s.db.SaveSubscription(nil, alert2.ID, subscriber1.ID))
+
+	// when
+	subscribers, err := s.db.FindSubscribers(nil, alert1.ID)
+
+	// then
+	s.NoError(err)
+	s.Len(subscribers, 2)
+	s.Equal(subscriber2.ID, subscribers[0].ID)
+	s.Equal(subscriber1.ID, subscribers[1].ID)
+}
+
+func newSubscriber(s *DBSuite, username string) *accountModel.Account {
+	subscriber := accountModel.Account{
+		Username: username,
+		Email:    username + "@gmail.com",
+		Password: "password",
+	}
+	s.NoError(s.accountDB.Save(nil, &subscriber))
+	return &subscriber
+}
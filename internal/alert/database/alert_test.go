@@ -41,6 +41,10 @@ func (s *DBSuite) SetupSuite() {
 func (s *DBSuite) SetupTest() {
 	s.NoError(database.DeleteRecordAll(s.T(), s.originDB, []string{
 		"comments", "id > 0",
+		"notification_attempts", "id > 0",
+		"notifications", "id > 0",
+		"alert_subscriptions", "id > 0",
+		"alert_events", "id > 0",
 		"alerts", "id > 0",
 		"accounts", "id > 0",
 	}))
@@ -172,7 +176,7 @@ func (s *DBSuite) TestFindAlerts() {
 	s.NoError(s.db.SaveAlert(nil, alert7))
 
 	criteria := IterateAlertCriteria{
-		Account: user1.Username,
+		Account: user1.ID,
 		Offset:  0,
 		Limit:   2,
 	}
@@ -198,6 +202,119 @@ func (s *DBSuite) TestFindAlerts() {
 	s.assertAlert(alert1, results[0])
 }
 
+func (s *DBSuite) TestFindAlerts_PublicOnly() {
+	// given
+	// alert1 - private
+	// alert2 - public
+	alert1 := newAlert("alert1", "alert1", "body1", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert1))
+	alert2 := newAlert("alert2", "alert2", "body2", dUser)
+	alert2.Visibility = model.VisibilityPublic
+	s.NoError(s.db.SaveAlert(nil, alert2))
+
+	// when
+	results, total, err := s.db.FindAlerts(nil, IterateAlertCriteria{
+		Account:    dUser.ID,
+		Visibility: model.VisibilityPublic,
+		Limit:      10,
+	})
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(1), total)
+	s.Equal(1, len(results))
+	s.assertAlert(alert2, results[0])
+}
+
+func (s *DBSuite) TestFindAlerts_ByAuthor() {
+	// given
+	// dUser  - alert1 public
+	// user2  - alert2 public
+	user2 := accountModel.Account{Username: "test-user2", Email: "test-user2@gmail.com", Password: "password"}
+	s.NoError(s.accountDB.Save(nil, &user2))
+	alert1 := newAlert("alert1", "alert1", "body1", dUser)
+	alert1.Visibility = model.VisibilityPublic
+	s.NoError(s.db.SaveAlert(nil, alert1))
+	alert2 := newAlert("alert2", "alert2", "body2", user2)
+	alert2.Visibility = model.VisibilityPublic
+	s.NoError(s.db.SaveAlert(nil, alert2))
+
+	// when
+	results, total, err := s.db.FindAlerts(nil, IterateAlertCriteria{
+		Author:     user2.Username,
+		Visibility: model.VisibilityPublic,
+		Limit:      10,
+	})
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(1), total)
+	s.Equal(1, len(results))
+	s.assertAlert(alert2, results[0])
+}
+
+func (s *DBSuite) TestFindActiveAlerts() {
+	// given
+	// User1
+	// alert1 - active        <- first itr [0]
+	// alert2 - triggered     <- first itr [1]
+	// alert3 - paused
+	// alert4 - expired time
+	// alert5 - deleted
+	// User2
+	// alert6 - active        <- second itr [0]
+	// alert7 - no expiration <- second itr [1]
+	now := time.Now()
+	user1 := accountModel.Account{Username: "test-user1", Email: "test-user1@gmail.com", Password: "password"}
+	s.NoError(s.accountDB.Save(nil, &user1))
+	alert1 := newAlert("alert1", "alert1", "body1", user1)
+	s.NoError(s.db.SaveAlert(nil, alert1))
+	alert2 := newAlert("alert2", "alert2", "body2", user1)
+	alert2.AlertStatus = model.StatusTriggered
+	s.NoError(s.db.SaveAlert(nil, alert2))
+	alert3 := newAlert("alert3", "alert3", "body3", user1)
+	alert3.AlertStatus = model.StatusPaused
+	s.NoError(s.db.SaveAlert(nil, alert3))
+	alert4 := newAlert("alert4", "alert4", "body4", user1)
+	alert4.ExpirationTime = now.Add(-time.Minute)
+	s.NoError(s.db.SaveAlert(nil, alert4))
+	alert5 := newAlert("alert5", "alert5", "body5", user1)
+	s.NoError(s.db.SaveAlert(nil, alert5))
+	s.NoError(s.db.DeleteAlertBySlug(nil, user1.ID, alert5.Slug))
+
+	user2 := accountModel.Account{Username: "test-user2", Email: "test-user2@gmail.com", Password: "password"}
+	s.NoError(s.accountDB.Save(nil, &user2))
+	alert6 := newAlert("alert6", "alert6", "body6", user2)
+	s.NoError(s.db.SaveAlert(nil, alert6))
+	alert7 := newAlert("alert7", "alert7", "body7", user2)
+	s.NoError(s.db.SaveAlert(nil, alert7))
+	s.NoError(s.originDB.Model(&model.Alert{}).Where("id = ?", alert7.ID).Update("expiration_time", gorm.Expr("NULL")).Error)
+
+	criteria := IterateActiveAlertCriteria{
+		Limit: 2,
+		Now:   now,
+	}
+
+	// when : first iteration
+	results, err := s.db.FindActiveAlerts(nil, criteria)
+
+	// then
+	s.NoError(err)
+	s.Equal(2, len(results))
+	s.assertAlert(alert1, results[0])
+	s.assertAlert(alert2, results[1])
+
+	// second iteration
+	criteria.AfterID = results[len(results)-1].ID
+	results, err = s.db.FindActiveAlerts(nil, criteria)
+
+	// then
+	s.NoError(err)
+	s.Equal(2, len(results))
+	s.assertAlert(alert6, results[0])
+	s.Equal(alert7.ID, results[1].ID)
+}
+
 func (s *DBSuite) TestDeleteAlertBySlug() {
 	// given
 	alert := newAlert("title1", "title1", "body", dUser)
@@ -247,6 +364,152 @@ func (s *DBSuite) TestDeleteAlertBySlug_FailIfNotExist() {
 	}
 }
 
+func (s *DBSuite) TestDeleteAlertBySlug_MarkCancelled() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+
+	// when
+	err := s.db.DeleteAlertBySlug(nil, dUser.ID, alert.Slug)
+
+	// then
+	s.NoError(err)
+	var find model.Alert
+	s.NoError(s.originDB.First(&find, alert.ID).Error)
+	s.Equal(model.StatusCancelled, find.AlertStatus)
+}
+
+func (s *DBSuite) TestUpdateAlert() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	alert.Slug, alert.Title, alert.Body = "title2", "title2", "body2"
+	alert.AlertValue, alert.CooldownSecs = "1400", 60
+
+	// when
+	err := s.db.UpdateAlert(nil, dUser.ID, alert)
+
+	// then
+	s.NoError(err)
+	// the slug is kept
+	find, err := s.db.FindAlertBySlug(nil, "title1")
+	s.NoError(err)
+	s.Equal(alert.ID, find.ID)
+	s.Equal("title2", find.Title)
+	s.Equal("body2", find.Body)
+	s.Equal("1400", find.AlertValue)
+	s.Equal(int64(60), find.CooldownSecs)
+	_, err = s.db.FindAlertBySlug(nil, "title2")
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestUpdateAlert_FailIfNotOwner() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	alert.Body = "body2"
+
+	// when
+	err := s.db.UpdateAlert(nil, dUser.ID+1, alert)
+
+	// then
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestUpdateAlertStatus() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+
+	// when
+	err := s.db.UpdateAlertStatus(nil, alert.ID, model.StatusActive, model.StatusPaused)
+
+	// then
+	s.NoError(err)
+	find, err := s.db.FindAlertBySlug(nil, alert.Slug)
+	s.NoError(err)
+	s.Equal(model.StatusPaused, find.AlertStatus)
+}
+
+func (s *DBSuite) TestUpdateAlertStatus_FailIfStatusChanged() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	s.NoError(s.db.UpdateAlertStatus(nil, alert.ID, model.StatusActive, model.StatusPaused))
+
+	// when
+	err := s.db.UpdateAlertStatus(nil, alert.ID, model.StatusActive, model.StatusTriggered)
+
+	// then
+	s.Error(err)
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestTriggerAlert() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	at := time.Now()
+
+	// when
+	err := s.db.TriggerAlert(nil, alert.ID, at)
+
+	// then
+	s.NoError(err)
+	find, err := s.db.FindAlertBySlug(nil, alert.Slug)
+	s.NoError(err)
+	s.Equal(model.StatusTriggered, find.AlertStatus)
+	s.NotNil(find.LastTriggeredAt)
+	s.WithinDuration(at, *find.LastTriggeredAt, time.Second)
+}
+
+func (s *DBSuite) TestTriggerAlert_FailIfNotActive() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	s.NoError(s.db.TriggerAlert(nil, alert.ID, time.Now()))
+
+	// when
+	err := s.db.TriggerAlert(nil, alert.ID, time.Now())
+
+	// then
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestExpireAlerts() {
+	// given
+	now := time.Now()
+	expired := newAlert("alert1", "alert1", "body1", dUser)
+	expired.ExpirationTime = now.Add(-time.Minute)
+	s.NoError(s.db.SaveAlert(nil, expired))
+	paused := newAlert("alert2", "alert2", "body2", dUser)
+	paused.ExpirationTime = now.Add(-time.Minute)
+	paused.AlertStatus = model.StatusPaused
+	s.NoError(s.db.SaveAlert(nil, paused))
+	notExpired := newAlert("alert3", "alert3", "body3", dUser)
+	notExpired.ExpirationTime = now.Add(time.Minute)
+	s.NoError(s.db.SaveAlert(nil, notExpired))
+
+	// when
+	count, err := s.db.ExpireAlerts(nil, now)
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(2), count)
+	for _, tc := range []struct {
+		Slug   string
+		Status string
+	}{
+		{Slug: expired.Slug, Status: model.StatusExpired},
+		{Slug: paused.Slug, Status: model.StatusExpired},
+		{Slug: notExpired.Slug, Status: model.StatusActive},
+	} {
+		find, err := s.db.FindAlertBySlug(nil, tc.Slug)
+		s.NoError(err)
+		s.Equal(tc.Status, find.AlertStatus)
+	}
+}
+
 func (s *DBSuite) assertAlert(expected, actual *model.Alert) {
 	s.Equal(expected.Slug, actual.Slug)
 	s.Equal(expected.Title, actual.Title)
@@ -261,9 +524,12 @@ func (s *DBSuite) assertAlert(expected, actual *model.Alert) {
 
 func newAlert(slug, title, body string, account accountModel.Account) *model.Alert {
 	return &model.Alert{
-		Slug:    slug,
-		Title:   title,
-		Body:    body,
-		Account: account,
+		Slug:           slug,
+		Title:          title,
+		Body:           body,
+		ExpirationTime: time.Now().Add(24 * time.Hour),
+		AlertStatus:    model.StatusActive,
+		Visibility:     model.VisibilityPrivate,
+		Account:        account,
 	}
 }
@@ -6,9 +6,13 @@ import (
 	context "context"
 	database "kek-backend/internal/alert/database"
 
+	accountmodel "kek-backend/internal/account/model"
+
 	mock "github.com/stretchr/testify/mock"
 
 	model "kek-backend/internal/alert/model"
+
+	time "time"
 )
 
 // AlertDB is an autogenerated mock type for the AlertDB type
@@ -16,6 +20,50 @@ type AlertDB struct {
 	mock.Mock
 }
 
+// ClaimDueNotifications provides a mock function with given fields: ctx, now, lease, limit
+func (_m *AlertDB) ClaimDueNotifications(ctx context.Context, now time.Time, lease time.Duration, limit uint) ([]*model.Notification, error) {
+	ret := _m.Called(ctx, now, lease, limit)
+
+	var r0 []*model.Notification
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Duration, uint) []*model.Notification); ok {
+		r0 = rf(ctx, now, lease, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Notification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Duration, uint) error); ok {
+		r1 = rf(ctx, now, lease, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountSentNotifications provides a mock function with given fields: ctx, accountId, since
+func (_m *AlertDB) CountSentNotifications(ctx context.Context, accountId uint, since time.Time) (int64, error) {
+	ret := _m.Called(ctx, accountId, since)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, uint, time.Time) int64); ok {
+		r0 = rf(ctx, accountId, since)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint, time.Time) error); ok {
+		r1 = rf(ctx, accountId, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteAlertBySlug provides a mock function with given fields: ctx, accountId, slug
 func (_m *AlertDB) DeleteAlertBySlug(ctx context.Context, accountId uint, slug string) error {
 	ret := _m.Called(ctx, accountId, slug)
@@ -30,13 +78,13 @@ func (_m *AlertDB) DeleteAlertBySlug(ctx context.Context, accountId uint, slug s
 	return r0
 }
 
-// DeleteCommentById provides a mock function with given fields: ctx, accountId, slug, id
-func (_m *AlertDB) DeleteCommentById(ctx context.Context, accountId uint, slug string, id uint) error {
-	ret := _m.Called(ctx, accountId, slug, id)
+// DeleteSubscription provides a mock function with given fields: ctx, alertId, accountId
+func (_m *AlertDB) DeleteSubscription(ctx context.Context, alertId uint, accountId uint) error {
+	ret := _m.Called(ctx, alertId, accountId)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint, string, uint) error); ok {
-		r0 = rf(ctx, accountId, slug, id)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(ctx, alertId, accountId)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -44,20 +92,43 @@ func (_m *AlertDB) DeleteCommentById(ctx context.Context, accountId uint, slug s
 	return r0
 }
 
-// DeleteComments provides a mock function with given fields: ctx, accountId, slug
-func (_m *AlertDB) DeleteComments(ctx context.Context, accountId uint, slug string) (int64, error) {
-	ret := _m.Called(ctx, accountId, slug)
+// ExpireAlerts provides a mock function with given fields: ctx, now
+func (_m *AlertDB) ExpireAlerts(ctx context.Context, now time.Time) (int64, error) {
+	ret := _m.Called(ctx, now)
 
 	var r0 int64
-	if rf, ok := ret.Get(0).(func(context.Context, uint, string) int64); ok {
-		r0 = rf(ctx, accountId, slug)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, now)
 	} else {
 		r0 = ret.Get(0).(int64)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, uint, string) error); ok {
-		r1 = rf(ctx, accountId, slug)
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindActiveAlerts provides a mock function with given fields: ctx, criteria
+func (_m *AlertDB) FindActiveAlerts(ctx context.Context, criteria database.IterateActiveAlertCriteria) ([]*model.Alert, error) {
+	ret := _m.Called(ctx, criteria)
+
+	var r0 []*model.Alert
+	if rf, ok := ret.Get(0).(func(context.Context, database.IterateActiveAlertCriteria) []*model.Alert); ok {
+		r0 = rf(ctx, criteria)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Alert)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, database.IterateActiveAlertCriteria) error); ok {
+		r1 = rf(ctx, criteria)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -88,6 +159,36 @@ func (_m *AlertDB) FindAlertBySlug(ctx context.Context, slug string) (*model.Ale
 	return r0, r1
 }
 
+// FindAlertEvents provides a mock function with given fields: ctx, criteria
+func (_m *AlertDB) FindAlertEvents(ctx context.Context, criteria database.IterateAlertEventCriteria) ([]*model.AlertEvent, int64, error) {
+	ret := _m.Called(ctx, criteria)
+
+	var r0 []*model.AlertEvent
+	if rf, ok := ret.Get(0).(func(context.Context, database.IterateAlertEventCriteria) []*model.AlertEvent); ok {
+		r0 = rf(ctx, criteria)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.AlertEvent)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(context.Context, database.IterateAlertEventCriteria) int64); ok {
+		r1 = rf(ctx, criteria)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.IterateAlertEventCriteria) error); ok {
+		r2 = rf(ctx, criteria)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // FindAlerts provides a mock function with given fields: ctx, criteria
 func (_m *AlertDB) FindAlerts(ctx context.Context, criteria database.IterateAlertCriteria) ([]*model.Alert, int64, error) {
 	ret := _m.Called(ctx, criteria)
@@ -118,6 +219,119 @@ func (_m *AlertDB) FindAlerts(ctx context.Context, criteria database.IterateAler
 	return r0, r1, r2
 }
 
+// FindNotification provides a mock function with given fields: ctx, id
+func (_m *AlertDB) FindNotification(ctx context.Context, id uint) (*model.Notification, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *model.Notification
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *model.Notification); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Notification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindNotifications provides a mock function with given fields: ctx, criteria
+func (_m *AlertDB) FindNotifications(ctx context.Context, criteria database.IterateNotificationCriteria) ([]*model.Notification, int64, error) {
+	ret := _m.Called(ctx, criteria)
+
+	var r0 []*model.Notification
+	if rf, ok := ret.Get(0).(func(context.Context, database.IterateNotificationCriteria) []*model.Notification); ok {
+		r0 = rf(ctx, criteria)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Notification)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(context.Context, database.IterateNotificationCriteria) int64); ok {
+		r1 = rf(ctx, criteria)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.IterateNotificationCriteria) error); ok {
+		r2 = rf(ctx, criteria)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// FindNotificationsByEvent provides a mock function with given fields: ctx, eventId
+func (_m *AlertDB) FindNotificationsByEvent(ctx context.Context, eventId uint) ([]*model.Notification, error) {
+	ret := _m.Called(ctx, eventId)
+
+	var r0 []*model.Notification
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*model.Notification); ok {
+		r0 = rf(ctx, eventId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Notification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, eventId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindSubscribers provides a mock function with given fields: ctx, alertId
+func (_m *AlertDB) FindSubscribers(ctx context.Context, alertId uint) ([]*accountmodel.Account, error) {
+	ret := _m.Called(ctx, alertId)
+
+	var r0 []*accountmodel.Account
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*accountmodel.Account); ok {
+		r0 = rf(ctx, alertId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*accountmodel.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, alertId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplayNotification provides a mock function with given fields: ctx, id, at
+func (_m *AlertDB) ReplayNotification(ctx context.Context, id uint, at time.Time) error {
+	ret := _m.Called(ctx, id, at)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = rf(ctx, id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RunInTx provides a mock function with given fields: ctx, f
 func (_m *AlertDB) RunInTx(ctx context.Context, f func(context.Context) error) error {
 	ret := _m.Called(ctx, f)
@@ -132,6 +346,27 @@ func (_m *AlertDB) RunInTx(ctx context.Context, f func(context.Context) error) e
 	return r0
 }
 
+// RunLocked provides a mock function with given fields: ctx, lockId, f
+func (_m *AlertDB) RunLocked(ctx context.Context, lockId int64, f func(context.Context) error) (bool, error) {
+	ret := _m.Called(ctx, lockId, f)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, int64, func(context.Context) error) bool); ok {
+		r0 = rf(ctx, lockId, f)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, func(context.Context) error) error); ok {
+		r1 = rf(ctx, lockId, f)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SaveAlert provides a mock function with given fields: ctx, alert
 func (_m *AlertDB) SaveAlert(ctx context.Context, alert *model.Alert) error {
 	ret := _m.Called(ctx, alert)
@@ -145,3 +380,129 @@ func (_m *AlertDB) SaveAlert(ctx context.Context, alert *model.Alert) error {
 
 	return r0
 }
+
+// SaveAlertEvent provides a mock function with given fields: ctx, event
+func (_m *AlertDB) SaveAlertEvent(ctx context.Context, event *model.AlertEvent) error {
+	ret := _m.Called(ctx, event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.AlertEvent) error); ok {
+		r0 = rf(ctx, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveNotificationAttempt provides a mock function with given fields: ctx, attempt
+func (_m *AlertDB) SaveNotificationAttempt(ctx context.Context, attempt *model.NotificationAttempt) error {
+	ret := _m.Called(ctx, attempt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.NotificationAttempt) error); ok {
+		r0 = rf(ctx, attempt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveNotifications provides a mock function with given fields: ctx, notifications
+func (_m *AlertDB) SaveNotifications(ctx context.Context, notifications []*model.Notification) error {
+	ret := _m.Called(ctx, notifications)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.Notification) error); ok {
+		r0 = rf(ctx, notifications)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveSubscription provides a mock function with given fields: ctx, alertId, accountId
+func (_m *AlertDB) SaveSubscription(ctx context.Context, alertId uint, accountId uint) error {
+	ret := _m.Called(ctx, alertId, accountId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(ctx, alertId, accountId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TriggerAlert provides a mock function with given fields: ctx, id, at
+func (_m *AlertDB) TriggerAlert(ctx context.Context, id uint, at time.Time) error {
+	ret := _m.Called(ctx, id, at)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = rf(ctx, id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateAlert provides a mock function with given fields: ctx, accountId, alert
+func (_m *AlertDB) UpdateAlert(ctx context.Context, accountId uint, alert *model.Alert) error {
+	ret := _m.Called(ctx, accountId, alert)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, *model.Alert) error); ok {
+		r0 = rf(ctx, accountId, alert)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateAlertEventDelivery provides a mock function with given fields: ctx, id, status, deliveryErr
+func (_m *AlertDB) UpdateAlertEventDelivery(ctx context.Context, id uint, status string, deliveryErr string) error {
+	ret := _m.Called(ctx, id, status, deliveryErr)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, string) error); ok {
+		r0 = rf(ctx, id, status, deliveryErr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateAlertStatus provides a mock function with given fields: ctx, id, from, to
+func (_m *AlertDB) UpdateAlertStatus(ctx context.Context, id uint, from string, to string) error {
+	ret := _m.Called(ctx, id, from, to)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, string) error); ok {
+		r0 = rf(ctx, id, from, to)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateNotification provides a mock function with given fields: ctx, notification, fromAttempts
+func (_m *AlertDB) UpdateNotification(ctx context.Context, notification *model.Notification, fromAttempts int) error {
+	ret := _m.Called(ctx, notification, fromAttempts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Notification, int) error); ok {
+		r0 = rf(ctx, notification, fromAttempts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
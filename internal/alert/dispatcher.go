@@ -0,0 +1,177 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	alertDB "kek-backend/internal/alert/database"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/config"
+	"kek-backend/internal/notify"
+	"kek-backend/pkg/logging"
+)
+
+// Dispatcher delivers due notifications in the outbox over notifiers of their actions.
+// A failed notification is retried with exponential backoff and dead-lettered after max attempts.
+// Notifications which are not critical are held in quiet hours and over the rate limit of the recipient.
+// Due notifications are claimed before they are sent, so that dispatchers of several instances do not send them twice
+type Dispatcher struct {
+	alertDB     alertDB.AlertDB
+	notifiers   *notify.Registry
+	batchSize   uint
+	maxAttempts int
+	backoff     time.Duration
+	lease       time.Duration
+}
+
+// Dispatch claims and delivers notifications due at now
+func (d *Dispatcher) Dispatch(ctx context.Context) error {
+	now := time.Now()
+	notifications, err := d.alertDB.ClaimDueNotifications(ctx, now, d.lease, d.batchSize)
+	if err != nil {
+		return err
+	}
+	for _, n := range notifications {
+		if d.hold(ctx, now, n) {
+			continue
+		}
+		d.dispatch(ctx, now, n)
+	}
+	return nil
+}
+
+// hold defers given notification without an attempt if it is not critical and the recipient is in quiet hours
+// or has been sent the maximum notifications in the last hour. It returns true if the notification is deferred
+func (d *Dispatcher) hold(ctx context.Context, now time.Time, n *model.Notification) bool {
+	logger := logging.FromContext(ctx)
+	if n.Critical {
+		return false
+	}
+	preferences := n.Account.Preferences
+	until, held := preferences.QuietUntil(now)
+	if !held && preferences.MaxPerHour > 0 {
+		sent, err := d.alertDB.CountSentNotifications(ctx, n.AccountId, now.Add(-time.Hour))
+		if err != nil {
+			logger.Errorw("alert.dispatcher failed to count sent notifications", "accountId", n.AccountId, "err", err)
+			return false
+		}
+		if sent >= int64(preferences.MaxPerHour) {
+			until, held = now.Add(time.Hour/time.Duration(preferences.MaxPerHour)), true
+		}
+	}
+	if !held {
+		return false
+	}
+
+	logger.Debugw("alert.dispatcher hold a notification", "id", n.ID, "accountId", n.AccountId, "until", until)
+	// the end of quiet hours is in the time zone of the recipient and stored as UTC
+	n.NextAttemptAt = until.UTC()
+	if err := d.alertDB.UpdateNotification(ctx, n, n.Attempts); err != nil {
+		logger.Errorw("alert.dispatcher failed to update notification", "id", n.ID, "err", err)
+	}
+	return true
+}
+
+// dispatch attempts to deliver given notification at given time, records the attempt
+// and moves the notification to the next status
+func (d *Dispatcher) dispatch(ctx context.Context, now time.Time, n *model.Notification) {
+	logger := logging.FromContext(ctx)
+	notifier, err := d.notifiers.Notifier(n.Action)
+	permanent := err != nil
+	if err == nil {
+		err = notifier.Notify(ctx, &n.Account, &notify.Message{
+			Title:         n.Title,
+			Body:          n.Body,
+			Slug:          n.Slug,
+			Condition:     n.Condition,
+			ObservedPrice: n.ObservedPrice,
+			TriggeredAt:   n.TriggeredAt,
+		})
+		// a forbidden webhook or a disabled channel fails the same way on every attempt
+		permanent = errors.Is(err, notify.ErrForbiddenWebhook) || errors.Is(err, notify.ErrPushDisabled)
+	}
+
+	attempt := model.NotificationAttempt{NotificationID: n.ID, AttemptedAt: now}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	if err := d.alertDB.SaveNotificationAttempt(ctx, &attempt); err != nil {
+		logger.Errorw("alert.dispatcher failed to save notification attempt", "id", n.ID, "err", err)
+	}
+
+	fromAttempts := n.Attempts
+	n.Attempts++
+	switch {
+	case err == nil:
+		n.Status, n.LastError = model.NotificationSent, ""
+	case err == notify.ErrNoRecipient:
+		n.Status, n.LastError = model.NotificationSkipped, err.Error()
+	case permanent || n.Attempts >= d.maxAttempts:
+		logger.Warnw("alert.dispatcher dead-letter a notification", "id", n.ID, "action", n.Action, "attempts", n.Attempts, "err", err)
+		n.Status, n.LastError = model.NotificationDead, err.Error()
+	default:
+		logger.Infow("alert.dispatcher failed to deliver a notification", "id", n.ID, "action", n.Action, "attempts", n.Attempts, "err", err)
+		n.LastError = err.Error()
+		n.NextAttemptAt = now.Add(d.backoff << (n.Attempts - 1))
+	}
+	if err := d.alertDB.UpdateNotification(ctx, n, fromAttempts); err != nil {
+		logger.Errorw("alert.dispatcher failed to update notification", "id", n.ID, "err", err)
+		return
+	}
+	if n.IsFinished() {
+		d.settle(ctx, n.AlertEventID)
+	}
+}
+
+// settle records the delivery outcome to an alert event with given id once all of its notifications are finished.
+// The event is failed if any notification is dead
+func (d *Dispatcher) settle(ctx context.Context, eventId uint) {
+	logger := logging.FromContext(ctx)
+	notifications, err := d.alertDB.FindNotificationsByEvent(ctx, eventId)
+	if err != nil {
+		logger.Errorw("alert.dispatcher failed to find notifications of event", "eventId", eventId, "err", err)
+		return
+	}
+	var errs []string
+	for _, n := range notifications {
+		if !n.IsFinished() {
+			return
+		}
+		if n.Status == model.NotificationDead {
+			errs = append(errs, n.Action+": "+n.LastError)
+		}
+	}
+	status, deliveryErr := model.DeliverySent, ""
+	if len(errs) > 0 {
+		status, deliveryErr = model.DeliveryFailed, strings.Join(errs, "; ")
+	}
+	if err := d.alertDB.UpdateAlertEventDelivery(ctx, eventId, status, deliveryErr); err != nil {
+		logger.Errorw("alert.dispatcher failed to update alert event delivery", "eventId", eventId, "err", err)
+	}
+}
+
+// NewDispatcher creates a new dispatcher with given config, alert db and notifiers
+func NewDispatcher(cfg *config.Config, alertDB alertDB.AlertDB, notifiers *notify.Registry) *Dispatcher {
+	outbox := cfg.NotifyConfig.Outbox
+	batchSize, maxAttempts := outbox.BatchSize, outbox.MaxAttempts
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	lease := time.Duration(outbox.LeaseSecs) * time.Second
+	if lease <= 0 {
+		lease = 5 * time.Minute
+	}
+	return &Dispatcher{
+		alertDB:     alertDB,
+		notifiers:   notifiers,
+		batchSize:   uint(batchSize),
+		maxAttempts: maxAttempts,
+		backoff:     time.Duration(outbox.BackoffSecs) * time.Second,
+		lease:       lease,
+	}
+}
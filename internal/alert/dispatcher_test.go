@@ -0,0 +1,273 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	accountModel "kek-backend/internal/account/model"
+	alertDBMock "kek-backend/internal/alert/database/mocks"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/config"
+	"kek-backend/internal/notify"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	// given
+	db := &alertDBMock.AlertDB{}
+	notifiers, fake := newFakeNotifiers()
+	dispatcher := NewDispatcher(&config.Config{}, db, notifiers)
+	n := newPendingNotification(1, notify.ActionPush, "owner")
+	db.On("ClaimDueNotifications", mock.Anything, mock.Anything, 5*time.Minute, uint(100)).Return([]*model.Notification{n}, nil)
+	db.On("SaveNotificationAttempt", mock.Anything, mock.Anything).Return(nil)
+	db.On("UpdateNotification", mock.Anything, mock.Anything, 0).Return(nil)
+	db.On("FindNotificationsByEvent", mock.Anything, uint(10)).Return([]*model.Notification{n}, nil)
+	db.On("UpdateAlertEventDelivery", mock.Anything, uint(10), model.DeliverySent, "").Return(nil)
+
+	// when
+	err := dispatcher.Dispatch(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	sent := fake.Sent()
+	assert.Len(t, sent, 1)
+	assert.Equal(t, "owner", sent[0].Account.Username)
+	assert.Equal(t, notify.Message{
+		Title:         n.Title,
+		Body:          n.Body,
+		Slug:          n.Slug,
+		Condition:     n.Condition,
+		ObservedPrice: n.ObservedPrice,
+		TriggeredAt:   n.TriggeredAt,
+	}, sent[0].Message)
+	db.AssertCalled(t, "SaveNotificationAttempt", mock.Anything, mock.MatchedBy(func(a *model.NotificationAttempt) bool {
+		return a.NotificationID == n.ID && a.Error == ""
+	}))
+	db.AssertCalled(t, "UpdateNotification", mock.Anything, mock.MatchedBy(func(n *model.Notification) bool {
+		return n.Status == model.NotificationSent && n.Attempts == 1
+	}), 0)
+	db.AssertCalled(t, "UpdateAlertEventDelivery", mock.Anything, uint(10), model.DeliverySent, "")
+}
+
+func TestDispatcher_Dispatch_Outcomes(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Action        string
+		NotifyErr     error
+		Attempts      int
+		Status        string
+		Attempted     bool
+		NextAttemptIn time.Duration
+		LastError     string
+	}{
+		{Name: "retry with backoff", Action: notify.ActionPush, NotifyErr: errors.New("timeout"), Attempts: 0,
+			Status: model.NotificationPending, NextAttemptIn: 30 * time.Second, LastError: "timeout"},
+		{Name: "backoff doubles", Action: notify.ActionPush, NotifyErr: errors.New("timeout"), Attempts: 2,
+			Status: model.NotificationPending, NextAttemptIn: 120 * time.Second, LastError: "timeout"},
+		{Name: "dead after max attempts", Action: notify.ActionPush, NotifyErr: errors.New("timeout"), Attempts: 4,
+			Status: model.NotificationDead, LastError: "timeout"},
+		{Name: "dead if unknown action", Action: "sms", Attempts: 0,
+			Status: model.NotificationDead, LastError: `unknown alert action "sms", must be one of push`},
+		{Name: "skipped if no recipient", Action: notify.ActionPush, NotifyErr: notify.ErrNoRecipient, Attempts: 0,
+			Status: model.NotificationSkipped, LastError: notify.ErrNoRecipient.Error()},
+		{Name: "dead if forbidden webhook", Action: notify.ActionPush, NotifyErr: fmt.Errorf("send webhook request: %w", notify.ErrForbiddenWebhook), Attempts: 0,
+			Status: model.NotificationDead, LastError: "send webhook request: forbidden webhook address"},
+		{Name: "dead if push disabled", Action: notify.ActionPush, NotifyErr: notify.ErrPushDisabled, Attempts: 0,
+			Status: model.NotificationDead, LastError: "push notifications are disabled, fcm.key is not configured"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// given
+			db := &alertDBMock.AlertDB{}
+			notifiers, fake := newFakeNotifiers()
+			if tc.NotifyErr != nil {
+				fake.Errs = map[string]error{"owner": tc.NotifyErr}
+			}
+			cfg := config.Config{NotifyConfig: config.NotifyConfig{Outbox: config.OutboxConfig{MaxAttempts: 5, BackoffSecs: 30}}}
+			dispatcher := NewDispatcher(&cfg, db, notifiers)
+			n := newPendingNotification(1, tc.Action, "owner")
+			n.Attempts = tc.Attempts
+			now := time.Now()
+			db.On("SaveNotificationAttempt", mock.Anything, mock.Anything).Return(nil)
+			db.On("UpdateNotification", mock.Anything, mock.Anything, tc.Attempts).Return(nil)
+			db.On("FindNotificationsByEvent", mock.Anything, uint(10)).Return([]*model.Notification{n}, nil)
+			db.On("UpdateAlertEventDelivery", mock.Anything, uint(10), mock.Anything, mock.Anything).Return(nil)
+
+			// when
+			dispatcher.dispatch(context.Background(), now, n)
+
+			// then
+			db.AssertCalled(t, "SaveNotificationAttempt", mock.Anything, mock.MatchedBy(func(a *model.NotificationAttempt) bool {
+				return a.NotificationID == n.ID && a.AttemptedAt.Equal(now) && a.Error == tc.LastError
+			}))
+			db.AssertCalled(t, "UpdateNotification", mock.Anything, mock.Anything, tc.Attempts)
+			assert.Equal(t, tc.Status, n.Status)
+			assert.Equal(t, tc.Attempts+1, n.Attempts)
+			assert.Equal(t, tc.LastError, n.LastError)
+			if tc.Status == model.NotificationPending {
+				assert.Equal(t, now.Add(tc.NextAttemptIn), n.NextAttemptAt)
+				db.AssertNotCalled(t, "FindNotificationsByEvent", mock.Anything, mock.Anything)
+			} else {
+				db.AssertCalled(t, "FindNotificationsByEvent", mock.Anything, uint(10))
+			}
+		})
+	}
+}
+
+func TestDispatcher_Settle(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Statuses      []string
+		Settled       bool
+		Status        string
+		DeliveryError string
+	}{
+		{Name: "sent", Statuses: []string{model.NotificationSent, model.NotificationSkipped}, Settled: true, Status: model.DeliverySent},
+		{Name: "failed", Statuses: []string{model.NotificationSent, model.NotificationDead}, Settled: true,
+			Status: model.DeliveryFailed, DeliveryError: "push: timeout"},
+		{Name: "pending", Statuses: []string{model.NotificationDead, model.NotificationPending}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// given
+			db := &alertDBMock.AlertDB{}
+			dispatcher := NewDispatcher(&config.Config{}, db, notify.NewRegistry())
+			var notifications []*model.Notification
+			for i, status := range tc.Statuses {
+				n := newPendingNotification(uint(i+1), notify.ActionPush, "owner")
+				n.Status = status
+				if status == model.NotificationDead {
+					n.LastError = "timeout"
+				}
+				notifications = append(notifications, n)
+			}
+			db.On("FindNotificationsByEvent", mock.Anything, uint(10)).Return(notifications, nil)
+			db.On("UpdateAlertEventDelivery", mock.Anything, uint(10), tc.Status, tc.DeliveryError).Return(nil)
+
+			// when
+			dispatcher.settle(context.Background(), 10)
+
+			// then
+			if tc.Settled {
+				db.AssertCalled(t, "UpdateAlertEventDelivery", mock.Anything, uint(10), tc.Status, tc.DeliveryError)
+			} else {
+				db.AssertNotCalled(t, "UpdateAlertEventDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Dispatch_Hold(t *testing.T) {
+	now := time.Now().UTC()
+	quietHours := fmt.Sprintf("%s-%s", now.Add(-time.Hour).Format("15:04"), now.Add(time.Hour).Format("15:04"))
+	cases := []struct {
+		Name        string
+		Preferences accountModel.Preferences
+		Critical    bool
+		Sent        int64
+		Held        bool
+	}{
+		{Name: "no preferences"},
+		{Name: "quiet hours", Preferences: accountModel.Preferences{QuietHours: quietHours}, Held: true},
+		{Name: "critical in quiet hours", Preferences: accountModel.Preferences{QuietHours: quietHours}, Critical: true},
+		{Name: "under rate limit", Preferences: accountModel.Preferences{MaxPerHour: 2}, Sent: 1},
+		{Name: "over rate limit", Preferences: accountModel.Preferences{MaxPerHour: 2}, Sent: 2, Held: true},
+		{Name: "critical over rate limit", Preferences: accountModel.Preferences{MaxPerHour: 2}, Critical: true, Sent: 2},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// given
+			db := &alertDBMock.AlertDB{}
+			notifiers, fake := newFakeNotifiers()
+			dispatcher := NewDispatcher(&config.Config{}, db, notifiers)
+			n := newPendingNotification(1, notify.ActionPush, "owner")
+			n.Critical = tc.Critical
+			n.Account.Preferences = tc.Preferences
+			db.On("ClaimDueNotifications", mock.Anything, mock.Anything, 5*time.Minute, uint(100)).Return([]*model.Notification{n}, nil)
+			db.On("CountSentNotifications", mock.Anything, uint(1), mock.Anything).Return(tc.Sent, nil)
+			db.On("SaveNotificationAttempt", mock.Anything, mock.Anything).Return(nil)
+			db.On("UpdateNotification", mock.Anything, mock.Anything, 0).Return(nil)
+			db.On("FindNotificationsByEvent", mock.Anything, uint(10)).Return([]*model.Notification{n}, nil)
+			db.On("UpdateAlertEventDelivery", mock.Anything, uint(10), model.DeliverySent, "").Return(nil)
+
+			// when
+			err := dispatcher.Dispatch(context.Background())
+
+			// then
+			assert.NoError(t, err)
+			if tc.Held {
+				assert.Empty(t, fake.Sent())
+				assert.Equal(t, model.NotificationPending, n.Status)
+				assert.Equal(t, 0, n.Attempts)
+				assert.True(t, n.NextAttemptAt.After(now))
+				db.AssertNotCalled(t, "SaveNotificationAttempt", mock.Anything, mock.Anything)
+				db.AssertCalled(t, "UpdateNotification", mock.Anything, n, 0)
+			} else {
+				assert.Len(t, fake.Sent(), 1)
+				assert.Equal(t, model.NotificationSent, n.Status)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Dispatch_HoldInTimezone(t *testing.T) {
+	// given
+	// quiet hours of a recipient in Asia/Seoul (UTC+9) ending an hour later
+	seoul, err := time.LoadLocation("Asia/Seoul")
+	assert.NoError(t, err)
+	now := time.Now().In(seoul)
+	end := now.Add(time.Hour).Truncate(time.Minute)
+	quietHours := fmt.Sprintf("%s-%s", now.Add(-time.Hour).Format("15:04"), end.Format("15:04"))
+	db := &alertDBMock.AlertDB{}
+	notifiers, fake := newFakeNotifiers()
+	dispatcher := NewDispatcher(&config.Config{}, db, notifiers)
+	n := newPendingNotification(1, notify.ActionPush, "owner")
+	n.Account.Preferences = accountModel.Preferences{Timezone: "Asia/Seoul", QuietHours: quietHours}
+	db.On("ClaimDueNotifications", mock.Anything, mock.Anything, 5*time.Minute, uint(100)).Return([]*model.Notification{n}, nil)
+	db.On("UpdateNotification", mock.Anything, mock.Anything, 0).Return(nil)
+
+	// when
+	err = dispatcher.Dispatch(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	assert.Empty(t, fake.Sent())
+	db.AssertCalled(t, "UpdateNotification", mock.Anything, mock.MatchedBy(func(n *model.Notification) bool {
+		// the wall clock of a TIMESTAMP column is the UTC instant of the end
+		return n.NextAttemptAt.Location() == time.UTC && n.NextAttemptAt.Equal(end)
+	}), 0)
+}
+
+// newPendingNotification returns a pending notification of alert event 10 to an account with given username
+func newPendingNotification(id uint, action, username string) *model.Notification {
+	return &model.Notification{
+		ID:            id,
+		AlertEventID:  10,
+		AlertID:       1,
+		Action:        action,
+		Title:         "WETH above 1500",
+		Body:          "body",
+		Slug:          "weth-above-1500",
+		Condition:     "price above 1500",
+		ObservedPrice: 1520.5,
+		TriggeredAt:   time.Now(),
+		Status:        model.NotificationPending,
+		Account:       accountModel.Account{ID: 1, Username: username},
+		AccountId:     1,
+	}
+}
+
+// newFakeNotifiers returns a registry of a fake push notifier
+func newFakeNotifiers() (*notify.Registry, *notify.Fake) {
+	fake := &notify.Fake{}
+	notifiers := notify.NewRegistry()
+	notifiers.Register(notify.ActionPush, fake)
+	return notifiers, fake
+}
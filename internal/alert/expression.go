@@ -0,0 +1,513 @@
+package alert
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An expression combines predicates on token metrics with AND, OR and parentheses such as
+//
+//	price(0xa0b8...eb48) < 1 AND liquidity_usd(0xa0b8...eb48) > 500000
+//	change(0xc02a...6cc2, 1h) <= -10 OR change(0xa0b8...eb48, 1h) <= -10
+//
+// AND binds tighter than OR. A predicate is a metric of a token compared with a number by
+// one of <, <=, > and >=. Metrics are
+//
+//	price(address)          USD price
+//	liquidity(address)      total liquidity in token units
+//	liquidity_usd(address)  total liquidity in USD
+//	change(address, window) USD price change in percent over one of windows
+const (
+	maxExpressionLength     = 1000
+	maxExpressionPredicates = 10
+)
+
+// metrics is supported metrics of expressions
+var metrics = map[string]bool{
+	"price":         true,
+	"liquidity":     true,
+	"liquidity_usd": true,
+	"change":        true,
+}
+
+var addressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// exprNode is a node of a parsed expression
+type exprNode interface {
+	// evaluate returns true if the node matches given market
+	evaluate(m Market) (bool, error)
+
+	// rearmed returns true if the node has moved back past the match by given margin
+	rearmed(m Market, margin float64) (bool, error)
+
+	// predicates returns all predicates of the node
+	predicates() []*predicateNode
+}
+
+// andNode matches if all children match.
+// A child which cannot be evaluated does not hide another child which does not match
+type andNode struct {
+	children []exprNode
+}
+
+func (n *andNode) evaluate(m Market) (bool, error) {
+	var firstErr error
+	for _, child := range n.children {
+		matched, err := child.evaluate(m)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	if firstErr != nil {
+		return false, firstErr
+	}
+	return true, nil
+}
+
+func (n *andNode) rearmed(m Market, margin float64) (bool, error) {
+	var (
+		firstErr  error
+		evaluated bool
+	)
+	for _, child := range n.children {
+		rearmed, err := child.rearmed(m, margin)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if rearmed {
+			return true, nil
+		}
+		evaluated = true
+	}
+	if !evaluated {
+		return false, firstErr
+	}
+	return false, nil
+}
+
+func (n *andNode) predicates() []*predicateNode {
+	var ret []*predicateNode
+	for _, child := range n.children {
+		ret = append(ret, child.predicates()...)
+	}
+	return ret
+}
+
+// orNode matches if any child matches.
+// Children which cannot be evaluated are skipped and an error is returned only if no child can be evaluated
+type orNode struct {
+	children []exprNode
+}
+
+func (n *orNode) evaluate(m Market) (bool, error) {
+	var (
+		firstErr  error
+		evaluated bool
+	)
+	for _, child := range n.children {
+		matched, err := child.evaluate(m)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if matched {
+			return true, nil
+		}
+		evaluated = true
+	}
+	if !evaluated {
+		return false, firstErr
+	}
+	return false, nil
+}
+
+func (n *orNode) rearmed(m Market, margin float64) (bool, error) {
+	var (
+		firstErr  error
+		evaluated bool
+	)
+	for _, child := range n.children {
+		rearmed, err := child.rearmed(m, margin)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if !rearmed {
+			return false, nil
+		}
+		evaluated = true
+	}
+	if !evaluated {
+		return false, firstErr
+	}
+	return true, nil
+}
+
+func (n *orNode) predicates() []*predicateNode {
+	var ret []*predicateNode
+	for _, child := range n.children {
+		ret = append(ret, child.predicates()...)
+	}
+	return ret
+}
+
+// predicateNode compares a metric of a token with a threshold
+type predicateNode struct {
+	metric    string
+	address   string
+	window    time.Duration
+	op        string
+	threshold float64
+}
+
+func (n *predicateNode) value(m Market) (float64, error) {
+	q, err := m.Quote(n.address)
+	if err != nil {
+		return 0, err
+	}
+	switch n.metric {
+	case "price":
+		return q.USDPrice(), nil
+	case "liquidity":
+		return q.TotalLiquidity, nil
+	case "liquidity_usd":
+		return q.LiquidityUSD(), nil
+	default:
+		baseline, err := m.Baseline(n.address, n.window)
+		if err != nil {
+			return 0, err
+		}
+		if baseline <= 0 {
+			return 0, ErrNoBaseline
+		}
+		return (q.USDPrice() - baseline) / baseline * 100, nil
+	}
+}
+
+func (n *predicateNode) evaluate(m Market) (bool, error) {
+	v, err := n.value(m)
+	if err != nil {
+		return false, err
+	}
+	switch n.op {
+	case "<":
+		return v < n.threshold, nil
+	case "<=":
+		return v <= n.threshold, nil
+	case ">":
+		return v > n.threshold, nil
+	default:
+		return v >= n.threshold, nil
+	}
+}
+
+func (n *predicateNode) rearmed(m Market, margin float64) (bool, error) {
+	v, err := n.value(m)
+	if err != nil {
+		return false, err
+	}
+	switch n.op {
+	case "<":
+		return v >= n.threshold+margin, nil
+	case "<=":
+		return v > n.threshold+margin, nil
+	case ">":
+		return v <= n.threshold-margin, nil
+	default:
+		return v < n.threshold-margin, nil
+	}
+}
+
+func (n *predicateNode) predicates() []*predicateNode {
+	return []*predicateNode{n}
+}
+
+// expressionCondition is a Condition of a parsed expression
+type expressionCondition struct {
+	root   exprNode
+	margin float64
+}
+
+func newExpressionCondition(expr string, margin float64) (*expressionCondition, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, &ConditionError{Field: "condition", Value: expr, Message: "condition is required for expression alerts"}
+	}
+	if len(expr) > maxExpressionLength {
+		return nil, &ConditionError{Field: "condition", Value: expr, Message: fmt.Sprintf("condition must be at most %d characters", maxExpressionLength)}
+	}
+	root, err := parseExpression(expr)
+	if err != nil {
+		return nil, &ConditionError{Field: "condition", Value: expr, Message: err.Error()}
+	}
+	if len(root.predicates()) > maxExpressionPredicates {
+		return nil, &ConditionError{Field: "condition", Value: expr, Message: fmt.Sprintf("condition must have at most %d predicates", maxExpressionPredicates)}
+	}
+	return &expressionCondition{root: root, margin: margin}, nil
+}
+
+func (c *expressionCondition) Evaluate(m Market) (bool, error) {
+	return c.root.evaluate(m)
+}
+
+func (c *expressionCondition) Rearmed(m Market) (bool, error) {
+	return c.root.rearmed(m, c.margin)
+}
+
+func (c *expressionCondition) Addresses() []string {
+	var ret []string
+	seen := make(map[string]bool)
+	for _, p := range c.root.predicates() {
+		if !seen[p.address] {
+			seen[p.address] = true
+			ret = append(ret, p.address)
+		}
+	}
+	return ret
+}
+
+// token kinds of expressions
+const (
+	tokenEOF = iota
+	tokenWord
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind int
+	text string
+	// pos is the 1-based character position of the token in the expression
+	pos int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of condition"
+	}
+	return fmt.Sprintf("%q at position %d", t.text, t.pos)
+}
+
+// tokenize splits an expression into tokens
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i + 1})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i + 1})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i + 1})
+			i++
+		case c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(expr) && expr[i+1] == '=' {
+				op += "="
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: i + 1})
+			i += len(op)
+		case isWordChar(c) || ((c == '-' || c == '+') && i+1 < len(expr) && isWordChar(expr[i+1])):
+			j := i + 1
+			for j < len(expr) && isWordChar(expr[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: expr[i:j], pos: i + 1})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", c, i+1)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(expr) + 1}), nil
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.'
+}
+
+// exprParser is a recursive descent parser of expressions
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+// parseExpression parses given expression to a tree of nodes
+func parseExpression(expr string) (exprNode, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := exprParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s", t)
+	}
+	return node, nil
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) expect(kind int, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("expected %s but got %s", what, t)
+	}
+	return t, nil
+}
+
+func (p *exprParser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+// parseOr parses and { OR and }
+func (p *exprParser) parseOr() (exprNode, error) {
+	node, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []exprNode{node}
+	for p.isKeyword("OR") {
+		p.next()
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, node)
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &orNode{children: children}, nil
+}
+
+// parseAnd parses primary { AND primary }
+func (p *exprParser) parseAnd() (exprNode, error) {
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	children := []exprNode{node}
+	for p.isKeyword("AND") {
+		p.next()
+		node, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, node)
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &andNode{children: children}, nil
+}
+
+// parsePrimary parses "(" or ")" or predicate
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	if p.peek().kind == tokenLParen {
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen, `")"`); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+	return p.parsePredicate()
+}
+
+// parsePredicate parses metric "(" address [ "," window ] ")" op number
+func (p *exprParser) parsePredicate() (exprNode, error) {
+	t, err := p.expect(tokenWord, "a metric")
+	if err != nil {
+		return nil, err
+	}
+	metric := strings.ToLower(t.text)
+	if !metrics[metric] {
+		return nil, fmt.Errorf("unknown metric %s, must be one of price, liquidity, liquidity_usd, change", t)
+	}
+	n := predicateNode{metric: metric}
+
+	if _, err := p.expect(tokenLParen, `"("`); err != nil {
+		return nil, err
+	}
+	t, err = p.expect(tokenWord, "a token address")
+	if err != nil {
+		return nil, err
+	}
+	if !addressPattern.MatchString(t.text) {
+		return nil, fmt.Errorf("invalid token address %s", t)
+	}
+	n.address = strings.ToLower(t.text)
+	if metric == "change" {
+		if _, err := p.expect(tokenComma, `","`); err != nil {
+			return nil, err
+		}
+		t, err = p.expect(tokenWord, "a window")
+		if err != nil {
+			return nil, err
+		}
+		window, ok := windows[t.text]
+		if !ok {
+			return nil, fmt.Errorf("unsupported window %s, must be one of 5m, 15m, 1h, 4h, 24h", t)
+		}
+		n.window = window
+	}
+	if _, err := p.expect(tokenRParen, `")"`); err != nil {
+		return nil, err
+	}
+
+	t, err = p.expect(tokenOp, "one of <, <=, >, >=")
+	if err != nil {
+		return nil, err
+	}
+	n.op = t.text
+	t, err = p.expect(tokenWord, "a number")
+	if err != nil {
+		return nil, err
+	}
+	threshold, err := strconv.ParseFloat(t.text, 64)
+	if err != nil || math.IsNaN(threshold) || math.IsInf(threshold, 0) {
+		return nil, fmt.Errorf("invalid number %s", t)
+	}
+	n.threshold = threshold
+	return &n, nil
+}
@@ -0,0 +1,179 @@
+package alert
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpressionCondition_Evaluate(t *testing.T) {
+	// weth is 2000 with 300000000 USD of liquidity and usdc is 1 with 300000000 USD of liquidity
+	cases := []struct {
+		Name     string
+		Expr     string
+		Expected bool
+	}{
+		{Name: "single predicate", Expr: "price(" + wethAddress + ") > 1500", Expected: true},
+		{Name: "and matches", Expr: "price(" + usdcAddress + ") < 1.01 AND liquidity_usd(" + usdcAddress + ") > 500000", Expected: true},
+		{Name: "and does not match", Expr: "price(" + usdcAddress + ") < 1 AND liquidity_usd(" + usdcAddress + ") > 500000", Expected: false},
+		{Name: "or matches", Expr: "change(" + wethAddress + ", 1h) <= -10 OR change(" + usdcAddress + ", 1h) <= -10", Expected: true},
+		{Name: "or does not match", Expr: "change(" + wethAddress + ", 1h) <= -25 OR price(" + usdcAddress + ") > 2", Expected: false},
+		{Name: "and binds tighter than or", Expr: "price(" + wethAddress + ") > 1 OR price(" + wethAddress + ") > 1 AND price(" + wethAddress + ") < 1", Expected: true},
+		{Name: "parentheses", Expr: "(price(" + wethAddress + ") > 1 OR price(" + wethAddress + ") > 1) AND price(" + wethAddress + ") < 1", Expected: false},
+		{Name: "lower case keywords", Expr: "liquidity(" + wethAddress + ") >= 150000 and price(" + wethAddress + ") <= 2000", Expected: true},
+		{Name: "mixed case address", Expr: "price(0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2) >= 2000", Expected: true},
+	}
+
+	m := testMarket{
+		Quotes:    cannedQuotes(t),
+		baselines: map[time.Duration]float64{time.Hour: 2500},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			alert := newConditionAlert("", TypeExpression, "", "")
+			alert.ConditionExpr = tc.Expr
+			cond, err := ParseCondition(alert)
+			assert.NoError(t, err)
+
+			matched, err := cond.Evaluate(&m)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expected, matched)
+		})
+	}
+}
+
+func TestExpressionCondition_Rearmed(t *testing.T) {
+	// weth is 2000
+	cases := []struct {
+		Name     string
+		Expr     string
+		Margin   float64
+		Expected bool
+	}{
+		{Name: "predicate rearms out of margin", Expr: "price(" + wethAddress + ") > 2100", Margin: 50, Expected: true},
+		{Name: "predicate does not rearm within margin", Expr: "price(" + wethAddress + ") > 2020", Margin: 50, Expected: false},
+		{Name: "and rearms if any rearms", Expr: "price(" + wethAddress + ") > 1000 AND price(" + wethAddress + ") < 1900", Margin: 50, Expected: true},
+		{Name: "or rearms if all rearm", Expr: "price(" + wethAddress + ") > 2100 OR price(" + wethAddress + ") < 1900", Margin: 50, Expected: true},
+		{Name: "or does not rearm if any does not", Expr: "price(" + wethAddress + ") > 2100 OR price(" + wethAddress + ") < 1980", Margin: 50, Expected: false},
+	}
+
+	m := testMarket{Quotes: cannedQuotes(t)}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			alert := newConditionAlert("", TypeExpression, "", "")
+			alert.ConditionExpr = tc.Expr
+			alert.RearmMargin = tc.Margin
+			cond, err := ParseCondition(alert)
+			assert.NoError(t, err)
+
+			rearmed, err := cond.Rearmed(&m)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expected, rearmed)
+		})
+	}
+}
+
+func TestExpressionCondition_Addresses(t *testing.T) {
+	alert := newConditionAlert("", TypeExpression, "", "")
+	alert.ConditionExpr = "price(0x" + strings.ToUpper(wethAddress[2:]) + ") > 1 AND (change(" + usdcAddress + ", 5m) > 1 OR liquidity(" + wethAddress + ") < 1)"
+	cond, err := ParseCondition(alert)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{wethAddress, usdcAddress}, cond.Addresses())
+}
+
+func TestExpressionCondition_FailIfNoQuote(t *testing.T) {
+	alert := newConditionAlert("", TypeExpression, "", "")
+	alert.ConditionExpr = "price(" + wethAddress + ") > 1 AND price(0x0000000000000000000000000000000000000000) > 1"
+	cond, err := ParseCondition(alert)
+	assert.NoError(t, err)
+
+	matched, err := cond.Evaluate(&testMarket{Quotes: cannedQuotes(t)})
+
+	assert.False(t, matched)
+	assert.Equal(t, ErrNoQuote, err)
+}
+
+func TestExpressionCondition_SkipChildrenWithoutQuote(t *testing.T) {
+	// weth is 2000 and the zero address has no quote
+	const unknown = "0x0000000000000000000000000000000000000000"
+	cases := []struct {
+		Name     string
+		Expr     string
+		Rearm    bool
+		Expected bool
+		Err      error
+	}{
+		{Name: "or matches", Expr: "price(" + unknown + ") > 1 OR price(" + wethAddress + ") > 1500", Expected: true},
+		{Name: "or does not match", Expr: "price(" + unknown + ") > 1 OR price(" + wethAddress + ") > 5000", Expected: false},
+		{Name: "or fails if no child is evaluated", Expr: "price(" + unknown + ") > 1 OR price(" + unknown + ") < 1", Err: ErrNoQuote},
+		{Name: "and does not match", Expr: "price(" + unknown + ") > 1 AND price(" + wethAddress + ") > 5000", Expected: false},
+		{Name: "and fails if other children match", Expr: "price(" + unknown + ") > 1 AND price(" + wethAddress + ") > 1500", Err: ErrNoQuote},
+		{Name: "or rearms", Expr: "price(" + unknown + ") > 1 OR price(" + wethAddress + ") > 2100", Rearm: true, Expected: true},
+		{Name: "or does not rearm", Expr: "price(" + unknown + ") > 1 OR price(" + wethAddress + ") > 2020", Rearm: true, Expected: false},
+		{Name: "or fails to rearm if no child is evaluated", Expr: "price(" + unknown + ") > 1 OR price(" + unknown + ") < 1", Rearm: true, Err: ErrNoQuote},
+		{Name: "and rearms", Expr: "price(" + unknown + ") > 1 AND price(" + wethAddress + ") < 1900", Rearm: true, Expected: true},
+	}
+
+	m := testMarket{Quotes: cannedQuotes(t)}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			alert := newConditionAlert("", TypeExpression, "", "")
+			alert.ConditionExpr = tc.Expr
+			alert.RearmMargin = 50
+			cond, err := ParseCondition(alert)
+			assert.NoError(t, err)
+
+			var result bool
+			if tc.Rearm {
+				result, err = cond.Rearmed(&m)
+			} else {
+				result, err = cond.Evaluate(&m)
+			}
+
+			assert.Equal(t, tc.Err, err)
+			assert.Equal(t, tc.Expected, result)
+		})
+	}
+}
+
+func TestParseCondition_FailIfInvalidExpression(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Expr    string
+		Message string
+	}{
+		{Name: "empty", Expr: " ", Message: "condition is required for expression alerts"},
+		{Name: "unknown metric", Expr: "volume(" + wethAddress + ") > 1", Message: `unknown metric "volume" at position 1, must be one of price, liquidity, liquidity_usd, change`},
+		{Name: "invalid address", Expr: "price(0x1) > 1", Message: `invalid token address "0x1" at position 7`},
+		{Name: "missing window", Expr: "change(" + wethAddress + ") > 1", Message: `expected "," but got ")" at position 50`},
+		{Name: "unsupported window", Expr: "change(" + wethAddress + ", 2h) > 1", Message: `unsupported window "2h" at position 52, must be one of 5m, 15m, 1h, 4h, 24h`},
+		{Name: "missing operator", Expr: "price(" + wethAddress + ") 1", Message: `expected one of <, <=, >, >= but got "1" at position 51`},
+		{Name: "invalid number", Expr: "price(" + wethAddress + ") > abc", Message: `invalid number "abc" at position 53`},
+		{Name: "unexpected character", Expr: "price(" + wethAddress + ") = 1", Message: `unexpected '=' at position 51`},
+		{Name: "dangling and", Expr: "price(" + wethAddress + ") > 1 AND", Message: "expected a metric but got end of condition"},
+		{Name: "unclosed parenthesis", Expr: "(price(" + wethAddress + ") > 1", Message: `expected ")" but got end of condition`},
+		{Name: "trailing token", Expr: "price(" + wethAddress + ") > 1 price", Message: `unexpected "price" at position 55`},
+		{Name: "too many predicates", Expr: strings.Repeat("price("+wethAddress+") > 1 OR ", 10) + "price(" + wethAddress + ") > 1", Message: "condition must have at most 10 predicates"},
+		{Name: "too long", Expr: strings.Repeat(" ", 1000) + "price(" + wethAddress + ") > 1", Message: "condition must be at most 1000 characters"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			alert := newConditionAlert("", TypeExpression, "", "")
+			alert.ConditionExpr = tc.Expr
+
+			cond, err := ParseCondition(alert)
+
+			assert.Nil(t, cond)
+			cErr, ok := err.(*ConditionError)
+			assert.True(t, ok)
+			assert.Equal(t, "condition", cErr.Field)
+			assert.Equal(t, tc.Message, cErr.Message)
+		})
+	}
+}
@@ -2,6 +2,10 @@ package alert
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"kek-backend/internal/account"
 	alertDB "kek-backend/internal/alert/database"
 	"kek-backend/internal/alert/model"
@@ -9,11 +13,14 @@ import (
 	"kek-backend/internal/database"
 	"kek-backend/internal/middleware"
 	"kek-backend/internal/middleware/handler"
+	"kek-backend/internal/notify"
 	"kek-backend/pkg/logging"
 	"kek-backend/pkg/validate"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
@@ -23,7 +30,8 @@ import (
 )
 
 type Handler struct {
-	alertDB alertDB.AlertDB
+	alertDB   alertDB.AlertDB
+	notifiers *notify.Registry
 }
 
 // saveAlert handles POST /v1/api/alerts
@@ -33,14 +41,19 @@ func (h *Handler) saveAlert(c *gin.Context) {
 		// bind
 		type RequestBody struct {
 			Alert struct {
-				Title          string    `json:"title" binding:"required,min=5"`
-				Body           string    `json:"body" binding:"required"`
-				PairAddress    string    `json:"pairAddress" binding:"required,min=20"`
-				AlertType      string    `json:"alertType" binding:"required,min=3"`
-				AlertValue     string    `json:"alertValue" binding:"required"`
-				AlertOption    string    `json:"alertOption" binding:"required"`
-				ExpirationTime time.Time `json:"expirationTime" binding:"required"`
-				AlertActions   string    `json:"alertActions" binding:"required"`
+				Title          string     `json:"title" binding:"required,min=5"`
+				Body           string     `json:"body" binding:"required"`
+				PairAddress    string     `json:"pairAddress" binding:"required_unless=AlertType expression,omitempty,min=20"`
+				AlertType      string     `json:"alertType" binding:"required,min=3"`
+				AlertValue     string     `json:"alertValue" binding:"required_unless=AlertType expression"`
+				AlertOption    string     `json:"alertOption" binding:"required_unless=AlertType expression"`
+				Condition      string     `json:"condition" binding:"required_if=AlertType expression"`
+				ExpirationTime time.Time  `json:"expirationTime" binding:"required"`
+				AlertActions   actionList `json:"alertActions"`
+				CooldownSecs   int64      `json:"cooldownSecs" binding:"min=0"`
+				RearmMargin    float64    `json:"rearmMargin"`
+				Visibility     string     `json:"visibility" binding:"omitempty,oneof=private public"`
+				Critical       bool       `json:"critical"`
 			} `json:"alert"`
 		}
 		var body RequestBody
@@ -53,32 +66,190 @@ func (h *Handler) saveAlert(c *gin.Context) {
 			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert request in body", details)
 		}
 
-		// save alert
+		// save alert. TIMESTAMP columns keep the wall clock of a time, so the expiration is stored as UTC
 		currentUser := account.MustCurrentUser(c)
 		alert := model.Alert{
-			Slug:           slug.Make(body.Alert.Title),
 			Title:          body.Alert.Title,
 			Body:           body.Alert.Body,
 			PairAddress:    body.Alert.PairAddress,
 			AlertType:      body.Alert.AlertType,
 			AlertValue:     body.Alert.AlertValue,
 			AlertOption:    body.Alert.AlertOption,
-			ExpirationTime: body.Alert.ExpirationTime,
-			AlertActions:   body.Alert.AlertActions,
-			AlertStatus:    "active",
+			ConditionExpr:  body.Alert.Condition,
+			ExpirationTime: body.Alert.ExpirationTime.UTC(),
+			AlertActions:   body.Alert.AlertActions.String(),
+			AlertStatus:    model.StatusActive,
+			CooldownSecs:   body.Alert.CooldownSecs,
+			RearmMargin:    body.Alert.RearmMargin,
+			Visibility:     body.Alert.Visibility,
+			Critical:       body.Alert.Critical,
 			AccountId:      currentUser.ID,
 		}
-		err := h.alertDB.SaveAlert(c.Request.Context(), &alert)
+		if alert.Visibility == "" {
+			alert.Visibility = model.VisibilityPrivate
+		}
+		if alert.AlertActions == "" {
+			alert.AlertActions = currentUser.Preferences.DefaultChannels
+		}
+		cond, err := ParseCondition(&alert)
 		if err != nil {
-			if database.IsKeyConflictErr(err) {
-				return handler.NewErrorResponse(http.StatusConflict, handler.DuplicateEntry, "duplicate alert title", nil)
+			logger.Errorw("alert.handler.register invalid condition", "err", err)
+			var details []*validate.ValidationErrDetail
+			if cErr, ok := err.(*ConditionError); ok {
+				details = validate.NewValidationErrorDetails(cErr.Field, cErr.Message, cErr.Value)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert request in body", details)
+		}
+		if details := h.validateActions(&alert); details != nil {
+			logger.Errorw("alert.handler.register invalid actions", "actions", alert.AlertActions)
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert request in body", details)
+		}
+		if details := validateTemplates(&alert); details != nil {
+			logger.Errorw("alert.handler.register invalid templates", "title", alert.Title, "body", alert.Body)
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert request in body", details)
+		}
+		if alert.PairAddress == "" {
+			// an expression alert is listed and priced by its first token
+			alert.PairAddress = cond.Addresses()[0]
+		}
+		// slugs are global, so a taken slug is suffixed instead of revealing alerts of other accounts
+		for attempt := 0; ; attempt++ {
+			alert.Slug = alertSlug(body.Alert.Title, attempt > 0)
+			err = h.alertDB.SaveAlert(c.Request.Context(), &alert)
+			if err == nil || !database.IsKeyConflictErr(err) || attempt+1 >= maxSlugAttempts {
+				break
 			}
+		}
+		if err != nil {
 			return handler.NewInternalErrorResponse(err)
 		}
+		alert.Account = *currentUser
 		return handler.NewSuccessResponse(http.StatusCreated, NewAlertResponse(&alert))
 	})
 }
 
+// updateAlert handles PUT /v1/api/alerts/:slug
+func (h *Handler) updateAlert(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		// bind
+		type RequestUri struct {
+			Slug string `uri:"slug" binding:"required"`
+		}
+		type RequestBody struct {
+			Alert struct {
+				Title          *string     `json:"title" binding:"omitempty,min=5"`
+				Body           *string     `json:"body" binding:"omitempty,min=1"`
+				AlertValue     *string     `json:"alertValue" binding:"omitempty,min=1"`
+				AlertOption    *string     `json:"alertOption" binding:"omitempty,min=1"`
+				Condition      *string     `json:"condition" binding:"omitempty,min=1"`
+				ExpirationTime *time.Time  `json:"expirationTime"`
+				AlertActions   *actionList `json:"alertActions"`
+				CooldownSecs   *int64      `json:"cooldownSecs" binding:"omitempty,min=0"`
+				RearmMargin    *float64    `json:"rearmMargin"`
+				Visibility     *string     `json:"visibility" binding:"omitempty,oneof=private public"`
+				Critical       *bool       `json:"critical"`
+			} `json:"alert"`
+		}
+		var (
+			uri  RequestUri
+			body RequestBody
+		)
+		if err := c.ShouldBindUri(&uri); err != nil {
+			logger.Errorw("alert.handler.updateAlert failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&uri, "uri", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid alert request in uri", details)
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			logger.Errorw("alert.handler.updateAlert failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&body.Alert, "json", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert request in body", details)
+		}
+
+		// find alert of current user
+		currentUser := account.MustCurrentUser(c)
+		alert, err := h.alertDB.FindAlertBySlug(c.Request.Context(), uri.Slug)
+		if err != nil {
+			if database.IsRecordNotFoundErr(err) {
+				return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found alert", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+		if alert.AccountId != currentUser.ID {
+			return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found alert", nil)
+		}
+
+		// apply changes
+		// the slug is kept on rename, so that links of inbox items and notifications stay valid
+		if body.Alert.Title != nil {
+			alert.Title = *body.Alert.Title
+		}
+		if body.Alert.Body != nil {
+			alert.Body = *body.Alert.Body
+		}
+		if body.Alert.AlertValue != nil {
+			alert.AlertValue = *body.Alert.AlertValue
+		}
+		if body.Alert.AlertOption != nil {
+			alert.AlertOption = *body.Alert.AlertOption
+		}
+		if body.Alert.Condition != nil {
+			alert.ConditionExpr = *body.Alert.Condition
+		}
+		if body.Alert.ExpirationTime != nil {
+			alert.ExpirationTime = body.Alert.ExpirationTime.UTC()
+		}
+		if body.Alert.AlertActions != nil {
+			alert.AlertActions = body.Alert.AlertActions.String()
+		}
+		if body.Alert.CooldownSecs != nil {
+			alert.CooldownSecs = *body.Alert.CooldownSecs
+		}
+		if body.Alert.RearmMargin != nil {
+			alert.RearmMargin = *body.Alert.RearmMargin
+		}
+		if body.Alert.Visibility != nil {
+			alert.Visibility = *body.Alert.Visibility
+		}
+		if body.Alert.Critical != nil {
+			alert.Critical = *body.Alert.Critical
+		}
+		if _, err := ParseCondition(alert); err != nil {
+			logger.Errorw("alert.handler.updateAlert invalid condition", "err", err)
+			var details []*validate.ValidationErrDetail
+			if cErr, ok := err.(*ConditionError); ok {
+				details = validate.NewValidationErrorDetails(cErr.Field, cErr.Message, cErr.Value)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert request in body", details)
+		}
+		if details := h.validateActions(alert); details != nil {
+			logger.Errorw("alert.handler.updateAlert invalid actions", "actions", alert.AlertActions)
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert request in body", details)
+		}
+		if details := validateTemplates(alert); details != nil {
+			logger.Errorw("alert.handler.updateAlert invalid templates", "title", alert.Title, "body", alert.Body)
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert request in body", details)
+		}
+
+		// update
+		err = h.alertDB.UpdateAlert(c.Request.Context(), currentUser.ID, alert)
+		if err != nil {
+			logger.Errorw("alert.handler.updateAlert failed to update an alert", "err", err)
+			if database.IsRecordNotFoundErr(err) {
+				return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found alert", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, NewAlertResponse(alert))
+	})
+}
+
 // alertBySlug handles GET /v1/api/alerts/:slug
 func (h *Handler) alertBySlug(c *gin.Context) {
 	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
@@ -98,6 +269,7 @@ func (h *Handler) alertBySlug(c *gin.Context) {
 		}
 
 		// find
+		currentUser := account.MustCurrentUser(c)
 		alert, err := h.alertDB.FindAlertBySlug(c.Request.Context(), uri.Slug)
 		if err != nil {
 			if database.IsRecordNotFoundErr(err) {
@@ -105,6 +277,9 @@ func (h *Handler) alertBySlug(c *gin.Context) {
 			}
 			return handler.NewInternalErrorResponse(err)
 		}
+		if !alert.IsVisibleTo(currentUser.ID) {
+			return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found alert", nil)
+		}
 		return handler.NewSuccessResponse(http.StatusOK, NewAlertResponse(alert))
 	})
 }
@@ -114,10 +289,10 @@ func (h *Handler) alerts(c *gin.Context) {
 	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
 		logger := logging.FromContext(c)
 		type QueryParameter struct {
-			Tag     []string `form:"tag" binding:"omitempty,dive,max=10"`
-			Account string   `form:"account" binding:"omitempty"`
-			Limit   string   `form:"limit,default=5" binding:"numeric"`
-			Offset  string   `form:"offset,default=0" binding:"numeric"`
+			Tag    []string `form:"tag" binding:"omitempty,dive,max=10"`
+			Author string   `form:"author" binding:"omitempty"`
+			Limit  string   `form:"limit,default=5" binding:"numeric"`
+			Offset string   `form:"offset,default=0" binding:"numeric"`
 		}
 		var query QueryParameter
 		if err := c.ShouldBindQuery(&query); err != nil {
@@ -129,7 +304,6 @@ func (h *Handler) alerts(c *gin.Context) {
 			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid alert request in query", details)
 		}
 
-		account, err := strconv.ParseUint(query.Account, 10, 64)
 		limit, err := strconv.ParseUint(query.Limit, 10, 64)
 		if err != nil {
 			limit = 5
@@ -138,11 +312,18 @@ func (h *Handler) alerts(c *gin.Context) {
 		if err != nil {
 			offset = 0
 		}
+		// own alerts by default, otherwise public alerts of the author with given username
+		currentUser := account.MustCurrentUser(c)
 		criteria := alertDB.IterateAlertCriteria{
-			Account: uint(account),
+			Account: currentUser.ID,
 			Offset:  uint(offset),
 			Limit:   uint(limit),
 		}
+		if query.Author != "" && query.Author != currentUser.Username {
+			criteria.Account = 0
+			criteria.Author = query.Author
+			criteria.Visibility = model.VisibilityPublic
+		}
 		alerts, total, err := h.alertDB.FindAlerts(c.Request.Context(), criteria)
 		if err != nil {
 			return handler.NewInternalErrorResponse(err)
@@ -191,30 +372,182 @@ func (h *Handler) deleteAlert(c *gin.Context) {
 	})
 }
 
+// pauseAlert handles POST /v1/api/alerts/:slug/pause
+func (h *Handler) pauseAlert(c *gin.Context) {
+	h.transitAlert(c, model.StatusPaused)
+}
+
+// resumeAlert handles POST /v1/api/alerts/:slug/resume
+func (h *Handler) resumeAlert(c *gin.Context) {
+	h.transitAlert(c, model.StatusActive)
+}
+
+// transitAlert moves an alert of current user with slug in uri to given status
+func (h *Handler) transitAlert(c *gin.Context, status string) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		// bind
+		type RequestUri struct {
+			Slug string `uri:"slug" binding:"required"`
+		}
+		var uri RequestUri
+		if err := c.ShouldBindUri(&uri); err != nil {
+			logger.Errorw("alert.handler.transitAlert failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&uri, "uri", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid alert request in uri", details)
+		}
+
+		// find
+		currentUser := account.MustCurrentUser(c)
+		alert, err := h.alertDB.FindAlertBySlug(c.Request.Context(), uri.Slug)
+		if err != nil {
+			if database.IsRecordNotFoundErr(err) {
+				return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found alert", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+		if alert.AccountId != currentUser.ID {
+			return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found alert", nil)
+		}
+
+		// transit
+		if status == model.StatusActive && alert.IsExpired(time.Now()) {
+			return handler.NewErrorResponse(http.StatusConflict, handler.InvalidStatusTransition, "alert already expired", nil)
+		}
+		if !alert.CanTransitionTo(status) {
+			message := fmt.Sprintf("cannot change alert status from %s to %s", alert.AlertStatus, status)
+			return handler.NewErrorResponse(http.StatusConflict, handler.InvalidStatusTransition, message, nil)
+		}
+		err = h.alertDB.UpdateAlertStatus(c.Request.Context(), alert.ID, alert.AlertStatus, status)
+		if err != nil {
+			logger.Errorw("alert.handler.transitAlert failed to update alert status", "err", err)
+			if database.IsRecordNotFoundErr(err) {
+				return handler.NewErrorResponse(http.StatusConflict, handler.InvalidStatusTransition, "alert status has been changed", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+		alert.AlertStatus = status
+		return handler.NewSuccessResponse(http.StatusOK, NewAlertResponse(alert))
+	})
+}
+
+const (
+	// maxSlugAttempts is the number of slugs tried to save an alert
+	maxSlugAttempts = 3
+	// maxSlugLength is the length of the slug column
+	maxSlugLength = 100
+)
+
+// alertSlug returns the slug of given title, suffixed with random characters if suffix is true
+func alertSlug(title string, suffix bool) string {
+	s := slug.Make(title)
+	if !suffix {
+		return truncateSlug(s, maxSlugLength)
+	}
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	suffixed := "-" + hex.EncodeToString(b)
+	return truncateSlug(s, maxSlugLength-len(suffixed)) + suffixed
+}
+
+// truncateSlug returns given slug cut to max length without a trailing dash
+func truncateSlug(s string, max int) string {
+	if len(s) > max {
+		s = strings.TrimRight(s[:max], "-")
+	}
+	return s
+}
+
+// actionList is alert actions in a request given as a JSON list like defaultChannels of preferences
+// or as a string separated by comma
+type actionList []string
+
+func (l *actionList) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*l = strings.Split(s, ",")
+		return nil
+	}
+	var actions []string
+	if err := json.Unmarshal(data, &actions); err != nil {
+		return errors.New("alertActions must be a list or a string of actions separated by comma")
+	}
+	*l = actions
+	return nil
+}
+
+// String returns the actions separated by comma as stored in model.Alert
+func (l actionList) String() string {
+	return strings.Join(l, ",")
+}
+
+// validateActions normalizes actions of given alert and returns validation error details
+// if it has no action or an action without notifier
+func (h *Handler) validateActions(alert *model.Alert) []*validate.ValidationErrDetail {
+	actions := alert.Actions()
+	if len(actions) == 0 {
+		return validate.NewValidationErrorDetails("alertActions", "at least one alert action is required", alert.AlertActions)
+	}
+	for _, action := range actions {
+		if _, err := h.notifiers.Notifier(action); err != nil {
+			return validate.NewValidationErrorDetails("alertActions", err.Error(), alert.AlertActions)
+		}
+	}
+	alert.AlertActions = strings.Join(actions, ",")
+	return nil
+}
+
+// validateTemplates returns validation error details if title or body of given alert is an invalid template
+// or the title is longer than MaxTitleLength
+func validateTemplates(alert *model.Alert) []*validate.ValidationErrDetail {
+	if utf8.RuneCountInString(alert.Title) > MaxTitleLength {
+		return validate.NewValidationErrorDetails("title", fmt.Sprintf("must be at most %d characters", MaxTitleLength), alert.Title)
+	}
+	if err := ValidateTemplate(alert.Title); err != nil {
+		return validate.NewValidationErrorDetails("title", err.Error(), alert.Title)
+	}
+	if err := ValidateTemplate(alert.Body); err != nil {
+		return validate.NewValidationErrorDetails("body", err.Error(), alert.Body)
+	}
+	return nil
+}
+
 func RouteV1(cfg *config.Config, h *Handler, r *gin.Engine, auth *jwt.GinJWTMiddleware) {
 	v1 := r.Group("v1/api")
 	timeout := time.Duration(cfg.ServerConfig.WriteTimeoutSecs) * time.Second
 	v1.Use(middleware.RequestIDMiddleware(), middleware.TimeoutMiddleware(timeout))
 
 	alertV1 := v1.Group("alerts")
-	// anonymous
-	alertV1.Use()
+	// auth required
+	alertV1.Use(auth.MiddlewareFunc())
 	{
 		alertV1.GET(":slug", h.alertBySlug)
 		alertV1.GET("", h.alerts)
+		alertV1.POST("", h.saveAlert)
+		alertV1.PUT(":slug", h.updateAlert)
+		alertV1.DELETE(":slug", h.deleteAlert)
+		alertV1.POST(":slug/pause", h.pauseAlert)
+		alertV1.POST(":slug/resume", h.resumeAlert)
+		alertV1.GET(":slug/events", h.alertEvents)
+		alertV1.POST(":slug/subscribe", h.subscribeAlert)
+		alertV1.DELETE(":slug/subscribe", h.unsubscribeAlert)
 	}
 
-	// auth required
-	alertV1.Use(auth.MiddlewareFunc())
+	adminV1 := v1.Group("admin/notifications")
+	// admin required
+	adminV1.Use(auth.MiddlewareFunc(), account.AdminMiddleware())
 	{
-		alertV1.POST("", h.saveAlert)
-		alertV1.DELETE(":slug", h.deleteAlert)
+		adminV1.GET("", h.notifications)
+		adminV1.POST(":id/replay", h.replayNotification)
 	}
 }
 
-func NewHandler(alertDB alertDB.AlertDB) *Handler {
-	StartCron(alertDB)
+func NewHandler(alertDB alertDB.AlertDB, notifiers *notify.Registry) *Handler {
 	return &Handler{
-		alertDB: alertDB,
+		alertDB:   alertDB,
+		notifiers: notifiers,
 	}
 }
@@ -0,0 +1,82 @@
+package alert
+
+import (
+	alertDB "kek-backend/internal/alert/database"
+	"kek-backend/internal/alert/model"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/tidwall/gjson"
+)
+
+var (
+	dAlertEvent = model.AlertEvent{
+		ID:             1,
+		AlertID:        dAlert.ID,
+		TriggeredAt:    time.Now(),
+		ObservedPrice:  1450.5,
+		Condition:      "price below 1500",
+		DeliveryStatus: model.DeliverySent,
+	}
+)
+
+func (s *HandlerSuite) TestAlertEvents() {
+	// given
+	alert := dAlert
+	alert.AccountId = dUser.ID
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+	criteria := alertDB.IterateAlertEventCriteria{AlertID: alert.ID, Offset: 10, Limit: 5}
+	s.db.On("FindAlertEvents", mock.Anything, criteria).Return([]*model.AlertEvent{&dAlertEvent}, int64(11), nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/alerts/"+alert.Slug+"/events?limit=5&offset=10", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "FindAlertEvents", mock.Anything, criteria)
+	s.Equal(http.StatusOK, res.Code)
+	result := gjson.Parse(res.Body.String())
+	s.Equal(int64(11), result.Get("eventsCount").Int())
+	events := result.Get("events").Array()
+	s.Len(events, 1)
+	s.Equal(dAlertEvent.ObservedPrice, events[0].Get("observedPrice").Float())
+	s.Equal(dAlertEvent.Condition, events[0].Get("condition").String())
+	s.Equal(dAlertEvent.DeliveryStatus, events[0].Get("deliveryStatus").String())
+	s.True(events[0].Get("triggeredAt").Exists())
+	s.False(events[0].Get("deliveryError").Exists())
+}
+
+func (s *HandlerSuite) TestAlertEvents_FailIfNotOwner() {
+	// given
+	alert := dAlert
+	alert.AccountId = dUser.ID + 1
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/alerts/"+alert.Slug+"/events", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "FindAlertEvents", mock.Anything, mock.Anything)
+	s.Equal(http.StatusNotFound, res.Code)
+}
+
+func (s *HandlerSuite) TestAlertEvents_FailIfAnonymous() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/alerts/"+dAlert.Slug+"/events", nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "FindAlertBySlug", mock.Anything, mock.Anything)
+	s.Equal(http.StatusUnauthorized, res.Code)
+}
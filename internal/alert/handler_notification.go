@@ -0,0 +1,110 @@
+package alert
+
+import (
+	"context"
+	alertDB "kek-backend/internal/alert/database"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	"kek-backend/internal/middleware/handler"
+	"kek-backend/pkg/logging"
+	"kek-backend/pkg/validate"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
+)
+
+// notifications handles GET /v1/api/admin/notifications
+func (h *Handler) notifications(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		// bind
+		type QueryParameter struct {
+			Status string `form:"status,default=dead" binding:"omitempty,oneof=pending sent skipped dead"`
+			Limit  string `form:"limit,default=20" binding:"numeric"`
+			Offset string `form:"offset,default=0" binding:"numeric"`
+		}
+		var query QueryParameter
+		if err := c.ShouldBindQuery(&query); err != nil {
+			logger.Errorw("alert.handler.notifications failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&query, "form", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidQueryValue, "invalid notification request in query", details)
+		}
+		limit, err := strconv.ParseUint(query.Limit, 10, 64)
+		if err != nil {
+			limit = 20
+		}
+		offset, err := strconv.ParseUint(query.Offset, 10, 64)
+		if err != nil {
+			offset = 0
+		}
+
+		// find notifications
+		criteria := alertDB.IterateNotificationCriteria{
+			Status: query.Status,
+			Offset: uint(offset),
+			Limit:  uint(limit),
+		}
+		notifications, total, err := h.alertDB.FindNotifications(c.Request.Context(), criteria)
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, NewNotificationsResponse(notifications, total))
+	})
+}
+
+// replayNotification handles POST /v1/api/admin/notifications/:id/replay
+func (h *Handler) replayNotification(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		// bind
+		type RequestUri struct {
+			ID uint `uri:"id" binding:"required"`
+		}
+		var uri RequestUri
+		if err := c.ShouldBindUri(&uri); err != nil {
+			logger.Errorw("alert.handler.replayNotification failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&uri, "uri", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid notification request in uri", details)
+		}
+
+		// find
+		notification, err := h.alertDB.FindNotification(c.Request.Context(), uri.ID)
+		if err != nil {
+			if database.IsRecordNotFoundErr(err) {
+				return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found notification", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+		if notification.Status != model.NotificationDead {
+			return handler.NewErrorResponse(http.StatusConflict, handler.InvalidStatusTransition, "only dead notifications can be replayed", nil)
+		}
+
+		// move back to pending with the alert event in transaction
+		now := time.Now()
+		err = h.alertDB.RunInTx(c.Request.Context(), func(ctx context.Context) error {
+			if err := h.alertDB.ReplayNotification(ctx, notification.ID, now); err != nil {
+				return err
+			}
+			return h.alertDB.UpdateAlertEventDelivery(ctx, notification.AlertEventID, model.DeliveryPending, "")
+		})
+		if err != nil {
+			logger.Errorw("alert.handler.replayNotification failed to replay a notification", "err", err)
+			if database.IsRecordNotFoundErr(errors.Cause(err)) {
+				return handler.NewErrorResponse(http.StatusConflict, handler.InvalidStatusTransition, "notification status has been changed", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+		notification.Status, notification.Attempts, notification.NextAttemptAt = model.NotificationPending, 0, now
+		return handler.NewSuccessResponse(http.StatusOK, NewNotificationResponse(notification))
+	})
+}
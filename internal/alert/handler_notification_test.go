@@ -0,0 +1,167 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	accountModel "kek-backend/internal/account/model"
+	alertDB "kek-backend/internal/alert/database"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/tidwall/gjson"
+)
+
+var dAdmin = accountModel.Account{
+	ID:       100,
+	Username: "admin",
+	Email:    "admin@gmail.com",
+	Password: dUser.Password,
+	IsAdmin:  true,
+}
+
+func (s *HandlerSuite) TestNotifications() {
+	// given
+	n := dNotification(1)
+	s.db.On("FindNotifications", mock.Anything, mock.Anything).Return([]*model.Notification{n}, int64(1), nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/admin/notifications?limit=5", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getAdminBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "FindNotifications", mock.Anything, alertDB.IterateNotificationCriteria{
+		Status: model.NotificationDead,
+		Offset: 0,
+		Limit:  5,
+	})
+	s.Equal(http.StatusOK, res.Code)
+	jsonVal := gjson.Parse(res.Body.String())
+	s.Equal(int64(1), jsonVal.Get("notificationsCount").Int())
+	s.Len(jsonVal.Get("notifications").Array(), 1)
+	notification := jsonVal.Get("notifications.0")
+	s.Equal(n.ID, uint(notification.Get("id").Uint()))
+	s.Equal(n.Slug, notification.Get("slug").String())
+	s.Equal(n.Action, notification.Get("action").String())
+	s.Equal(n.Account.Username, notification.Get("recipient.username").String())
+	s.Equal(n.Status, notification.Get("status").String())
+	s.Equal(int64(n.Attempts), notification.Get("attempts").Int())
+	s.Equal(n.LastError, notification.Get("lastError").String())
+}
+
+func (s *HandlerSuite) TestNotifications_FailIfInvalidStatus() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/admin/notifications?status=unknown", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getAdminBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "FindNotifications", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+}
+
+func (s *HandlerSuite) TestNotifications_FailIfNotAdmin() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/admin/notifications", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "FindNotifications", mock.Anything, mock.Anything)
+	s.Equal(http.StatusForbidden, res.Code)
+}
+
+func (s *HandlerSuite) TestReplayNotification() {
+	// given
+	n := dNotification(1)
+	s.db.On("FindNotification", mock.Anything, n.ID).Return(n, nil)
+	s.db.On("RunInTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, f func(ctx context.Context) error) error {
+		return f(ctx)
+	})
+	s.db.On("ReplayNotification", mock.Anything, n.ID, mock.Anything).Return(nil)
+	s.db.On("UpdateAlertEventDelivery", mock.Anything, n.AlertEventID, model.DeliveryPending, "").Return(nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/v1/api/admin/notifications/%d/replay", n.ID), nil)
+	req.Header.Add("Authorization", "Bearer "+s.getAdminBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "ReplayNotification", mock.Anything, n.ID, mock.Anything)
+	s.db.AssertCalled(s.T(), "UpdateAlertEventDelivery", mock.Anything, n.AlertEventID, model.DeliveryPending, "")
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal(model.NotificationPending, gjson.Get(res.Body.String(), "notification.status").String())
+	s.Equal(int64(0), gjson.Get(res.Body.String(), "notification.attempts").Int())
+}
+
+func (s *HandlerSuite) TestReplayNotification_FailIfNotDead() {
+	// given
+	n := dNotification(1)
+	n.Status = model.NotificationSent
+	s.db.On("FindNotification", mock.Anything, n.ID).Return(n, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/v1/api/admin/notifications/%d/replay", n.ID), nil)
+	req.Header.Add("Authorization", "Bearer "+s.getAdminBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "ReplayNotification", mock.Anything, mock.Anything, mock.Anything)
+	s.Equal(http.StatusConflict, res.Code)
+}
+
+func (s *HandlerSuite) TestReplayNotification_FailIfNotExist() {
+	// given
+	s.db.On("FindNotification", mock.Anything, uint(1)).Return(nil, database.ErrNotFound)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/admin/notifications/1/replay", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getAdminBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusNotFound, res.Code)
+}
+
+func (s *HandlerSuite) getAdminBearerToken() string {
+	s.accountDB.On("FindByEmail", mock.Anything, dAdmin.Email).Return(&dAdmin, nil)
+	return s.login(dAdmin.Email)
+}
+
+// dNotification returns a dead notification of dAlert to dUser
+func dNotification(id uint) *model.Notification {
+	return &model.Notification{
+		ID:            id,
+		AlertEventID:  10,
+		AlertID:       dAlert.ID,
+		Action:        "push",
+		Title:         dAlert.Title,
+		Body:          dAlert.Body,
+		Slug:          dAlert.Slug,
+		Condition:     "price below 1500",
+		ObservedPrice: 1499.5,
+		TriggeredAt:   time.Now(),
+		Status:        model.NotificationDead,
+		Attempts:      5,
+		NextAttemptAt: time.Now(),
+		LastError:     "timeout",
+		Account:       dUser,
+		AccountId:     dUser.ID,
+	}
+}
@@ -0,0 +1,99 @@
+package alert
+
+import (
+	"kek-backend/internal/account"
+	"kek-backend/internal/database"
+	"kek-backend/internal/middleware/handler"
+	"kek-backend/pkg/logging"
+	"kek-backend/pkg/validate"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// subscribeAlert handles POST /v1/api/alerts/:slug/subscribe
+func (h *Handler) subscribeAlert(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		// bind
+		type RequestUri struct {
+			Slug string `uri:"slug" binding:"required"`
+		}
+		var uri RequestUri
+		if err := c.ShouldBindUri(&uri); err != nil {
+			logger.Errorw("alert.handler.subscribeAlert failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&uri, "uri", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid alert subscription request in uri", details)
+		}
+
+		// find public alert
+		currentUser := account.MustCurrentUser(c)
+		alert, err := h.alertDB.FindAlertBySlug(c.Request.Context(), uri.Slug)
+		if err != nil {
+			if database.IsRecordNotFoundErr(err) {
+				return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found alert", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+		if !alert.IsVisibleTo(currentUser.ID) {
+			return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found alert", nil)
+		}
+		if alert.AccountId == currentUser.ID {
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "cannot subscribe to own alert", nil)
+		}
+
+		// subscribe
+		if err := h.alertDB.SaveSubscription(c.Request.Context(), alert.ID, currentUser.ID); err != nil {
+			logger.Errorw("alert.handler.subscribeAlert failed to save subscription", "err", err)
+			if database.IsKeyConflictErr(err) {
+				return handler.NewErrorResponse(http.StatusConflict, handler.DuplicateEntry, "already subscribed alert", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, NewAlertResponse(alert))
+	})
+}
+
+// unsubscribeAlert handles DELETE /v1/api/alerts/:slug/subscribe
+func (h *Handler) unsubscribeAlert(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		// bind
+		type RequestUri struct {
+			Slug string `uri:"slug" binding:"required"`
+		}
+		var uri RequestUri
+		if err := c.ShouldBindUri(&uri); err != nil {
+			logger.Errorw("alert.handler.unsubscribeAlert failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&uri, "uri", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid alert subscription request in uri", details)
+		}
+
+		// find alert
+		currentUser := account.MustCurrentUser(c)
+		alert, err := h.alertDB.FindAlertBySlug(c.Request.Context(), uri.Slug)
+		if err != nil {
+			if database.IsRecordNotFoundErr(err) {
+				return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found alert", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+
+		// unsubscribe even if the alert became private
+		if err := h.alertDB.DeleteSubscription(c.Request.Context(), alert.ID, currentUser.ID); err != nil {
+			logger.Errorw("alert.handler.unsubscribeAlert failed to delete subscription", "err", err)
+			if database.IsRecordNotFoundErr(err) {
+				return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found alert subscription", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, nil)
+	})
+}
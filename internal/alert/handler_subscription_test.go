@@ -0,0 +1,134 @@
+package alert
+
+import (
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/tidwall/gjson"
+)
+
+func (s *HandlerSuite) TestSubscribeAlert() {
+	// given
+	alert := dAlert
+	alert.AccountId, alert.Visibility = dUser.ID+1, model.VisibilityPublic
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+	s.db.On("SaveSubscription", mock.Anything, alert.ID, dUser.ID).Return(nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+alert.Slug+"/subscribe", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "SaveSubscription", mock.Anything, alert.ID, dUser.ID)
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal(alert.Slug, gjson.Get(res.Body.String(), "alert.slug").String())
+}
+
+func (s *HandlerSuite) TestSubscribeAlert_FailIfPrivate() {
+	// given
+	alert := dAlert
+	alert.AccountId, alert.Visibility = dUser.ID+1, model.VisibilityPrivate
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+alert.Slug+"/subscribe", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "SaveSubscription", mock.Anything, mock.Anything, mock.Anything)
+	s.Equal(http.StatusNotFound, res.Code)
+}
+
+func (s *HandlerSuite) TestSubscribeAlert_FailIfOwner() {
+	// given
+	alert := dAlert
+	alert.AccountId, alert.Visibility = dUser.ID, model.VisibilityPublic
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+alert.Slug+"/subscribe", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "SaveSubscription", mock.Anything, mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+}
+
+func (s *HandlerSuite) TestSubscribeAlert_FailIfAlreadySubscribed() {
+	// given
+	alert := dAlert
+	alert.AccountId, alert.Visibility = dUser.ID+1, model.VisibilityPublic
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+	s.db.On("SaveSubscription", mock.Anything, alert.ID, dUser.ID).Return(database.ErrKeyConflict)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+alert.Slug+"/subscribe", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusConflict, res.Code)
+}
+
+func (s *HandlerSuite) TestSubscribeAlert_FailIfAnonymous() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+dAlert.Slug+"/subscribe", nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "FindAlertBySlug", mock.Anything, mock.Anything)
+	s.Equal(http.StatusUnauthorized, res.Code)
+}
+
+func (s *HandlerSuite) TestUnsubscribeAlert() {
+	// given
+	alert := dAlert
+	alert.AccountId, alert.Visibility = dUser.ID+1, model.VisibilityPublic
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+	s.db.On("DeleteSubscription", mock.Anything, alert.ID, dUser.ID).Return(nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/v1/api/alerts/"+alert.Slug+"/subscribe", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "DeleteSubscription", mock.Anything, alert.ID, dUser.ID)
+	s.Equal(http.StatusOK, res.Code)
+}
+
+func (s *HandlerSuite) TestUnsubscribeAlert_FailIfNotSubscribed() {
+	// given
+	alert := dAlert
+	alert.AccountId, alert.Visibility = dUser.ID+1, model.VisibilityPublic
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+	s.db.On("DeleteSubscription", mock.Anything, alert.ID, dUser.ID).Return(database.ErrNotFound)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/v1/api/alerts/"+alert.Slug+"/subscribe", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusNotFound, res.Code)
+}
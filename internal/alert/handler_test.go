@@ -7,18 +7,22 @@ import (
 	"kek-backend/internal/account"
 	accountDBMock "kek-backend/internal/account/database/mocks"
 	accountModel "kek-backend/internal/account/model"
-	"kek-backend/internal/alert/database"
+	alertDB "kek-backend/internal/alert/database"
 	alertDBMock "kek-backend/internal/alert/database/mocks"
 	"kek-backend/internal/alert/model"
 	"kek-backend/internal/config"
+	"kek-backend/internal/database"
+	"kek-backend/internal/middleware/handler"
 	"kek-backend/pkg/logging"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gosimple/slug"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"github.com/tidwall/gjson"
@@ -36,12 +40,18 @@ var (
 	dUserRawPass = "user1"
 
 	dAlert = model.Alert{
-		ID:        1,
-		Slug:      "how-to-train-your-dragon",
-		Title:     "How to train your dragon",
-		Body:      "You have to believe",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:             1,
+		Slug:           "how-to-train-your-dragon",
+		Title:          "How to train your dragon",
+		Body:           "You have to believe",
+		PairAddress:    "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2",
+		AlertType:      TypePrice,
+		AlertValue:     "1500",
+		AlertOption:    OptionBelow,
+		ExpirationTime: time.Now().Add(24 * time.Hour),
+		AlertActions:   "push",
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 )
 
@@ -62,7 +72,8 @@ func (s *HandlerSuite) SetupTest() {
 	s.NoError(err)
 
 	s.db = &alertDBMock.AlertDB{}
-	s.handler = NewHandler(s.db)
+	notifiers, _ := newFakeNotifiers()
+	s.handler = NewHandler(s.db, notifiers)
 	s.accountDB = &accountDBMock.AccountDB{}
 	s.accountDB.On("FindByEmail", mock.Anything, mock.MatchedBy(func(email string) bool {
 		return email == dUser.Email
@@ -76,7 +87,7 @@ func (s *HandlerSuite) SetupTest() {
 
 	RouteV1(cfg, s.handler, s.r, jwtMiddleware)
 
-	accountHandler := account.NewHandler(s.accountDB)
+	accountHandler := account.NewHandler(s.accountDB, notifiers)
 	account.RouteV1(cfg, accountHandler, s.r, jwtMiddleware)
 }
 
@@ -93,8 +104,14 @@ func (s *HandlerSuite) TestSaveAlert() {
 	// when
 	requestBody := map[string]interface{}{
 		"alert": map[string]interface{}{
-			"title": dAlert.Title,
-			"body":  dAlert.Body,
+			"title":          dAlert.Title,
+			"body":           dAlert.Body,
+			"pairAddress":    dAlert.PairAddress,
+			"alertType":      dAlert.AlertType,
+			"alertValue":     dAlert.AlertValue,
+			"alertOption":    dAlert.AlertOption,
+			"expirationTime": dAlert.ExpirationTime,
+			"alertActions":   dAlert.AlertActions,
 		},
 	}
 	b, _ := json.Marshal(&requestBody)
@@ -112,43 +129,707 @@ func (s *HandlerSuite) TestSaveAlert() {
 	s.Equal(http.StatusCreated, res.Code)
 	// 3) response
 	jsonVal := res.Body.String()
-	s.assertAlertResponse(&dAlert, gjson.Parse(jsonVal).Get("alert"))
+	expected := dAlert
+	expected.Account = dUser
+	s.assertAlertResponse(&expected, gjson.Parse(jsonVal).Get("alert"))
+	s.Equal(model.VisibilityPrivate, gjson.Parse(jsonVal).Get("alert.visibility").String())
+}
+
+func (s *HandlerSuite) TestSaveAlert_SuffixTakenSlug() {
+	// given
+	taken := slug.Make(dAlert.Title)
+	s.db.On("SaveAlert", mock.Anything, mock.MatchedBy(func(a *model.Alert) bool {
+		return a.Slug == taken
+	})).Return(database.ErrKeyConflict).Once()
+	s.db.On("SaveAlert", mock.Anything, mock.MatchedBy(func(a *model.Alert) bool {
+		return strings.HasPrefix(a.Slug, taken+"-") && len(a.Slug) == len(taken)+7
+	})).Return(nil).Once()
+
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":          dAlert.Title,
+			"body":           dAlert.Body,
+			"pairAddress":    dAlert.PairAddress,
+			"alertType":      dAlert.AlertType,
+			"alertValue":     dAlert.AlertValue,
+			"alertOption":    dAlert.AlertOption,
+			"expirationTime": dAlert.ExpirationTime,
+			"alertActions":   dAlert.AlertActions,
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusCreated, res.Code)
+	s.db.AssertNumberOfCalls(s.T(), "SaveAlert", 2)
+	s.NotEqual(taken, gjson.Get(res.Body.String(), "alert.slug").String())
+}
+
+func TestAlertSlug(t *testing.T) {
+	assert.Equal(t, "weth-above-1500", alertSlug("WETH above 1500", false))
+	assert.Regexp(t, `^weth-above-1500-[0-9a-f]{6}$`, alertSlug("WETH above 1500", true))
+	long := strings.Repeat("a", 120)
+	assert.Len(t, alertSlug(long, false), maxSlugLength)
+	assert.Len(t, alertSlug(long, true), maxSlugLength)
+}
+
+func (s *HandlerSuite) TestSaveAlert_FailIfInvalidVisibility() {
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":          dAlert.Title,
+			"body":           dAlert.Body,
+			"pairAddress":    dAlert.PairAddress,
+			"alertType":      dAlert.AlertType,
+			"alertValue":     dAlert.AlertValue,
+			"alertOption":    dAlert.AlertOption,
+			"expirationTime": dAlert.ExpirationTime,
+			"alertActions":   dAlert.AlertActions,
+			"visibility":     "friends",
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "SaveAlert", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.Equal("visibility", gjson.Get(res.Body.String(), "errors.0.field").String())
+}
+
+func (s *HandlerSuite) TestSaveAlert_FailIfInvalidTemplate() {
+	cases := []struct {
+		Title string
+		Body  string
+		Field string
+	}{
+		{Title: "{{symbol}} above {{volume}}", Body: dAlert.Body, Field: "title"},
+		{Title: "{{symbol}} above {{threshold}}", Body: "{{symbol is {{price}}", Field: "body"},
+		{Title: strings.Repeat("{{pair}}", 13), Body: dAlert.Body, Field: "title"},
+	}
+
+	for _, tc := range cases {
+		// when
+		requestBody := map[string]interface{}{
+			"alert": map[string]interface{}{
+				"title":          tc.Title,
+				"body":           tc.Body,
+				"pairAddress":    dAlert.PairAddress,
+				"alertType":      dAlert.AlertType,
+				"alertValue":     dAlert.AlertValue,
+				"alertOption":    dAlert.AlertOption,
+				"expirationTime": dAlert.ExpirationTime,
+				"alertActions":   dAlert.AlertActions,
+			},
+		}
+		b, _ := json.Marshal(&requestBody)
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+		req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+		s.r.ServeHTTP(res, req)
+
+		// then
+		s.db.AssertNotCalled(s.T(), "SaveAlert", mock.Anything, mock.Anything)
+		s.Equal(http.StatusBadRequest, res.Code)
+		s.Equal(tc.Field, gjson.Get(res.Body.String(), "errors.0.field").String())
+	}
+}
+
+func (s *HandlerSuite) TestSaveAlert_NormalizeActions() {
+	// given
+	s.db.On("SaveAlert", mock.Anything, mock.Anything).Return(nil)
+
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":          dAlert.Title,
+			"body":           dAlert.Body,
+			"pairAddress":    dAlert.PairAddress,
+			"alertType":      dAlert.AlertType,
+			"alertValue":     dAlert.AlertValue,
+			"alertOption":    dAlert.AlertOption,
+			"expirationTime": dAlert.ExpirationTime,
+			"alertActions":   " Push, push ,",
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusCreated, res.Code)
+	s.db.AssertCalled(s.T(), "SaveAlert", mock.Anything, mock.MatchedBy(func(alert *model.Alert) bool {
+		return alert.AlertActions == "push"
+	}))
+}
+
+func (s *HandlerSuite) TestSaveAlert_ActionList() {
+	// given
+	s.db.On("SaveAlert", mock.Anything, mock.Anything).Return(nil)
+
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":          dAlert.Title,
+			"body":           dAlert.Body,
+			"pairAddress":    dAlert.PairAddress,
+			"alertType":      dAlert.AlertType,
+			"alertValue":     dAlert.AlertValue,
+			"alertOption":    dAlert.AlertOption,
+			"expirationTime": dAlert.ExpirationTime,
+			"alertActions":   []string{"Push", "push"},
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusCreated, res.Code)
+	s.db.AssertCalled(s.T(), "SaveAlert", mock.Anything, mock.MatchedBy(func(alert *model.Alert) bool {
+		return alert.AlertActions == "push"
+	}))
+}
+
+func (s *HandlerSuite) TestSaveAlert_DefaultChannels() {
+	cases := []struct {
+		DefaultChannels string
+		Status          int
+	}{
+		{DefaultChannels: "push", Status: http.StatusCreated},
+		{DefaultChannels: "", Status: http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		// given
+		s.SetupTest()
+		user := dUser
+		user.ID, user.Email = 2, "user2@gmail.com"
+		user.Preferences.DefaultChannels = tc.DefaultChannels
+		s.accountDB.On("FindByEmail", mock.Anything, user.Email).Return(&user, nil)
+		s.db.On("SaveAlert", mock.Anything, mock.Anything).Return(nil)
+
+		// when
+		requestBody := map[string]interface{}{
+			"alert": map[string]interface{}{
+				"title":          dAlert.Title,
+				"body":           dAlert.Body,
+				"pairAddress":    dAlert.PairAddress,
+				"alertType":      dAlert.AlertType,
+				"alertValue":     dAlert.AlertValue,
+				"alertOption":    dAlert.AlertOption,
+				"expirationTime": dAlert.ExpirationTime,
+				"critical":       true,
+			},
+		}
+		b, _ := json.Marshal(&requestBody)
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+		req.Header.Add("Authorization", "Bearer "+s.login(user.Email))
+
+		s.r.ServeHTTP(res, req)
+
+		// then
+		s.Equal(tc.Status, res.Code)
+		if tc.Status != http.StatusCreated {
+			s.Equal("alertActions", gjson.Get(res.Body.String(), "errors.0.field").String())
+			continue
+		}
+		s.Equal(tc.DefaultChannels, gjson.Get(res.Body.String(), "alert.alertActions").String())
+		s.True(gjson.Get(res.Body.String(), "alert.critical").Bool())
+		s.db.AssertCalled(s.T(), "SaveAlert", mock.Anything, mock.MatchedBy(func(alert *model.Alert) bool {
+			return alert.AlertActions == tc.DefaultChannels && alert.Critical
+		}))
+	}
+}
+
+func (s *HandlerSuite) TestSaveAlert_FailIfUnknownAction() {
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":          dAlert.Title,
+			"body":           dAlert.Body,
+			"pairAddress":    dAlert.PairAddress,
+			"alertType":      dAlert.AlertType,
+			"alertValue":     dAlert.AlertValue,
+			"alertOption":    dAlert.AlertOption,
+			"expirationTime": dAlert.ExpirationTime,
+			"alertActions":   "push,sms",
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "SaveAlert", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	expected := `
+	{
+	  "code": "InvalidBodyValue",
+	  "message": "[InvalidBodyValue] invalid alert request in body",
+	  "errors": [
+		{
+		  "field": "alertActions",
+		  "value": "push,sms",
+		  "message": "unknown alert action \"sms\", must be one of push"
+		}
+	  ]
+	}`
+	s.JSONEq(expected, res.Body.String())
+}
+
+func (s *HandlerSuite) TestSaveAlert_FailIfInvalidCondition() {
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":          dAlert.Title,
+			"body":           dAlert.Body,
+			"pairAddress":    dAlert.PairAddress,
+			"alertType":      dAlert.AlertType,
+			"alertValue":     "abc",
+			"alertOption":    dAlert.AlertOption,
+			"expirationTime": dAlert.ExpirationTime,
+			"alertActions":   dAlert.AlertActions,
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "SaveAlert", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	expected := `
+	{
+	  "code": "InvalidBodyValue",
+	  "message": "[InvalidBodyValue] invalid alert request in body",
+	  "errors": [
+		{
+		  "field": "alertValue",
+		  "value": "abc",
+		  "message": "alertValue must be a non negative number"
+		}
+	  ]
+	}`
+	s.JSONEq(expected, res.Body.String())
+}
+
+func (s *HandlerSuite) TestSaveAlert_WithCooldownAndRearmMargin() {
+	// given
+	s.db.On("SaveAlert", mock.Anything, mock.Anything).Return(nil)
+
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":          dAlert.Title,
+			"body":           dAlert.Body,
+			"pairAddress":    dAlert.PairAddress,
+			"alertType":      dAlert.AlertType,
+			"alertValue":     dAlert.AlertValue,
+			"alertOption":    dAlert.AlertOption,
+			"expirationTime": dAlert.ExpirationTime,
+			"alertActions":   dAlert.AlertActions,
+			"cooldownSecs":   300,
+			"rearmMargin":    20.5,
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "SaveAlert", mock.Anything, mock.MatchedBy(func(alert *model.Alert) bool {
+		return alert.CooldownSecs == 300 && alert.RearmMargin == 20.5 && alert.LastTriggeredAt == nil
+	}))
+	s.Equal(http.StatusCreated, res.Code)
+	s.Equal(int64(300), gjson.Get(res.Body.String(), "alert.cooldownSecs").Int())
+	s.Equal(20.5, gjson.Get(res.Body.String(), "alert.rearmMargin").Float())
+}
+
+func (s *HandlerSuite) TestSaveAlert_ExpirationTimeInUTC() {
+	// given
+	s.db.On("SaveAlert", mock.Anything, mock.Anything).Return(nil)
+	expirationTime := time.Date(2030, 1, 1, 9, 0, 0, 0, time.FixedZone("KST", 9*60*60))
+
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":          dAlert.Title,
+			"body":           dAlert.Body,
+			"pairAddress":    dAlert.PairAddress,
+			"alertType":      dAlert.AlertType,
+			"alertValue":     dAlert.AlertValue,
+			"alertOption":    dAlert.AlertOption,
+			"expirationTime": expirationTime.Format(time.RFC3339),
+			"alertActions":   dAlert.AlertActions,
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusCreated, res.Code)
+	s.db.AssertCalled(s.T(), "SaveAlert", mock.Anything, mock.MatchedBy(func(alert *model.Alert) bool {
+		return alert.ExpirationTime.Location() == time.UTC && alert.ExpirationTime.Equal(expirationTime)
+	}))
+}
+
+func (s *HandlerSuite) TestSaveAlert_FailIfNegativeCooldown() {
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":          dAlert.Title,
+			"body":           dAlert.Body,
+			"pairAddress":    dAlert.PairAddress,
+			"alertType":      dAlert.AlertType,
+			"alertValue":     dAlert.AlertValue,
+			"alertOption":    dAlert.AlertOption,
+			"expirationTime": dAlert.ExpirationTime,
+			"alertActions":   dAlert.AlertActions,
+			"cooldownSecs":   -1,
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "SaveAlert", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.Equal("cooldownSecs", gjson.Get(res.Body.String(), "errors.0.field").String())
+}
+
+func (s *HandlerSuite) TestSaveAlert_WithExpression() {
+	// given
+	s.db.On("SaveAlert", mock.Anything, mock.Anything).Return(nil)
+	condition := "price(0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48) < 1 AND liquidity_usd(0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48) > 500000"
+
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":          dAlert.Title,
+			"body":           dAlert.Body,
+			"alertType":      TypeExpression,
+			"condition":      condition,
+			"expirationTime": dAlert.ExpirationTime,
+			"alertActions":   dAlert.AlertActions,
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "SaveAlert", mock.Anything, mock.MatchedBy(func(alert *model.Alert) bool {
+		return alert.ConditionExpr == condition && alert.PairAddress == "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	}))
+	s.Equal(http.StatusCreated, res.Code)
+	s.Equal(condition, gjson.Get(res.Body.String(), "alert.condition").String())
+}
+
+func (s *HandlerSuite) TestSaveAlert_FailIfInvalidExpression() {
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":          dAlert.Title,
+			"body":           dAlert.Body,
+			"alertType":      TypeExpression,
+			"condition":      "price(0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48) < 1 AND",
+			"expirationTime": dAlert.ExpirationTime,
+			"alertActions":   dAlert.AlertActions,
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "SaveAlert", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	expected := `
+	{
+	  "code": "InvalidBodyValue",
+	  "message": "[InvalidBodyValue] invalid alert request in body",
+	  "errors": [
+		{
+		  "field": "condition",
+		  "value": "price(0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48) < 1 AND",
+		  "message": "expected a metric but got end of condition"
+		}
+	  ]
+	}`
+	s.JSONEq(expected, res.Body.String())
+}
+
+func (s *HandlerSuite) TestSaveAlert_FailIfNoConditionOfExpression() {
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":          dAlert.Title,
+			"body":           dAlert.Body,
+			"alertType":      TypeExpression,
+			"expirationTime": dAlert.ExpirationTime,
+			"alertActions":   dAlert.AlertActions,
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "SaveAlert", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.Equal("condition", gjson.Get(res.Body.String(), "errors.0.field").String())
+}
+
+func (s *HandlerSuite) TestSaveAlert_FailIfInvalidWindow() {
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":          dAlert.Title,
+			"body":           dAlert.Body,
+			"pairAddress":    dAlert.PairAddress,
+			"alertType":      TypePercentChange,
+			"alertValue":     "5",
+			"alertOption":    "2h",
+			"expirationTime": dAlert.ExpirationTime,
+			"alertActions":   dAlert.AlertActions,
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "SaveAlert", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	expected := `
+	{
+	  "code": "InvalidBodyValue",
+	  "message": "[InvalidBodyValue] invalid alert request in body",
+	  "errors": [
+		{
+		  "field": "alertOption",
+		  "value": "2h",
+		  "message": "alertOption must be one of 5m, 15m, 1h, 4h, 24h"
+		}
+	  ]
+	}`
+	s.JSONEq(expected, res.Body.String())
+}
+
+func (s *HandlerSuite) TestUpdateAlert() {
+	// given
+	alert := dAlert
+	alert.AccountId = dUser.ID
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+	s.db.On("UpdateAlert", mock.Anything, dUser.ID, mock.Anything).Return(nil)
+
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":        "How to ride your dragon",
+			"alertValue":   "1400",
+			"cooldownSecs": 0,
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/v1/api/alerts/"+alert.Slug, bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "UpdateAlert", mock.Anything, dUser.ID, mock.MatchedBy(func(a *model.Alert) bool {
+		return a.ID == dAlert.ID &&
+			a.Slug == dAlert.Slug &&
+			a.Title == "How to ride your dragon" &&
+			a.AlertValue == "1400" &&
+			a.Body == dAlert.Body &&
+			a.AlertOption == dAlert.AlertOption
+	}))
+	s.Equal(http.StatusOK, res.Code)
+	// the slug is kept on rename
+	s.Equal(dAlert.Slug, gjson.Get(res.Body.String(), "alert.slug").String())
+	s.Equal("1400", gjson.Get(res.Body.String(), "alert.alertValue").String())
+}
+
+func (s *HandlerSuite) TestUpdateAlert_FailIfNotOwner() {
+	// given
+	alert := dAlert
+	alert.AccountId = dUser.ID + 1
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+
+	// when
+	b, _ := json.Marshal(map[string]interface{}{"alert": map[string]interface{}{"alertValue": "1400"}})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/v1/api/alerts/"+alert.Slug, bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "UpdateAlert", mock.Anything, mock.Anything, mock.Anything)
+	s.Equal(http.StatusNotFound, res.Code)
+}
+
+func (s *HandlerSuite) TestUpdateAlert_FailIfInvalidCondition() {
+	// given
+	alert := dAlert
+	alert.AccountId = dUser.ID
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+
+	// when
+	b, _ := json.Marshal(map[string]interface{}{"alert": map[string]interface{}{"alertOption": "cross"}})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/v1/api/alerts/"+alert.Slug, bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "UpdateAlert", mock.Anything, mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.Equal("alertOption", gjson.Get(res.Body.String(), "errors.0.field").String())
 }
 
 func (s *HandlerSuite) TestAlertBySlug() {
 	// given
-	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(&dAlert, nil)
+	alert := dAlert
+	alert.Account, alert.AccountId = dUser, dUser.ID
+	alert.Visibility = model.VisibilityPrivate
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
 
 	// when
 	res := httptest.NewRecorder()
-	req, _ := http.NewRequest("GET", "/v1/api/alerts/"+dAlert.Slug, nil)
+	req, _ := http.NewRequest("GET", "/v1/api/alerts/"+alert.Slug, nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
 
 	s.r.ServeHTTP(res, req)
 
 	// then
 	// 1) method called
-	s.db.AssertCalled(s.T(), "FindAlertBySlug", mock.Anything, dAlert.Slug)
+	s.db.AssertCalled(s.T(), "FindAlertBySlug", mock.Anything, alert.Slug)
 	// 2) status code
 	s.Equal(http.StatusOK, res.Code)
 	// 3) body
 	jsonVal := res.Body.String()
-	s.assertAlertResponse(&dAlert, gjson.Parse(jsonVal).Get("alert"))
+	s.assertAlertResponse(&alert, gjson.Parse(jsonVal).Get("alert"))
+}
+
+func (s *HandlerSuite) TestAlertBySlug_PublicOfOtherAccount() {
+	// given
+	alert := dAlert
+	alert.Account, alert.AccountId = accountModel.Account{ID: dUser.ID + 1, Username: "user2"}, dUser.ID+1
+	alert.Visibility = model.VisibilityPublic
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/alerts/"+alert.Slug, nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.assertAlertResponse(&alert, gjson.Parse(res.Body.String()).Get("alert"))
+}
+
+func (s *HandlerSuite) TestAlertBySlug_FailIfPrivateOfOtherAccount() {
+	// given
+	alert := dAlert
+	alert.AccountId = dUser.ID + 1
+	alert.Visibility = model.VisibilityPrivate
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/alerts/"+alert.Slug, nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusNotFound, res.Code)
+}
+
+func (s *HandlerSuite) TestAlertBySlug_FailIfAnonymous() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/alerts/"+dAlert.Slug, nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "FindAlertBySlug", mock.Anything, mock.Anything)
+	s.Equal(http.StatusUnauthorized, res.Code)
 }
 
 func (s *HandlerSuite) TestAlerts() {
-	criteria := database.IterateAlertCriteria{
-		Account: dAlert.Account.Username,
+	// given
+	alert := dAlert
+	alert.Account, alert.AccountId = dUser, dUser.ID
+	criteria := alertDB.IterateAlertCriteria{
+		Account: dUser.ID,
 		Offset:  0,
 		Limit:   5,
 	}
-	s.db.On("FindAlerts", mock.Anything, criteria).Return([]*model.Alert{&dAlert}, int64(1), nil)
+	s.db.On("FindAlerts", mock.Anything, criteria).Return([]*model.Alert{&alert}, int64(1), nil)
 
 	// when
-	url := fmt.Sprintf("/v1/api/alerts?tag=%s&account=%s&offset=%d&limit=%d",
-		criteria.Account, criteria.Offset, criteria.Limit)
+	url := fmt.Sprintf("/v1/api/alerts?offset=%d&limit=%d", criteria.Offset, criteria.Limit)
 
 	res := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
 
 	s.r.ServeHTTP(res, req)
 
@@ -164,7 +845,51 @@ func (s *HandlerSuite) TestAlerts() {
 
 	alertsResult := result.Get("alerts").Array()
 	s.Equal(1, len(alertsResult))
-	s.assertAlertResponse(&dAlert, alertsResult[0])
+	s.assertAlertResponse(&alert, alertsResult[0])
+}
+
+func (s *HandlerSuite) TestAlerts_PublicOfOtherAccount() {
+	// given
+	criteria := alertDB.IterateAlertCriteria{
+		Author:     "user2",
+		Visibility: model.VisibilityPublic,
+		Offset:     0,
+		Limit:      5,
+	}
+	s.db.On("FindAlerts", mock.Anything, criteria).Return([]*model.Alert{}, int64(0), nil)
+
+	// when
+	url := fmt.Sprintf("/v1/api/alerts?author=%s", criteria.Author)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "FindAlerts", mock.Anything, criteria)
+	s.Equal(http.StatusOK, res.Code)
+}
+
+func (s *HandlerSuite) TestAlerts_OwnByAuthor() {
+	// given
+	criteria := alertDB.IterateAlertCriteria{
+		Account: dUser.ID,
+		Offset:  0,
+		Limit:   5,
+	}
+	s.db.On("FindAlerts", mock.Anything, criteria).Return([]*model.Alert{}, int64(0), nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/alerts?author="+dUser.Username, nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "FindAlerts", mock.Anything, criteria)
+	s.Equal(http.StatusOK, res.Code)
 }
 
 func (s *HandlerSuite) TestDeleteAlert() {
@@ -187,6 +912,100 @@ func (s *HandlerSuite) TestDeleteAlert() {
 	s.Empty(res.Body.Bytes())
 }
 
+func (s *HandlerSuite) TestPauseAlert() {
+	// given
+	alert := dAlert
+	alert.AlertStatus = model.StatusActive
+	alert.AccountId = dUser.ID
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+	s.db.On("UpdateAlertStatus", mock.Anything, alert.ID, model.StatusActive, model.StatusPaused).Return(nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+alert.Slug+"/pause", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "UpdateAlertStatus", mock.Anything, alert.ID, model.StatusActive, model.StatusPaused)
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal(model.StatusPaused, gjson.Get(res.Body.String(), "alert.alertStatus").String())
+}
+
+func (s *HandlerSuite) TestResumeAlert() {
+	// given
+	alert := dAlert
+	alert.AlertStatus = model.StatusPaused
+	alert.AccountId = dUser.ID
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+	s.db.On("UpdateAlertStatus", mock.Anything, alert.ID, model.StatusPaused, model.StatusActive).Return(nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+alert.Slug+"/resume", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "UpdateAlertStatus", mock.Anything, alert.ID, model.StatusPaused, model.StatusActive)
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal(model.StatusActive, gjson.Get(res.Body.String(), "alert.alertStatus").String())
+}
+
+func (s *HandlerSuite) TestTransitAlert_FailIfIllegalTransition() {
+	cases := []struct {
+		Status string
+		Action string
+	}{
+		{Status: model.StatusActive, Action: "resume"},
+		{Status: model.StatusPaused, Action: "pause"},
+		{Status: model.StatusExpired, Action: "resume"},
+		{Status: model.StatusCancelled, Action: "pause"},
+	}
+
+	for _, tc := range cases {
+		// given
+		s.SetupTest()
+		alert := dAlert
+		alert.AlertStatus = tc.Status
+		alert.AccountId = dUser.ID
+		s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+
+		// when
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v1/api/alerts/"+alert.Slug+"/"+tc.Action, nil)
+		req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+		s.r.ServeHTTP(res, req)
+
+		// then
+		s.db.AssertNotCalled(s.T(), "UpdateAlertStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		s.Equal(http.StatusConflict, res.Code)
+		s.Equal(string(handler.InvalidStatusTransition), gjson.Get(res.Body.String(), "code").String())
+	}
+}
+
+func (s *HandlerSuite) TestTransitAlert_FailIfNotOwner() {
+	// given
+	alert := dAlert
+	alert.AlertStatus = model.StatusActive
+	alert.AccountId = dUser.ID + 1
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+alert.Slug+"/pause", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "UpdateAlertStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	s.Equal(http.StatusNotFound, res.Code)
+}
+
 func (s *HandlerSuite) assertAlertResponse(alert *model.Alert, result gjson.Result) {
 	s.Equal(slug.Make(alert.Title), result.Get("slug").String())
 	s.Equal(alert.Title, result.Get("title").String())
@@ -194,15 +1013,23 @@ func (s *HandlerSuite) assertAlertResponse(alert *model.Alert, result gjson.Resu
 
 	s.True(result.Get("createdAt").Exists())
 	s.True(result.Get("updatedAt").Exists())
-	s.Equal(alert.Account.Username, result.Get("account.username").String())
-	s.Equal(alert.Account.Bio, result.Get("account.bio").String())
-	s.Equal(alert.Account.Image, result.Get("account.image").String())
+	s.Equal(alert.Account.Username, result.Get("author.username").String())
+	s.Equal(alert.Account.Bio, result.Get("author.bio").String())
+	s.Equal(alert.Account.Image, result.Get("author.image").String())
+	s.False(result.Get("author.password").Exists())
+	s.False(result.Get("author.token").Exists())
+	s.False(result.Get("account").Exists())
 }
 
 func (s *HandlerSuite) getBearerToken() string {
+	return s.login(dUser.Email)
+}
+
+// login signs in an account with given email and the password of dUser and returns the token
+func (s *HandlerSuite) login(email string) string {
 	body := map[string]interface{}{
 		"user": map[string]interface{}{
-			"email":    dUser.Email,
+			"email":    email,
 			"password": dUserRawPass,
 		},
 	}
@@ -220,7 +1047,7 @@ func alertMatcher(title, body string, account *accountModel.Account) func(a *mod
 		if a.Slug != slug.Make(title) || a.Title != title || a.Body != body {
 			return false
 		}
-		if a.Account.Username != account.Username || a.Account.Bio != account.Bio || a.Account.Image != account.Image {
+		if a.AccountId != account.ID || a.Visibility != model.VisibilityPrivate {
 			return false
 		}
 		return true
@@ -2,24 +2,124 @@ package model
 
 import (
 	accountModel "kek-backend/internal/account/model"
+	"strings"
 	"time"
 )
 
+// alert statuses
+const (
+	StatusActive    = "active"
+	StatusTriggered = "triggered"
+	StatusPaused    = "paused"
+	StatusExpired   = "expired"
+	StatusCancelled = "cancelled"
+)
+
+// alert visibilities
+const (
+	VisibilityPrivate = "private"
+	VisibilityPublic  = "public"
+)
+
+// alert event delivery statuses
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
+// statusTransitions is allowed next statuses of each status.
+// expired and cancelled are terminal statuses
+var statusTransitions = map[string][]string{
+	StatusActive:    {StatusTriggered, StatusPaused, StatusExpired, StatusCancelled},
+	StatusTriggered: {StatusActive, StatusPaused, StatusExpired, StatusCancelled},
+	StatusPaused:    {StatusActive, StatusExpired, StatusCancelled},
+}
+
 type Alert struct {
+	ID              uint       `gorm:"column:id"`
+	Slug            string     `gorm:"column:slug"`
+	Title           string     `gorm:"column:title"`
+	Body            string     `gorm:"column:body"`
+	PairAddress     string     `gorm:"column:pair_address"`
+	AlertType       string     `gorm:"column:alert_type"`
+	AlertValue      string     `gorm:"column:alert_value"`
+	AlertOption     string     `gorm:"column:alert_option"`
+	ConditionExpr   string     `gorm:"column:condition_expr"`
+	ExpirationTime  time.Time  `gorm:"column:expiration_time"`
+	AlertActions    string     `gorm:"column:alert_actions"`
+	AlertStatus     string     `gorm:"column:alert_status"`
+	Visibility      string     `gorm:"column:visibility"`
+	CooldownSecs    int64      `gorm:"column:cooldown_secs"`
+	RearmMargin     float64    `gorm:"column:rearm_margin"`
+	LastTriggeredAt *time.Time `gorm:"column:last_triggered_at"`
+	Critical        bool       `gorm:"column:critical"`
+	CreatedAt       time.Time  `gorm:"column:created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at"`
+	DeletedAtUnix   int64      `gorm:"column:deleted_at_unix"`
+	Account         accountModel.Account
+	AccountId       uint
+}
+
+// AlertEvent is a record of an alert triggered with the observed price and the notification outcome
+type AlertEvent struct {
 	ID             uint      `gorm:"column:id"`
-	Slug           string    `gorm:"column:slug"`
-	Title          string    `gorm:"column:title"`
-	Body           string    `gorm:"column:body"`
-	PairAddress    string    `gorm:"column:pair_address"`
-	AlertType      string    `gorm:"column:alert_type"`
-	AlertValue     string    `gorm:"column:alert_value"`
-	AlertOption    string    `gorm:"column:alert_option"`
-	ExpirationTime time.Time `gorm:"column:expiration_time"`
-	AlertActions   string    `gorm:"column:alert_actions"`
-	AlertStatus    string    `gorm:"column:alert_status"`
+	AlertID        uint      `gorm:"column:alert_id"`
+	TriggeredAt    time.Time `gorm:"column:triggered_at"`
+	ObservedPrice  float64   `gorm:"column:observed_price"`
+	Condition      string    `gorm:"column:condition"`
+	DeliveryStatus string    `gorm:"column:delivery_status"`
+	DeliveryError  string    `gorm:"column:delivery_error"`
 	CreatedAt      time.Time `gorm:"column:created_at"`
 	UpdatedAt      time.Time `gorm:"column:updated_at"`
-	DeletedAtUnix  int64     `gorm:"column:deleted_at_unix"`
-	Account        accountModel.Account
-	AccountId      uint
+}
+
+// AlertSubscription is a subscription of an account to a public alert of another account
+type AlertSubscription struct {
+	ID        uint      `gorm:"column:id"`
+	AlertID   uint      `gorm:"column:alert_id"`
+	AccountID uint      `gorm:"column:account_id"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+// CanTransitionTo returns true if the alert can move from current status to given status
+func (a *Alert) CanTransitionTo(status string) bool {
+	for _, next := range statusTransitions[a.AlertStatus] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired returns true if the expiration time of the alert has passed at given time
+func (a *Alert) IsExpired(now time.Time) bool {
+	return !a.ExpirationTime.IsZero() && !a.ExpirationTime.After(now)
+}
+
+// InCooldown returns true if the alert was triggered within its cooldown at given time
+func (a *Alert) InCooldown(now time.Time) bool {
+	if a.LastTriggeredAt == nil || a.CooldownSecs <= 0 {
+		return false
+	}
+	return now.Before(a.LastTriggeredAt.Add(time.Duration(a.CooldownSecs) * time.Second))
+}
+
+// IsVisibleTo returns true if the alert is public or owned by given account
+func (a *Alert) IsVisibleTo(accountId uint) bool {
+	return a.Visibility == VisibilityPublic || a.AccountId == accountId
+}
+
+// Actions returns notification channels of the alert, listed in AlertActions separated by comma
+func (a *Alert) Actions() []string {
+	var ret []string
+	seen := make(map[string]bool)
+	for _, action := range strings.Split(a.AlertActions, ",") {
+		action = strings.ToLower(strings.TrimSpace(action))
+		if action != "" && !seen[action] {
+			seen[action] = true
+			ret = append(ret, action)
+		}
+	}
+	return ret
 }
@@ -0,0 +1,52 @@
+package model
+
+import (
+	accountModel "kek-backend/internal/account/model"
+	"time"
+)
+
+// notification statuses.
+// sent, skipped and dead are final statuses
+const (
+	NotificationPending = "pending"
+	NotificationSent    = "sent"
+	NotificationSkipped = "skipped"
+	NotificationDead    = "dead"
+)
+
+// Notification is an outbox entry of an alert event to be delivered to an account over an action
+type Notification struct {
+	ID            uint      `gorm:"column:id"`
+	AlertEventID  uint      `gorm:"column:alert_event_id"`
+	AlertID       uint      `gorm:"column:alert_id"`
+	Action        string    `gorm:"column:action"`
+	Title         string    `gorm:"column:title"`
+	Body          string    `gorm:"column:body"`
+	Slug          string    `gorm:"column:slug"`
+	Condition     string    `gorm:"column:condition"`
+	ObservedPrice float64   `gorm:"column:observed_price"`
+	TriggeredAt   time.Time `gorm:"column:triggered_at"`
+	Status        string    `gorm:"column:status"`
+	Attempts      int       `gorm:"column:attempts"`
+	NextAttemptAt time.Time `gorm:"column:next_attempt_at"`
+	LastError     string    `gorm:"column:last_error"`
+	Critical      bool      `gorm:"column:critical"`
+	CreatedAt     time.Time `gorm:"column:created_at"`
+	UpdatedAt     time.Time `gorm:"column:updated_at"`
+	Account       accountModel.Account
+	AccountId     uint
+}
+
+// NotificationAttempt is a record of a delivery attempt of a notification with the error if failed
+type NotificationAttempt struct {
+	ID             uint      `gorm:"column:id"`
+	NotificationID uint      `gorm:"column:notification_id"`
+	AttemptedAt    time.Time `gorm:"column:attempted_at"`
+	Error          string    `gorm:"column:error"`
+	CreatedAt      time.Time `gorm:"column:created_at"`
+}
+
+// IsFinished returns true if the notification will not be delivered anymore
+func (n *Notification) IsFinished() bool {
+	return n.Status != NotificationPending
+}
@@ -1,7 +1,6 @@
 package alert
 
 import (
-	accountModel "kek-backend/internal/account/model"
 	"kek-backend/internal/alert/model"
 	"time"
 )
@@ -16,19 +15,31 @@ type AlertsResponse struct {
 }
 
 type Alert struct {
-	Slug           string    `json:"slug"`
-	Title          string    `json:"title"`
-	Body           string    `json:"body"`
-	PairAddress    string    `json:"pairAddress"`
-	AlertType      string    `json:"alertType"`
-	AlertValue     string    `json:"alertValue"`
-	AlertOption    string    `json:"alertOption"`
-	ExpirationTime time.Time `json:"expirationTime"`
-	AlertActions   string    `json:"alertActions"`
-	AlertStatus    string    `json:"alertStatus"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
-	Account        accountModel.Account
+	Slug            string     `json:"slug"`
+	Title           string     `json:"title"`
+	Body            string     `json:"body"`
+	PairAddress     string     `json:"pairAddress"`
+	AlertType       string     `json:"alertType"`
+	AlertValue      string     `json:"alertValue"`
+	AlertOption     string     `json:"alertOption"`
+	Condition       string     `json:"condition,omitempty"`
+	ExpirationTime  time.Time  `json:"expirationTime"`
+	AlertActions    string     `json:"alertActions"`
+	AlertStatus     string     `json:"alertStatus"`
+	Visibility      string     `json:"visibility"`
+	CooldownSecs    int64      `json:"cooldownSecs"`
+	RearmMargin     float64    `json:"rearmMargin"`
+	LastTriggeredAt *time.Time `json:"lastTriggeredAt"`
+	Critical        bool       `json:"critical"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+	Author          Author     `json:"author"`
+}
+
+type Author struct {
+	Username string `json:"username"`
+	Bio      string `json:"bio"`
+	Image    string `json:"image"`
 }
 
 // NewAlertsResponse converts alert models and total count to AlertsResponse
@@ -48,19 +59,120 @@ func NewAlertsResponse(alerts []*model.Alert, total int64) *AlertsResponse {
 func NewAlertResponse(a *model.Alert) *AlertResponse {
 	return &AlertResponse{
 		Alert: Alert{
-			Slug:           a.Slug,
-			Title:          a.Title,
-			Body:           a.Body,
-			PairAddress:    a.PairAddress,
-			AlertType:      a.AlertType,
-			AlertValue:     a.AlertValue,
-			AlertOption:    a.AlertOption,
-			ExpirationTime: a.ExpirationTime,
-			AlertActions:   a.AlertActions,
-			AlertStatus:    a.AlertStatus,
-			CreatedAt:      a.CreatedAt,
-			UpdatedAt:      a.UpdatedAt,
-			Account:        a.Account,
+			Slug:            a.Slug,
+			Title:           a.Title,
+			Body:            a.Body,
+			PairAddress:     a.PairAddress,
+			AlertType:       a.AlertType,
+			AlertValue:      a.AlertValue,
+			AlertOption:     a.AlertOption,
+			Condition:       a.ConditionExpr,
+			ExpirationTime:  a.ExpirationTime,
+			AlertActions:    a.AlertActions,
+			AlertStatus:     a.AlertStatus,
+			Visibility:      a.Visibility,
+			CooldownSecs:    a.CooldownSecs,
+			RearmMargin:     a.RearmMargin,
+			LastTriggeredAt: a.LastTriggeredAt,
+			Critical:        a.Critical,
+			CreatedAt:       a.CreatedAt,
+			UpdatedAt:       a.UpdatedAt,
+			Author: Author{
+				Username: a.Account.Username,
+				Bio:      a.Account.Bio,
+				Image:    a.Account.Image,
+			},
+		},
+	}
+}
+
+type AlertEventsResponse struct {
+	Events      []AlertEvent `json:"events"`
+	EventsCount int64        `json:"eventsCount"`
+}
+
+type AlertEvent struct {
+	TriggeredAt    time.Time `json:"triggeredAt"`
+	ObservedPrice  float64   `json:"observedPrice"`
+	Condition      string    `json:"condition"`
+	DeliveryStatus string    `json:"deliveryStatus"`
+	DeliveryError  string    `json:"deliveryError,omitempty"`
+}
+
+// NewAlertEventsResponse converts alert event models and total count to AlertEventsResponse
+func NewAlertEventsResponse(events []*model.AlertEvent, total int64) *AlertEventsResponse {
+	e := make([]AlertEvent, 0, len(events))
+	for _, event := range events {
+		e = append(e, AlertEvent{
+			TriggeredAt:    event.TriggeredAt,
+			ObservedPrice:  event.ObservedPrice,
+			Condition:      event.Condition,
+			DeliveryStatus: event.DeliveryStatus,
+			DeliveryError:  event.DeliveryError,
+		})
+	}
+	return &AlertEventsResponse{
+		Events:      e,
+		EventsCount: total,
+	}
+}
+
+type NotificationResponse struct {
+	Notification Notification `json:"notification"`
+}
+
+type NotificationsResponse struct {
+	Notifications      []Notification `json:"notifications"`
+	NotificationsCount int64          `json:"notificationsCount"`
+}
+
+type Notification struct {
+	ID            uint      `json:"id"`
+	Slug          string    `json:"slug"`
+	Action        string    `json:"action"`
+	Recipient     Author    `json:"recipient"`
+	Condition     string    `json:"condition"`
+	ObservedPrice float64   `json:"observedPrice"`
+	TriggeredAt   time.Time `json:"triggeredAt"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	LastError     string    `json:"lastError,omitempty"`
+}
+
+func newNotification(n *model.Notification) Notification {
+	return Notification{
+		ID:     n.ID,
+		Slug:   n.Slug,
+		Action: n.Action,
+		Recipient: Author{
+			Username: n.Account.Username,
+			Bio:      n.Account.Bio,
+			Image:    n.Account.Image,
 		},
+		Condition:     n.Condition,
+		ObservedPrice: n.ObservedPrice,
+		TriggeredAt:   n.TriggeredAt,
+		Status:        n.Status,
+		Attempts:      n.Attempts,
+		NextAttemptAt: n.NextAttemptAt,
+		LastError:     n.LastError,
+	}
+}
+
+// NewNotificationResponse converts notification model to NotificationResponse
+func NewNotificationResponse(n *model.Notification) *NotificationResponse {
+	return &NotificationResponse{Notification: newNotification(n)}
+}
+
+// NewNotificationsResponse converts notification models and total count to NotificationsResponse
+func NewNotificationsResponse(notifications []*model.Notification, total int64) *NotificationsResponse {
+	n := make([]Notification, 0, len(notifications))
+	for _, notification := range notifications {
+		n = append(n, newNotification(notification))
+	}
+	return &NotificationsResponse{
+		Notifications:      n,
+		NotificationsCount: total,
 	}
 }
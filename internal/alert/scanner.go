@@ -0,0 +1,510 @@
+package alert
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	accountDB "kek-backend/internal/account/database"
+	accountModel "kek-backend/internal/account/model"
+	alertDB "kek-backend/internal/alert/database"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/config"
+	"kek-backend/internal/database"
+	priceDB "kek-backend/internal/price/database"
+	priceModel "kek-backend/internal/price/model"
+	"kek-backend/internal/stream"
+	"kek-backend/pkg/logging"
+)
+
+// Scanner evaluates active alerts of all accounts.
+// Alerts are read in batches and evaluated by a bounded number of workers.
+// Fetched prices and triggered alerts are published to the stream hub
+type Scanner struct {
+	alertDB   alertDB.AlertDB
+	accountDB accountDB.AccountDB
+	priceDB   priceDB.PriceDB
+	hub       *stream.Hub
+	source    PriceSource
+	batchSize uint
+	workers   int
+	// retention is how long price snapshots are kept, forever if zero
+	retention time.Duration
+}
+
+// scanLockId is the id of the advisory lock held while a tick is scanned
+const scanLockId int64 = 0x6b656b5363616e
+
+// Scan expires outdated alerts and evaluates all active alerts once.
+// A tick fetches the ETH price and quotes of each distinct token of the batches from the price source once.
+// The tick is skipped if the scanner of another instance is scanning, so that snapshots are saved
+// and alerts are triggered by a single instance
+func (s *Scanner) Scan(ctx context.Context) error {
+	locked, err := s.alertDB.RunLocked(ctx, scanLockId, s.scanTick)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		logging.FromContext(ctx).Debug("alert.scanner skip a tick scanned by another instance")
+	}
+	return nil
+}
+
+// scanTick expires outdated alerts and evaluates all active alerts at now
+func (s *Scanner) scanTick(ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+	now := time.Now()
+
+	if expired, err := s.alertDB.ExpireAlerts(ctx, now); err != nil {
+		logger.Errorw("alert.scanner failed to expire alerts", "err", err)
+	} else if expired > 0 {
+		logger.Infow("alert.scanner expired alerts", "count", expired)
+	}
+
+	ethPrice := ethPriceCache{fetch: s.source.EthPrice}
+	cache := quoteCache{
+		fetch: func(ctx context.Context, addresses []string) Quotes {
+			price, err := ethPrice.ethPrice(ctx)
+			if err != nil {
+				return nil
+			}
+			quotes, err := s.source.Quotes(ctx, price, addresses)
+			if err != nil {
+				logger.Errorw("alert.scanner failed to fetch quotes", "addresses", addresses, "err", err)
+				return nil
+			}
+			s.saveSnapshots(ctx, now, quotes)
+			s.publishPrices(now, quotes)
+			return quotes
+		},
+		fetched: make(Quotes),
+	}
+	baselines := baselineCache{
+		lookup: func(address string, window time.Duration) (float64, error) {
+			return s.findBaseline(ctx, address, now, window)
+		},
+		prices: make(map[baselineKey]baselineResult),
+	}
+	return s.scan(ctx, now, cache.quotes, func(ctx context.Context, alert *model.Alert, quotes Quotes) {
+		s.evaluate(ctx, now, alert, &market{Quotes: quotes, baselines: &baselines})
+	})
+}
+
+// scan pages through active alerts at given time.
+// quote is called with each batch in the scanning goroutine and
+// evaluate is called for each alert of the batch with the quotes in the worker pool
+func (s *Scanner) scan(ctx context.Context, now time.Time,
+	quote func(ctx context.Context, alerts []*model.Alert) Quotes,
+	evaluate func(ctx context.Context, alert *model.Alert, quotes Quotes)) error {
+	type job struct {
+		alert  *model.Alert
+		quotes Quotes
+	}
+	jobs := make(chan job, s.workers)
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				evaluate(ctx, j.alert, j.quotes)
+			}
+		}()
+	}
+
+	var err error
+	criteria := alertDB.IterateActiveAlertCriteria{
+		Limit: s.batchSize,
+		Now:   now,
+	}
+	for {
+		var alerts []*model.Alert
+		alerts, err = s.alertDB.FindActiveAlerts(ctx, criteria)
+		if err != nil {
+			break
+		}
+		if len(alerts) != 0 {
+			quotes := quote(ctx, alerts)
+			for _, alert := range alerts {
+				jobs <- job{alert: alert, quotes: quotes}
+			}
+		}
+		if uint(len(alerts)) < s.batchSize {
+			break
+		}
+		criteria.AfterID = alerts[len(alerts)-1].ID
+		if err = ctx.Err(); err != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return err
+}
+
+// ethPriceCache keeps the ETH price fetched in a tick so that it is fetched once per tick.
+// A failure is kept as well so that a tick does not retry it for each batch.
+// It is used by the scanning goroutine only
+type ethPriceCache struct {
+	fetch   func(ctx context.Context) (float64, error)
+	fetched bool
+	price   float64
+	err     error
+}
+
+func (c *ethPriceCache) ethPrice(ctx context.Context) (float64, error) {
+	if !c.fetched {
+		c.price, c.err = c.fetch(ctx)
+		c.fetched = true
+		if c.err != nil {
+			logging.FromContext(ctx).Errorw("alert.scanner failed to fetch eth price", "err", c.err)
+		}
+	}
+	return c.price, c.err
+}
+
+// quoteCache keeps quotes fetched in a tick so that a token is fetched once per tick
+type quoteCache struct {
+	fetch   func(ctx context.Context, addresses []string) Quotes
+	fetched Quotes
+}
+
+// quotes returns quotes of tokens of given alerts and fetches tokens at once not fetched yet
+func (c *quoteCache) quotes(ctx context.Context, alerts []*model.Alert) Quotes {
+	var (
+		addresses []string
+		missing   []string
+	)
+	seen := make(map[string]bool)
+	for _, alert := range alerts {
+		for _, address := range alertAddresses(alert) {
+			if seen[address] {
+				continue
+			}
+			seen[address] = true
+			addresses = append(addresses, address)
+			if _, ok := c.fetched[address]; !ok {
+				missing = append(missing, address)
+			}
+		}
+	}
+	if len(missing) != 0 {
+		for address, q := range c.fetch(ctx, missing) {
+			c.fetched[address] = q
+		}
+	}
+
+	quotes := make(Quotes, len(addresses))
+	for _, address := range addresses {
+		if q, ok := c.fetched[address]; ok {
+			quotes[address] = q
+		}
+	}
+	return quotes
+}
+
+// alertAddresses returns lower cased addresses of tokens the condition of given alert observes
+func alertAddresses(alert *model.Alert) []string {
+	if cond, err := ParseCondition(alert); err == nil {
+		return cond.Addresses()
+	}
+	return []string{strings.ToLower(alert.PairAddress)}
+}
+
+// market is a Market of quotes of a batch and baselines of a tick
+type market struct {
+	Quotes
+	baselines *baselineCache
+}
+
+func (m *market) Baseline(address string, window time.Duration) (float64, error) {
+	return m.baselines.baseline(strings.ToLower(address), window)
+}
+
+type baselineKey struct {
+	address string
+	window  time.Duration
+}
+
+type baselineResult struct {
+	price float64
+	err   error
+}
+
+// baselineCache keeps baseline prices looked up in a tick so that a token and window is looked up once per tick.
+// It is safe for concurrent use by workers
+type baselineCache struct {
+	mu     sync.Mutex
+	lookup func(address string, window time.Duration) (float64, error)
+	prices map[baselineKey]baselineResult
+}
+
+func (c *baselineCache) baseline(address string, window time.Duration) (float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := baselineKey{address: address, window: window}
+	if r, ok := c.prices[key]; ok {
+		return r.price, r.err
+	}
+	price, err := c.lookup(address, window)
+	c.prices[key] = baselineResult{price: price, err: err}
+	return price, err
+}
+
+// findBaseline returns the USD price of given token observed the window ago from given time.
+// ErrNoBaseline is returned if there is no snapshot or the latest snapshot is older than twice the window
+func (s *Scanner) findBaseline(ctx context.Context, address string, now time.Time, window time.Duration) (float64, error) {
+	at := now.Add(-window)
+	snapshot, err := s.priceDB.FindSnapshotBefore(ctx, address, at)
+	if err != nil {
+		if database.IsRecordNotFoundErr(err) {
+			return 0, ErrNoBaseline
+		}
+		return 0, err
+	}
+	if snapshot.ObservedAt.Before(at.Add(-window)) {
+		return 0, ErrNoBaseline
+	}
+	return snapshot.USDPrice, nil
+}
+
+// saveSnapshots stores given quotes observed at given time as price snapshots
+func (s *Scanner) saveSnapshots(ctx context.Context, observedAt time.Time, quotes Quotes) {
+	snapshots := make([]*priceModel.PriceSnapshot, 0, len(quotes))
+	for _, q := range quotes {
+		snapshots = append(snapshots, &priceModel.PriceSnapshot{
+			TokenAddress:   q.Address,
+			DerivedETH:     q.DerivedETH,
+			EthPrice:       q.EthPrice,
+			USDPrice:       q.USDPrice(),
+			TotalLiquidity: q.TotalLiquidity,
+			ObservedAt:     observedAt,
+		})
+	}
+	if err := s.priceDB.SaveSnapshots(ctx, snapshots); err != nil {
+		logging.FromContext(ctx).Errorw("alert.scanner failed to save price snapshots", "err", err)
+	}
+}
+
+// Prune deletes price snapshots older than the retention
+func (s *Scanner) Prune(ctx context.Context) error {
+	if s.retention <= 0 {
+		return nil
+	}
+	deleted, err := s.priceDB.DeleteSnapshotsBefore(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		logging.FromContext(ctx).Infow("alert.scanner pruned price snapshots", "count", deleted)
+	}
+	return nil
+}
+
+// publishPrices publishes given quotes observed at given time to the stream hub
+func (s *Scanner) publishPrices(observedAt time.Time, quotes Quotes) {
+	ticks := make([]*stream.PriceTick, 0, len(quotes))
+	for _, q := range quotes {
+		ticks = append(ticks, &stream.PriceTick{
+			Address:    q.Address,
+			Symbol:     q.Symbol,
+			USDPrice:   q.USDPrice(),
+			EthPrice:   q.EthPrice,
+			ObservedAt: observedAt,
+		})
+	}
+	s.hub.PublishPrices(ticks)
+}
+
+// evaluate evaluates the condition of given alert with given market at given time and moves it to the next status
+func (s *Scanner) evaluate(ctx context.Context, now time.Time, alert *model.Alert, m Market) {
+	logger := logging.FromContext(ctx)
+	cond, err := ParseCondition(alert)
+	if err != nil {
+		logger.Warnw("alert.scanner skip an alert with invalid condition", "slug", alert.Slug, "err", err)
+		return
+	}
+
+	next, err := nextStatus(alert, cond, m, now)
+	if err != nil {
+		if err == ErrNoBaseline {
+			logger.Debugw("alert.scanner skip an alert without baseline price", "slug", alert.Slug)
+			return
+		}
+		logger.Errorw("alert.scanner failed to evaluate an alert", "slug", alert.Slug, "err", err)
+		return
+	}
+	logger.Debugw("alert.scanner evaluated an alert", "slug", alert.Slug, "status", alert.AlertStatus, "next", next)
+	if next == alert.AlertStatus {
+		return
+	}
+	if next == model.StatusTriggered {
+		s.trigger(ctx, now, alert, m)
+		return
+	}
+	if err := s.alertDB.UpdateAlertStatus(ctx, alert.ID, alert.AlertStatus, next); err != nil {
+		logger.Errorw("alert.scanner failed to update alert status", "slug", alert.Slug, "status", next, "err", err)
+	}
+}
+
+// trigger marks given alert as triggered at given time, records an event, keeps it in the inbox of the owner
+// and subscribers if the alert is public and enqueues notifications of each action to them in a transaction.
+// The inbox keeps the event whether or not a notification is delivered.
+// The alert stays active if the transaction fails so that it is evaluated again on the next tick.
+// Subscribers with default channels are notified over the channels instead of the actions.
+// Title and body of the notifications are rendered with live values of the market.
+// The event is published to the owner over the stream hub once saved
+func (s *Scanner) trigger(ctx context.Context, now time.Time, alert *model.Alert, m Market) {
+	event := model.AlertEvent{
+		AlertID:        alert.ID,
+		TriggeredAt:    now,
+		Condition:      describeCondition(alert),
+		DeliveryStatus: model.DeliveryPending,
+	}
+	if q, err := m.Quote(alert.PairAddress); err == nil {
+		event.ObservedPrice = q.USDPrice()
+	}
+	values := templateValues(alert, m)
+	title, body := RenderTemplate(alert.Title, values), RenderTemplate(alert.Body, values)
+	err := s.alertDB.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.alertDB.TriggerAlert(ctx, alert.ID, now); err != nil {
+			return err
+		}
+		recipients := []*accountModel.Account{&alert.Account}
+		if alert.Visibility == model.VisibilityPublic {
+			subscribers, err := s.alertDB.FindSubscribers(ctx, alert.ID)
+			if err != nil {
+				return err
+			}
+			recipients = append(recipients, subscribers...)
+		}
+		var (
+			notifications []*model.Notification
+			items         []*accountModel.InboxItem
+		)
+		for i, recipient := range recipients {
+			items = append(items, &accountModel.InboxItem{
+				AccountID:     recipient.ID,
+				AlertID:       alert.ID,
+				Slug:          alert.Slug,
+				Title:         title,
+				Body:          body,
+				Condition:     event.Condition,
+				ObservedPrice: event.ObservedPrice,
+				TriggeredAt:   now,
+			})
+			actions := alert.Actions()
+			// subscribers are notified over their default channels if set
+			if channels := recipient.Preferences.Channels(); i > 0 && len(channels) > 0 {
+				actions = channels
+			}
+			for _, action := range actions {
+				notifications = append(notifications, &model.Notification{
+					AlertID:       alert.ID,
+					AccountId:     recipient.ID,
+					Action:        action,
+					Title:         title,
+					Body:          body,
+					Slug:          alert.Slug,
+					Condition:     event.Condition,
+					ObservedPrice: event.ObservedPrice,
+					TriggeredAt:   now,
+					Status:        model.NotificationPending,
+					NextAttemptAt: now,
+					Critical:      alert.Critical,
+				})
+			}
+		}
+		if len(notifications) == 0 {
+			event.DeliveryStatus = model.DeliverySent
+		}
+		if err := s.alertDB.SaveAlertEvent(ctx, &event); err != nil {
+			return err
+		}
+		for _, item := range items {
+			item.AlertEventID = event.ID
+			if err := s.accountDB.SaveInboxItem(ctx, item); err != nil {
+				return err
+			}
+		}
+		for _, n := range notifications {
+			n.AlertEventID = event.ID
+		}
+		return s.alertDB.SaveNotifications(ctx, notifications)
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorw("alert.scanner failed to trigger an alert", "slug", alert.Slug, "err", err)
+		return
+	}
+	s.hub.PublishAlert(alert.AccountId, &stream.AlertTrigger{
+		Slug:          alert.Slug,
+		Title:         title,
+		Body:          body,
+		Condition:     event.Condition,
+		ObservedPrice: event.ObservedPrice,
+		TriggeredAt:   now,
+	})
+}
+
+// describeCondition returns a snapshot of the condition of given alert such as "price below 1500"
+func describeCondition(alert *model.Alert) string {
+	if alert.AlertType == TypeExpression {
+		return alert.ConditionExpr
+	}
+	return strings.Join([]string{alert.AlertType, alert.AlertOption, alert.AlertValue}, " ")
+}
+
+// nextStatus returns the status of an alert after its condition is evaluated with given market at given time.
+// An active alert is armed and triggered if matched unless it is in cooldown,
+// and a triggered alert is armed again once the observed value moves back past the re-arm margin
+func nextStatus(alert *model.Alert, cond Condition, m Market, now time.Time) (string, error) {
+	switch alert.AlertStatus {
+	case model.StatusActive:
+		if alert.InCooldown(now) {
+			return alert.AlertStatus, nil
+		}
+		matched, err := cond.Evaluate(m)
+		if err != nil {
+			return "", err
+		}
+		if matched {
+			return model.StatusTriggered, nil
+		}
+	case model.StatusTriggered:
+		rearmed, err := cond.Rearmed(m)
+		if err != nil {
+			return "", err
+		}
+		if rearmed {
+			return model.StatusActive, nil
+		}
+	}
+	return alert.AlertStatus, nil
+}
+
+// NewScanner creates a new scanner with given config, alert db, account db, price db, stream hub and price source
+func NewScanner(cfg *config.Config, alertDB alertDB.AlertDB, accountDB accountDB.AccountDB, priceDB priceDB.PriceDB, hub *stream.Hub, source PriceSource) *Scanner {
+	batchSize, workers := cfg.AlertConfig.BatchSize, cfg.AlertConfig.Workers
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	// baselines of the longest window are looked up to twice the window ago
+	retention := time.Duration(cfg.PriceConfig.RetentionHours) * time.Hour
+	if retention > 0 && retention < minRetention {
+		retention = minRetention
+	}
+	return &Scanner{
+		alertDB:   alertDB,
+		accountDB: accountDB,
+		priceDB:   priceDB,
+		hub:       hub,
+		source:    source,
+		batchSize: uint(batchSize),
+		workers:   workers,
+		retention: retention,
+	}
+}
@@ -0,0 +1,698 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	accountDBMock "kek-backend/internal/account/database/mocks"
+	accountModel "kek-backend/internal/account/model"
+	alertDB "kek-backend/internal/alert/database"
+	alertDBMock "kek-backend/internal/alert/database/mocks"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/config"
+	"kek-backend/internal/database"
+	"kek-backend/internal/notify"
+	priceDBMock "kek-backend/internal/price/database/mocks"
+	priceModel "kek-backend/internal/price/model"
+	"kek-backend/internal/stream"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestScanner_Scan(t *testing.T) {
+	// given
+	// batch size 2 and 3 workers
+	// first batch  : alert1, alert2
+	// second batch : alert3, alert4
+	// third batch  : alert5
+	db := &alertDBMock.AlertDB{}
+	scanner := NewScanner(&config.Config{AlertConfig: config.AlertConfig{BatchSize: 2, Workers: 3}}, db, newInbox(), &priceDBMock.PriceDB{}, stream.NewHub(&config.Config{}), NewFixedSource(0, nil))
+	now := time.Now()
+	for _, batch := range []struct {
+		AfterID uint
+		IDs     []uint
+	}{
+		{AfterID: 0, IDs: []uint{1, 2}},
+		{AfterID: 2, IDs: []uint{3, 4}},
+		{AfterID: 4, IDs: []uint{5}},
+	} {
+		var alerts []*model.Alert
+		for _, id := range batch.IDs {
+			alerts = append(alerts, &model.Alert{ID: id, AlertStatus: model.StatusActive})
+		}
+		criteria := alertDB.IterateActiveAlertCriteria{AfterID: batch.AfterID, Limit: 2, Now: now}
+		db.On("FindActiveAlerts", mock.Anything, criteria).Return(alerts, nil)
+	}
+
+	// when
+	var (
+		mu                sync.Mutex
+		evaluated         []uint
+		running, maxAlive int32
+	)
+	quote := func(ctx context.Context, alerts []*model.Alert) Quotes {
+		return Quotes{}
+	}
+	err := scanner.scan(context.Background(), now, quote, func(ctx context.Context, alert *model.Alert, quotes Quotes) {
+		alive := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		mu.Lock()
+		evaluated = append(evaluated, alert.ID)
+		if alive > maxAlive {
+			maxAlive = alive
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	})
+
+	// then
+	assert.NoError(t, err)
+	db.AssertNumberOfCalls(t, "FindActiveAlerts", 3)
+	assert.ElementsMatch(t, []uint{1, 2, 3, 4, 5}, evaluated)
+	assert.LessOrEqual(t, maxAlive, int32(3))
+}
+
+func TestScanner_Scan_WithFixedSource(t *testing.T) {
+	// given
+	db := &alertDBMock.AlertDB{}
+	priceDB := &priceDBMock.PriceDB{}
+	scanner := NewScanner(&config.Config{}, db, newInbox(), priceDB, stream.NewHub(&config.Config{}), NewFixedSource(2000, cannedQuotes(t)))
+	above := newConditionAlert(wethAddress, TypePrice, OptionAbove, "1500")
+	above.ID, above.AlertStatus, above.AlertActions = 1, model.StatusActive, notify.ActionPush
+	below := newConditionAlert(usdcAddress, TypePrice, OptionBelow, "0.5")
+	below.ID, below.AlertStatus, below.AlertActions = 2, model.StatusActive, notify.ActionPush
+	onRunLocked(db, true)
+	db.On("ExpireAlerts", mock.Anything, mock.Anything).Return(int64(0), nil)
+	db.On("FindActiveAlerts", mock.Anything, mock.Anything).Return([]*model.Alert{above, below}, nil)
+	db.On("TriggerAlert", mock.Anything, above.ID, mock.Anything).Return(nil)
+	db.On("RunInTx", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(func(context.Context) error)(args.Get(0).(context.Context))
+	}).Return(nil)
+	db.On("SaveAlertEvent", mock.Anything, mock.Anything).Return(nil)
+	db.On("SaveNotifications", mock.Anything, mock.Anything).Return(nil)
+	priceDB.On("SaveSnapshots", mock.Anything, mock.Anything).Return(nil)
+	priceDB.On("FindSnapshotBefore", mock.Anything, mock.Anything, mock.Anything).Return(nil, database.ErrNotFound)
+
+	// when
+	err := scanner.Scan(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	db.AssertCalled(t, "TriggerAlert", mock.Anything, above.ID, mock.Anything)
+	db.AssertNotCalled(t, "TriggerAlert", mock.Anything, below.ID, mock.Anything)
+	db.AssertCalled(t, "SaveAlertEvent", mock.Anything, mock.MatchedBy(func(event *model.AlertEvent) bool {
+		return event.AlertID == above.ID && event.ObservedPrice == 2000
+	}))
+	priceDB.AssertCalled(t, "SaveSnapshots", mock.Anything, mock.MatchedBy(func(snapshots []*priceModel.PriceSnapshot) bool {
+		return len(snapshots) == 2
+	}))
+}
+
+// onRunLocked mocks RunLocked of given db to run the function if locked is true
+func onRunLocked(db *alertDBMock.AlertDB, locked bool) {
+	db.On("RunLocked", mock.Anything, scanLockId, mock.Anything).Return(func(ctx context.Context, lockId int64, f func(context.Context) error) bool {
+		return locked
+	}, func(ctx context.Context, lockId int64, f func(context.Context) error) error {
+		if !locked {
+			return nil
+		}
+		return f(ctx)
+	})
+}
+
+func TestScanner_Scan_SkipIfLockedByOtherInstance(t *testing.T) {
+	// given
+	db := &alertDBMock.AlertDB{}
+	priceDB := &priceDBMock.PriceDB{}
+	scanner := NewScanner(&config.Config{}, db, newInbox(), priceDB, stream.NewHub(&config.Config{}), NewFixedSource(2000, cannedQuotes(t)))
+	onRunLocked(db, false)
+
+	// when
+	err := scanner.Scan(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	db.AssertNotCalled(t, "ExpireAlerts", mock.Anything, mock.Anything)
+	db.AssertNotCalled(t, "FindActiveAlerts", mock.Anything, mock.Anything)
+	priceDB.AssertNotCalled(t, "SaveSnapshots", mock.Anything, mock.Anything)
+}
+
+// countingSource counts ETH price fetches of a price source
+type countingSource struct {
+	PriceSource
+	ethPrices int32
+}
+
+func (s *countingSource) EthPrice(ctx context.Context) (float64, error) {
+	atomic.AddInt32(&s.ethPrices, 1)
+	return s.PriceSource.EthPrice(ctx)
+}
+
+func TestScanner_Scan_FetchEthPriceOncePerTick(t *testing.T) {
+	// given
+	db := &alertDBMock.AlertDB{}
+	priceDB := &priceDBMock.PriceDB{}
+	source := &countingSource{PriceSource: NewFixedSource(2000, cannedQuotes(t))}
+	scanner := NewScanner(&config.Config{AlertConfig: config.AlertConfig{BatchSize: 1, Workers: 1}}, db, newInbox(), priceDB, stream.NewHub(&config.Config{}), source)
+	weth := newConditionAlert(wethAddress, TypePrice, OptionAbove, "5000")
+	weth.ID, weth.AlertStatus = 1, model.StatusActive
+	usdc := newConditionAlert(usdcAddress, TypePrice, OptionAbove, "5000")
+	usdc.ID, usdc.AlertStatus = 2, model.StatusActive
+	onRunLocked(db, true)
+	db.On("ExpireAlerts", mock.Anything, mock.Anything).Return(int64(0), nil)
+	db.On("FindActiveAlerts", mock.Anything, mock.MatchedBy(func(c alertDB.IterateActiveAlertCriteria) bool { return c.AfterID == 0 })).Return([]*model.Alert{weth}, nil)
+	db.On("FindActiveAlerts", mock.Anything, mock.MatchedBy(func(c alertDB.IterateActiveAlertCriteria) bool { return c.AfterID == 1 })).Return([]*model.Alert{usdc}, nil)
+	db.On("FindActiveAlerts", mock.Anything, mock.MatchedBy(func(c alertDB.IterateActiveAlertCriteria) bool { return c.AfterID == 2 })).Return([]*model.Alert{}, nil)
+	priceDB.On("SaveSnapshots", mock.Anything, mock.Anything).Return(nil)
+
+	// when
+	err := scanner.Scan(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	priceDB.AssertNumberOfCalls(t, "SaveSnapshots", 2)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&source.ethPrices))
+}
+
+func TestScanner_Scan_FailIfDBError(t *testing.T) {
+	// given
+	db := &alertDBMock.AlertDB{}
+	scanner := NewScanner(&config.Config{AlertConfig: config.AlertConfig{BatchSize: 2, Workers: 2}}, db, newInbox(), &priceDBMock.PriceDB{}, stream.NewHub(&config.Config{}), NewFixedSource(0, nil))
+	dbErr := errors.New("db error")
+	db.On("FindActiveAlerts", mock.Anything, mock.Anything).Return(nil, dbErr)
+
+	// when
+	err := scanner.scan(context.Background(), time.Now(), func(ctx context.Context, alerts []*model.Alert) Quotes {
+		t.Fatal("must not quote")
+		return nil
+	}, func(ctx context.Context, alert *model.Alert, quotes Quotes) {
+		t.Fatal("must not evaluate")
+	})
+
+	// then
+	assert.Equal(t, dbErr, err)
+}
+
+func TestQuoteCache(t *testing.T) {
+	// given
+	var fetches [][]string
+	cache := quoteCache{
+		fetch: func(ctx context.Context, addresses []string) Quotes {
+			fetches = append(fetches, addresses)
+			quotes := make(Quotes)
+			for _, address := range addresses {
+				// unknown token is not returned
+				if address != "0x3" {
+					quotes[address] = &Quote{Address: address}
+				}
+			}
+			return quotes
+		},
+		fetched: make(Quotes),
+	}
+	first := []*model.Alert{{PairAddress: "0x1"}, {PairAddress: "0x2"}, {PairAddress: "0X1"}}
+	second := []*model.Alert{{PairAddress: "0x2"}, {PairAddress: "0x3"}, {PairAddress: "0x2"}}
+
+	// when
+	firstQuotes := cache.quotes(context.Background(), first)
+	secondQuotes := cache.quotes(context.Background(), second)
+
+	// then
+	assert.Equal(t, [][]string{{"0x1", "0x2"}, {"0x3"}}, fetches)
+	assert.Len(t, firstQuotes, 2)
+	assert.Contains(t, firstQuotes, "0x1")
+	assert.Contains(t, firstQuotes, "0x2")
+	assert.Len(t, secondQuotes, 1)
+	assert.Same(t, firstQuotes["0x2"], secondQuotes["0x2"])
+}
+
+func TestEthPriceCache(t *testing.T) {
+	// given
+	var fetches int
+	cache := ethPriceCache{fetch: func(ctx context.Context) (float64, error) {
+		fetches++
+		return 0, errors.New("bundle error")
+	}}
+
+	// when
+	_, err1 := cache.ethPrice(context.Background())
+	_, err2 := cache.ethPrice(context.Background())
+
+	// then
+	assert.EqualError(t, err1, "bundle error")
+	assert.EqualError(t, err2, "bundle error")
+	assert.Equal(t, 1, fetches)
+}
+
+func TestAlertAddresses(t *testing.T) {
+	price := newConditionAlert("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", TypePrice, OptionAbove, "1")
+	expression := newConditionAlert("", TypeExpression, "", "")
+	expression.ConditionExpr = "price(" + usdcAddress + ") < 1 OR change(" + wethAddress + ", 1h) < -10"
+
+	assert.Equal(t, []string{wethAddress}, alertAddresses(price))
+	assert.Equal(t, []string{usdcAddress, wethAddress}, alertAddresses(expression))
+}
+
+func TestScanner_SaveSnapshots(t *testing.T) {
+	// given
+	priceDB := &priceDBMock.PriceDB{}
+	scanner := NewScanner(&config.Config{}, &alertDBMock.AlertDB{}, newInbox(), priceDB, stream.NewHub(&config.Config{}), NewFixedSource(0, nil))
+	priceDB.On("SaveSnapshots", mock.Anything, mock.Anything).Return(nil)
+	now := time.Now()
+
+	// when
+	scanner.saveSnapshots(context.Background(), now, cannedQuotes(t))
+
+	// then
+	priceDB.AssertCalled(t, "SaveSnapshots", mock.Anything, mock.MatchedBy(func(snapshots []*priceModel.PriceSnapshot) bool {
+		if len(snapshots) != 2 {
+			return false
+		}
+		for _, s := range snapshots {
+			if s.TokenAddress == wethAddress && (s.USDPrice != 2000 || s.EthPrice != 2000 || s.TotalLiquidity != 150000) {
+				return false
+			}
+			if !s.ObservedAt.Equal(now) {
+				return false
+			}
+		}
+		return true
+	}))
+}
+
+func TestScanner_Prune(t *testing.T) {
+	// given
+	priceDB := &priceDBMock.PriceDB{}
+	cfg := &config.Config{PriceConfig: config.PriceConfig{RetentionHours: 1}}
+	scanner := NewScanner(cfg, &alertDBMock.AlertDB{}, newInbox(), priceDB, stream.NewHub(&config.Config{}), NewFixedSource(0, nil))
+	priceDB.On("DeleteSnapshotsBefore", mock.Anything, mock.Anything).Return(int64(3), nil)
+
+	// when
+	err := scanner.Prune(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	// retention shorter than baselines of the longest window is raised
+	priceDB.AssertCalled(t, "DeleteSnapshotsBefore", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before)-minRetention < time.Minute
+	}))
+}
+
+func TestScanner_Prune_Disabled(t *testing.T) {
+	// given
+	priceDB := &priceDBMock.PriceDB{}
+	scanner := NewScanner(&config.Config{}, &alertDBMock.AlertDB{}, newInbox(), priceDB, stream.NewHub(&config.Config{}), NewFixedSource(0, nil))
+
+	// when
+	err := scanner.Prune(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	priceDB.AssertNotCalled(t, "DeleteSnapshotsBefore", mock.Anything, mock.Anything)
+}
+
+func TestScanner_PublishPrices(t *testing.T) {
+	// given
+	hub := stream.NewHub(&config.Config{})
+	sub := hub.Subscribe(1, []string{strings.ToUpper(wethAddress)})
+	scanner := NewScanner(&config.Config{}, &alertDBMock.AlertDB{}, newInbox(), &priceDBMock.PriceDB{}, hub, NewFixedSource(0, nil))
+	now := time.Now()
+
+	// when
+	scanner.publishPrices(now, cannedQuotes(t))
+
+	// then
+	assert.Len(t, sub.Events(), 1)
+	e := <-sub.Events()
+	assert.Equal(t, stream.EventPrice, e.Type)
+	assert.Equal(t, &stream.PriceTick{Address: wethAddress, Symbol: "WETH", USDPrice: 2000, EthPrice: 2000, ObservedAt: now}, e.Data)
+}
+
+func TestScanner_FindBaseline(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		Name       string
+		ObservedAt time.Time
+		Err        error
+		Expected   float64
+		ExpectErr  error
+	}{
+		{Name: "snapshot at the window ago", ObservedAt: now.Add(-time.Hour), Expected: 1900},
+		{Name: "snapshot within twice the window", ObservedAt: now.Add(-2 * time.Hour), Expected: 1900},
+		{Name: "snapshot older than twice the window", ObservedAt: now.Add(-2*time.Hour - time.Second), ExpectErr: ErrNoBaseline},
+		{Name: "no snapshot", Err: database.ErrNotFound, ExpectErr: ErrNoBaseline},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// given
+			priceDB := &priceDBMock.PriceDB{}
+			scanner := NewScanner(&config.Config{}, &alertDBMock.AlertDB{}, newInbox(), priceDB, stream.NewHub(&config.Config{}), NewFixedSource(0, nil))
+			if tc.Err != nil {
+				priceDB.On("FindSnapshotBefore", mock.Anything, wethAddress, now.Add(-time.Hour)).Return(nil, tc.Err)
+			} else {
+				snapshot := priceModel.PriceSnapshot{TokenAddress: wethAddress, USDPrice: 1900, ObservedAt: tc.ObservedAt}
+				priceDB.On("FindSnapshotBefore", mock.Anything, wethAddress, now.Add(-time.Hour)).Return(&snapshot, nil)
+			}
+
+			// when
+			baseline, err := scanner.findBaseline(context.Background(), wethAddress, now, time.Hour)
+
+			// then
+			assert.Equal(t, tc.ExpectErr, err)
+			assert.Equal(t, tc.Expected, baseline)
+		})
+	}
+}
+
+func TestBaselineCache(t *testing.T) {
+	// given
+	var lookups int32
+	cache := baselineCache{
+		lookup: func(address string, window time.Duration) (float64, error) {
+			atomic.AddInt32(&lookups, 1)
+			if window == time.Hour {
+				return 0, ErrNoBaseline
+			}
+			return 1900, nil
+		},
+		prices: make(map[baselineKey]baselineResult),
+	}
+	m := market{Quotes: Quotes{}, baselines: &cache}
+
+	// when
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			baseline, err := m.Baseline(wethAddress, 5*time.Minute)
+			assert.NoError(t, err)
+			assert.Equal(t, 1900.0, baseline)
+			_, err = m.Baseline(wethAddress, time.Hour)
+			assert.Equal(t, ErrNoBaseline, err)
+		}()
+	}
+	wg.Wait()
+
+	// then
+	assert.Equal(t, int32(2), lookups)
+}
+
+func TestNextStatus(t *testing.T) {
+	now := time.Now()
+	recently, longAgo := now.Add(-30*time.Second), now.Add(-2*time.Minute)
+	// weth is 2000
+	cases := []struct {
+		Name            string
+		Status          string
+		Option          string
+		Value           string
+		RearmMargin     float64
+		CooldownSecs    int64
+		LastTriggeredAt *time.Time
+		Expected        string
+	}{
+		{Name: "active is triggered if matched", Status: model.StatusActive, Option: OptionAbove, Value: "1500", Expected: model.StatusTriggered},
+		{Name: "active stays if not matched", Status: model.StatusActive, Option: OptionAbove, Value: "2500", Expected: model.StatusActive},
+		{Name: "active stays in cooldown", Status: model.StatusActive, Option: OptionAbove, Value: "1500", CooldownSecs: 60, LastTriggeredAt: &recently, Expected: model.StatusActive},
+		{Name: "active is triggered after cooldown", Status: model.StatusActive, Option: OptionAbove, Value: "1500", CooldownSecs: 60, LastTriggeredAt: &longAgo, Expected: model.StatusTriggered},
+		{Name: "triggered stays if matched", Status: model.StatusTriggered, Option: OptionAbove, Value: "1500", Expected: model.StatusTriggered},
+		{Name: "triggered is rearmed if not matched without margin", Status: model.StatusTriggered, Option: OptionAbove, Value: "2001", Expected: model.StatusActive},
+		{Name: "triggered stays within margin", Status: model.StatusTriggered, Option: OptionAbove, Value: "2001", RearmMargin: 10, Expected: model.StatusTriggered},
+		{Name: "triggered is rearmed out of margin", Status: model.StatusTriggered, Option: OptionAbove, Value: "2020", RearmMargin: 10, Expected: model.StatusActive},
+		{Name: "paused stays", Status: model.StatusPaused, Option: OptionAbove, Value: "1500", Expected: model.StatusPaused},
+	}
+
+	quotes := cannedQuotes(t)
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			alert := newConditionAlert(wethAddress, TypePrice, tc.Option, tc.Value)
+			alert.AlertStatus = tc.Status
+			alert.RearmMargin = tc.RearmMargin
+			alert.CooldownSecs = tc.CooldownSecs
+			alert.LastTriggeredAt = tc.LastTriggeredAt
+			cond, err := ParseCondition(alert)
+			assert.NoError(t, err)
+
+			next, err := nextStatus(alert, cond, &testMarket{Quotes: quotes}, now)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expected, next)
+		})
+	}
+}
+
+func TestScanner_Evaluate(t *testing.T) {
+	// given
+	db := &alertDBMock.AlertDB{}
+	scanner := NewScanner(&config.Config{}, db, newInbox(), &priceDBMock.PriceDB{}, stream.NewHub(&config.Config{}), NewFixedSource(0, nil))
+	now := time.Now()
+	triggered := newConditionAlert(wethAddress, TypePrice, OptionAbove, "1500")
+	triggered.ID, triggered.AlertStatus = 1, model.StatusTriggered
+	rearmed := newConditionAlert(wethAddress, TypePrice, OptionAbove, "2500")
+	rearmed.ID, rearmed.AlertStatus = 2, model.StatusTriggered
+	db.On("UpdateAlertStatus", mock.Anything, uint(2), model.StatusTriggered, model.StatusActive).Return(nil)
+
+	// when
+	m := testMarket{Quotes: cannedQuotes(t)}
+	scanner.evaluate(context.Background(), now, triggered, &m)
+	scanner.evaluate(context.Background(), now, rearmed, &m)
+
+	// then
+	db.AssertNotCalled(t, "TriggerAlert", mock.Anything, mock.Anything, mock.Anything)
+	db.AssertNotCalled(t, "UpdateAlertStatus", mock.Anything, uint(1), mock.Anything, mock.Anything)
+	db.AssertCalled(t, "UpdateAlertStatus", mock.Anything, uint(2), model.StatusTriggered, model.StatusActive)
+}
+
+func TestScanner_Trigger(t *testing.T) {
+	// given
+	db := &alertDBMock.AlertDB{}
+	hub := stream.NewHub(&config.Config{})
+	sub := hub.Subscribe(1, nil)
+	scanner := NewScanner(&config.Config{}, db, newInbox(), &priceDBMock.PriceDB{}, hub, NewFixedSource(0, nil))
+	now := time.Now()
+	alert := newConditionAlert(wethAddress, TypePrice, OptionAbove, "1500")
+	alert.ID, alert.AlertStatus, alert.AlertActions = 1, model.StatusActive, "push,webhook"
+	alert.Title, alert.Body = "{{symbol}} above {{threshold}}", "{{symbol}} is {{price}} USD"
+	alert.Account, alert.AccountId = accountModel.Account{ID: 1, Username: "owner"}, 1
+	db.On("TriggerAlert", mock.Anything, alert.ID, now).Return(nil)
+	db.On("RunInTx", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(func(context.Context) error)(args.Get(0).(context.Context))
+	}).Return(nil)
+	db.On("SaveAlertEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.AlertEvent).ID = 10
+	}).Return(nil)
+	db.On("SaveNotifications", mock.Anything, mock.Anything).Return(nil)
+
+	// when
+	scanner.evaluate(context.Background(), now, alert, &testMarket{Quotes: cannedQuotes(t)})
+
+	// then
+	db.AssertCalled(t, "SaveAlertEvent", mock.Anything, mock.MatchedBy(func(event *model.AlertEvent) bool {
+		return event.AlertID == alert.ID && event.TriggeredAt.Equal(now) && event.ObservedPrice == 2000 &&
+			event.Condition == "price above 1500" && event.DeliveryStatus == model.DeliveryPending
+	}))
+	db.AssertNotCalled(t, "FindSubscribers", mock.Anything, mock.Anything)
+	db.AssertCalled(t, "SaveNotifications", mock.Anything, mock.MatchedBy(func(notifications []*model.Notification) bool {
+		if len(notifications) != 2 || notifications[0].Action != "push" || notifications[1].Action != "webhook" {
+			return false
+		}
+		for _, n := range notifications {
+			if n.AlertEventID != 10 || n.AlertID != alert.ID || n.AccountId != alert.Account.ID ||
+				n.Title != "WETH above 1500" || n.Body != "WETH is 2000 USD" || n.Slug != alert.Slug || n.Condition != "price above 1500" || n.ObservedPrice != 2000 ||
+				n.Status != model.NotificationPending || !n.NextAttemptAt.Equal(now) || !n.TriggeredAt.Equal(now) {
+				return false
+			}
+		}
+		return true
+	}))
+	assert.Len(t, sub.Events(), 1)
+	e := <-sub.Events()
+	assert.Equal(t, stream.EventAlert, e.Type)
+	assert.Equal(t, &stream.AlertTrigger{
+		Slug:          alert.Slug,
+		Title:         "WETH above 1500",
+		Body:          "WETH is 2000 USD",
+		Condition:     "price above 1500",
+		ObservedPrice: 2000,
+		TriggeredAt:   now,
+	}, e.Data)
+}
+
+func TestScanner_Trigger_RollbackIfSaveFailed(t *testing.T) {
+	// given
+	db := &alertDBMock.AlertDB{}
+	hub := stream.NewHub(&config.Config{})
+	sub := hub.Subscribe(1, nil)
+	scanner := NewScanner(&config.Config{}, db, newInbox(), &priceDBMock.PriceDB{}, hub, NewFixedSource(0, nil))
+	now := time.Now()
+	alert := newConditionAlert(wethAddress, TypePrice, OptionAbove, "1500")
+	alert.ID, alert.AlertStatus, alert.AlertActions = 1, model.StatusActive, notify.ActionPush
+	alert.Account, alert.AccountId = accountModel.Account{ID: 1, Username: "owner"}, 1
+	type txKey struct{}
+	inTx := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value(txKey{}) != nil })
+	db.On("RunInTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, f func(context.Context) error) error {
+		return f(context.WithValue(ctx, txKey{}, true))
+	})
+	db.On("TriggerAlert", inTx, alert.ID, now).Return(nil)
+	db.On("SaveAlertEvent", inTx, mock.Anything).Return(nil)
+	db.On("SaveNotifications", inTx, mock.Anything).Return(errors.New("insert failed"))
+
+	// when
+	scanner.evaluate(context.Background(), now, alert, &testMarket{Quotes: cannedQuotes(t)})
+
+	// then
+	db.AssertCalled(t, "TriggerAlert", inTx, alert.ID, now)
+	db.AssertNotCalled(t, "UpdateAlertStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.Len(t, sub.Events(), 0)
+}
+
+func TestScanner_Trigger_FanOutToSubscribers(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Visibility string
+		Recipients []uint
+	}{
+		{Name: "public", Visibility: model.VisibilityPublic, Recipients: []uint{1, 2, 3}},
+		{Name: "private", Visibility: model.VisibilityPrivate, Recipients: []uint{1}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// given
+			db := &alertDBMock.AlertDB{}
+			scanner := NewScanner(&config.Config{}, db, newInbox(), &priceDBMock.PriceDB{}, stream.NewHub(&config.Config{}), NewFixedSource(0, nil))
+			alert := newConditionAlert(wethAddress, TypePrice, OptionAbove, "1500")
+			alert.ID, alert.Visibility, alert.AlertActions = 1, tc.Visibility, notify.ActionPush
+			alert.Account = accountModel.Account{ID: 1, Username: "owner"}
+			db.On("TriggerAlert", mock.Anything, alert.ID, mock.Anything).Return(nil)
+			db.On("RunInTx", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				args.Get(1).(func(context.Context) error)(args.Get(0).(context.Context))
+			}).Return(nil)
+			db.On("FindSubscribers", mock.Anything, alert.ID).Return([]*accountModel.Account{
+				{ID: 2, Username: "sub1"},
+				{ID: 3, Username: "sub2"},
+			}, nil)
+			db.On("SaveAlertEvent", mock.Anything, mock.Anything).Return(nil)
+			var recipients []uint
+			db.On("SaveNotifications", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				for _, n := range args.Get(1).([]*model.Notification) {
+					recipients = append(recipients, n.AccountId)
+				}
+			}).Return(nil)
+
+			// when
+			scanner.trigger(context.Background(), time.Now(), alert, &testMarket{Quotes: cannedQuotes(t)})
+
+			// then
+			assert.Equal(t, tc.Recipients, recipients)
+		})
+	}
+}
+
+func TestScanner_Trigger_SubscriberDefaultChannels(t *testing.T) {
+	// given
+	db := &alertDBMock.AlertDB{}
+	scanner := NewScanner(&config.Config{}, db, newInbox(), &priceDBMock.PriceDB{}, stream.NewHub(&config.Config{}), NewFixedSource(0, nil))
+	alert := newConditionAlert(wethAddress, TypePrice, OptionAbove, "1500")
+	alert.ID, alert.Visibility, alert.AlertActions, alert.Critical = 1, model.VisibilityPublic, notify.ActionPush, true
+	alert.Account = accountModel.Account{ID: 1, Username: "owner", Preferences: accountModel.Preferences{DefaultChannels: "email"}}
+	db.On("TriggerAlert", mock.Anything, alert.ID, mock.Anything).Return(nil)
+	db.On("RunInTx", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(func(context.Context) error)(args.Get(0).(context.Context))
+	}).Return(nil)
+	db.On("FindSubscribers", mock.Anything, alert.ID).Return([]*accountModel.Account{
+		{ID: 2, Username: "sub1", Preferences: accountModel.Preferences{DefaultChannels: "email,push"}},
+		{ID: 3, Username: "sub2"},
+	}, nil)
+	db.On("SaveAlertEvent", mock.Anything, mock.Anything).Return(nil)
+	var actions []string
+	db.On("SaveNotifications", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		for _, n := range args.Get(1).([]*model.Notification) {
+			assert.True(t, n.Critical)
+			actions = append(actions, fmt.Sprintf("%d:%s", n.AccountId, n.Action))
+		}
+	}).Return(nil)
+
+	// when
+	scanner.trigger(context.Background(), time.Now(), alert, &testMarket{Quotes: cannedQuotes(t)})
+
+	// then
+	assert.Equal(t, []string{"1:push", "2:email", "2:push", "3:push"}, actions)
+}
+
+func TestScanner_Trigger_KeepInInbox(t *testing.T) {
+	// given
+	db := &alertDBMock.AlertDB{}
+	inbox := &accountDBMock.AccountDB{}
+	scanner := NewScanner(&config.Config{}, db, inbox, &priceDBMock.PriceDB{}, stream.NewHub(&config.Config{}), NewFixedSource(0, nil))
+	now := time.Now()
+	// no action, so that no notification is delivered to anyone
+	alert := newConditionAlert(wethAddress, TypePrice, OptionAbove, "1500")
+	alert.ID, alert.Visibility, alert.Title = 1, model.VisibilityPublic, "{{symbol}} above {{threshold}}"
+	alert.Account = accountModel.Account{ID: 1, Username: "owner"}
+	db.On("TriggerAlert", mock.Anything, alert.ID, now).Return(nil)
+	db.On("RunInTx", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(func(context.Context) error)(args.Get(0).(context.Context))
+	}).Return(nil)
+	db.On("FindSubscribers", mock.Anything, alert.ID).Return([]*accountModel.Account{{ID: 2, Username: "sub1"}}, nil)
+	db.On("SaveAlertEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.AlertEvent).ID = 10
+	}).Return(nil)
+	db.On("SaveNotifications", mock.Anything, mock.Anything).Return(nil)
+	var items []*accountModel.InboxItem
+	inbox.On("SaveInboxItem", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		items = append(items, args.Get(1).(*accountModel.InboxItem))
+	}).Return(nil)
+
+	// when
+	scanner.trigger(context.Background(), now, alert, &testMarket{Quotes: cannedQuotes(t)})
+
+	// then
+	assert.Len(t, items, 2)
+	for i, item := range items {
+		assert.Equal(t, uint(i+1), item.AccountID)
+		assert.Equal(t, uint(10), item.AlertEventID)
+		assert.Equal(t, alert.ID, item.AlertID)
+		assert.Equal(t, "WETH above 1500", item.Title)
+		assert.Equal(t, "price above 1500", item.Condition)
+		assert.Equal(t, 2000.0, item.ObservedPrice)
+		assert.True(t, now.Equal(item.TriggeredAt))
+	}
+}
+
+func TestScanner_Trigger_NoAction(t *testing.T) {
+	// given
+	db := &alertDBMock.AlertDB{}
+	scanner := NewScanner(&config.Config{}, db, newInbox(), &priceDBMock.PriceDB{}, stream.NewHub(&config.Config{}), NewFixedSource(0, nil))
+	alert := newConditionAlert(wethAddress, TypePrice, OptionAbove, "1500")
+	alert.ID = 1
+	db.On("TriggerAlert", mock.Anything, alert.ID, mock.Anything).Return(nil)
+	db.On("RunInTx", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(func(context.Context) error)(args.Get(0).(context.Context))
+	}).Return(nil)
+	db.On("SaveAlertEvent", mock.Anything, mock.Anything).Return(nil)
+	db.On("SaveNotifications", mock.Anything, mock.Anything).Return(nil)
+
+	// when
+	scanner.trigger(context.Background(), time.Now(), alert, &testMarket{Quotes: cannedQuotes(t)})
+
+	// then
+	db.AssertCalled(t, "SaveAlertEvent", mock.Anything, mock.MatchedBy(func(event *model.AlertEvent) bool {
+		return event.DeliveryStatus == model.DeliverySent
+	}))
+}
+
+// newInbox returns an account db keeping inbox items
+func newInbox() *accountDBMock.AccountDB {
+	inbox := &accountDBMock.AccountDB{}
+	inbox.On("SaveInboxItem", mock.Anything, mock.Anything).Return(nil)
+	return inbox
+}
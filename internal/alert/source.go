@@ -0,0 +1,150 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"kek-backend/internal/config"
+	"kek-backend/internal/uniswap"
+	"kek-backend/pkg/logging"
+
+	"github.com/pkg/errors"
+)
+
+// price sources
+const (
+	SourceUniswap = "uniswap"
+	SourceFixed   = "fixed"
+)
+
+// PriceSource provides quotes of tokens to the alert engine.
+// The ETH price is fetched apart from quotes so that a tick fetches it once for all batches
+type PriceSource interface {
+	// EthPrice returns the USD price of ETH
+	EthPrice(ctx context.Context) (float64, error)
+
+	// Quotes returns quotes of tokens with given addresses priced with given ETH price.
+	// Tokens failed to quote are not included
+	Quotes(ctx context.Context, ethPrice float64, addresses []string) (Quotes, error)
+}
+
+// uniswapSource is a PriceSource of the uniswap v2 subgraph
+type uniswapSource struct {
+	client *uniswap.Client
+}
+
+// EthPrice fetches the ETH price of the bundle
+func (s *uniswapSource) EthPrice(ctx context.Context) (float64, error) {
+	bundles, err := s.client.Bundles(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "fetch bundles")
+	}
+	return ParseEthPrice(bundles)
+}
+
+// Quotes fetches quotes of given tokens in chunks of uniswap.MaxTokensPerQuery.
+// Tokens failed to fetch are not included
+func (s *uniswapSource) Quotes(ctx context.Context, ethPrice float64, addresses []string) (Quotes, error) {
+	logger := logging.FromContext(ctx)
+	quotes := make(Quotes, len(addresses))
+	for i := 0; i < len(addresses); i += uniswap.MaxTokensPerQuery {
+		last := i + uniswap.MaxTokensPerQuery
+		if last > len(addresses) {
+			last = len(addresses)
+		}
+		tokens, err := s.client.Tokens(ctx, addresses[i:last])
+		if err != nil {
+			logger.Errorw("alert.source failed to fetch tokens", "addresses", addresses[i:last], "err", err)
+			continue
+		}
+		for _, token := range tokens.Tokens {
+			q, err := NewQuote(ethPrice, token)
+			if err != nil {
+				logger.Errorw("alert.source failed to parse token", "address", token.Id, "err", err)
+				continue
+			}
+			quotes[q.Address] = q
+		}
+	}
+	return quotes, nil
+}
+
+// FixedSource is an in-memory PriceSource of a fixed ETH price and fixed quotes.
+// It is safe for concurrent use
+type FixedSource struct {
+	mu       sync.RWMutex
+	ethPrice float64
+	quotes   Quotes
+}
+
+// EthPrice returns the fixed ETH price
+func (s *FixedSource) EthPrice(_ context.Context) (float64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ethPrice, nil
+}
+
+// SetEthPrice replaces the fixed ETH price
+func (s *FixedSource) SetEthPrice(ethPrice float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ethPrice = ethPrice
+}
+
+// Quotes returns copies of known quotes of given tokens priced with given ETH price
+func (s *FixedSource) Quotes(_ context.Context, ethPrice float64, addresses []string) (Quotes, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	quotes := make(Quotes, len(addresses))
+	for _, address := range addresses {
+		if q, err := s.quotes.Quote(address); err == nil {
+			copied := *q
+			copied.EthPrice = ethPrice
+			quotes[copied.Address] = &copied
+		}
+	}
+	return quotes, nil
+}
+
+// SetQuote adds or replaces the quote of the token of given quote
+func (s *FixedSource) SetQuote(q *Quote) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *q
+	copied.Address = strings.ToLower(copied.Address)
+	s.quotes[copied.Address] = &copied
+}
+
+// NewFixedSource creates a new fixed source of given ETH price and quotes
+func NewFixedSource(ethPrice float64, quotes Quotes) *FixedSource {
+	s := &FixedSource{ethPrice: ethPrice, quotes: make(Quotes, len(quotes))}
+	for _, q := range quotes {
+		s.SetQuote(q)
+	}
+	return s
+}
+
+// NewPriceSource creates the price source selected by given config
+func NewPriceSource(cfg *config.Config) (PriceSource, error) {
+	switch source := cfg.PriceConfig.Source; source {
+	case "", SourceUniswap:
+		return &uniswapSource{client: uniswap.NewClient(cfg.PriceConfig.Uniswap)}, nil
+	case SourceFixed:
+		fixed := cfg.PriceConfig.Fixed
+		quotes := make(Quotes, len(fixed.Tokens))
+		for address, token := range fixed.Tokens {
+			quotes[address] = &Quote{
+				Address:        address,
+				Name:           token.Name,
+				Symbol:         token.Symbol,
+				DerivedETH:     token.DerivedETH,
+				TotalLiquidity: token.TotalLiquidity,
+			}
+		}
+		return NewFixedSource(fixed.EthPrice, quotes), nil
+	default:
+		return nil, fmt.Errorf("unknown price source %q, must be one of %s, %s", source, SourceFixed, SourceUniswap)
+	}
+}
@@ -0,0 +1,133 @@
+package alert
+
+import (
+	"context"
+	"io/ioutil"
+	"kek-backend/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedSource(t *testing.T) {
+	// given
+	source := NewFixedSource(2000, cannedQuotes(t))
+
+	// when
+	ethPrice, err := source.EthPrice(context.Background())
+	assert.NoError(t, err)
+	quotes, err := source.Quotes(context.Background(), ethPrice, []string{"0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", "0x0000000000000000000000000000000000000000"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, quotes, 1)
+	weth, err := quotes.Quote(wethAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, 2000.0, weth.USDPrice())
+
+	// returned quotes are copies
+	weth.DerivedETH = 2
+	source.SetQuote(&Quote{Address: "0xA0B86991C6218B36C1D19D4A2E9EB0CE3606EB48", Symbol: "USDC", DerivedETH: 0.001})
+	quotes, err = source.Quotes(context.Background(), ethPrice, []string{wethAddress, usdcAddress})
+	assert.NoError(t, err)
+	assert.Equal(t, 2000.0, quotes[wethAddress].USDPrice())
+	assert.Equal(t, 2.0, quotes[usdcAddress].USDPrice())
+
+	// quotes are priced with given ETH price
+	source.SetEthPrice(3000)
+	ethPrice, err = source.EthPrice(context.Background())
+	assert.NoError(t, err)
+	quotes, err = source.Quotes(context.Background(), ethPrice, []string{wethAddress})
+	assert.NoError(t, err)
+	assert.Equal(t, 3000.0, quotes[wethAddress].USDPrice())
+}
+
+func TestUniswapSource_Quotes(t *testing.T) {
+	// given
+	var bundleRequests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), "bundles") {
+			atomic.AddInt32(&bundleRequests, 1)
+			w.Write([]byte(bundlesPayload))
+			return
+		}
+		w.Write([]byte(tokensPayload))
+	}))
+	defer srv.Close()
+	source, err := NewPriceSource(&config.Config{PriceConfig: config.PriceConfig{
+		Source:  SourceUniswap,
+		Uniswap: config.UniswapConfig{URL: srv.URL, TimeoutSecs: 1},
+	}})
+	assert.NoError(t, err)
+
+	// when
+	ethPrice, err := source.EthPrice(context.Background())
+	assert.NoError(t, err)
+	quotes, err := source.Quotes(context.Background(), ethPrice, []string{wethAddress, usdcAddress})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&bundleRequests))
+	assert.Len(t, quotes, 2)
+	assert.Equal(t, 2000.0, quotes[wethAddress].USDPrice())
+	assert.Equal(t, 1.0, quotes[usdcAddress].USDPrice())
+}
+
+func TestUniswapSource_FailIfGraphQLErrors(t *testing.T) {
+	// given
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors":[{"message":"indexing error"}]}`))
+	}))
+	defer srv.Close()
+	source, err := NewPriceSource(&config.Config{PriceConfig: config.PriceConfig{
+		Uniswap: config.UniswapConfig{URL: srv.URL, TimeoutSecs: 1},
+	}})
+	assert.NoError(t, err)
+
+	// when
+	ethPrice, err := source.EthPrice(context.Background())
+
+	// then
+	assert.Equal(t, 0.0, ethPrice)
+	assert.EqualError(t, err, "fetch bundles: graphql: indexing error")
+}
+
+func TestNewPriceSource(t *testing.T) {
+	// uniswap by default
+	source, err := NewPriceSource(&config.Config{})
+	assert.NoError(t, err)
+	assert.IsType(t, &uniswapSource{}, source)
+
+	// fixed
+	source, err = NewPriceSource(&config.Config{PriceConfig: config.PriceConfig{
+		Source: SourceFixed,
+		Fixed: config.FixedPriceConfig{
+			EthPrice: 2000,
+			Tokens: map[string]config.FixedTokenConfig{
+				wethAddress: {Name: "Wrapped Ether", Symbol: "WETH", DerivedETH: 1, TotalLiquidity: 150000},
+			},
+		},
+	}})
+	assert.NoError(t, err)
+	ethPrice, err := source.EthPrice(context.Background())
+	assert.NoError(t, err)
+	quotes, err := source.Quotes(context.Background(), ethPrice, []string{wethAddress})
+	assert.NoError(t, err)
+	assert.Equal(t, &Quote{
+		Address:        wethAddress,
+		Name:           "Wrapped Ether",
+		Symbol:         "WETH",
+		DerivedETH:     1,
+		EthPrice:       2000,
+		TotalLiquidity: 150000,
+	}, quotes[wethAddress])
+
+	// unknown
+	_, err = NewPriceSource(&config.Config{PriceConfig: config.PriceConfig{Source: "binance"}})
+	assert.EqualError(t, err, `unknown price source "binance", must be one of fixed, uniswap`)
+}
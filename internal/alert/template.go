@@ -0,0 +1,117 @@
+package alert
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kek-backend/internal/alert/model"
+
+	"github.com/pkg/errors"
+)
+
+// placeholders of alert title and body templates such as "{{symbol}} is {{price}} USD",
+// rendered with live values when the alert is triggered
+const (
+	// PlaceholderSymbol is the symbol of the token
+	PlaceholderSymbol = "symbol"
+	// PlaceholderPrice is the USD price of the token
+	PlaceholderPrice = "price"
+	// PlaceholderThreshold is the alert value without tolerance
+	PlaceholderThreshold = "threshold"
+	// PlaceholderChange is the USD price change of the token in percent over the window of
+	// a percent change alert, otherwise over 24 hours
+	PlaceholderChange = "change"
+	// PlaceholderPair is the address of the token
+	PlaceholderPair = "pair"
+
+	// missingValue is rendered for a placeholder without a live value
+	missingValue = "n/a"
+
+	// MaxTitleLength is the maximum number of characters of a title template as stored in alerts.
+	// Rendered titles are not limited since placeholders expand to live values
+	MaxTitleLength = 100
+)
+
+var (
+	placeholders = map[string]bool{
+		PlaceholderSymbol:    true,
+		PlaceholderPrice:     true,
+		PlaceholderThreshold: true,
+		PlaceholderChange:    true,
+		PlaceholderPair:      true,
+	}
+	placeholderPattern = regexp.MustCompile(`\{\{\s*(\w*)\s*\}\}`)
+)
+
+// ValidateTemplate returns an error if given template has an unknown or unclosed placeholder
+func ValidateTemplate(tmpl string) error {
+	for _, m := range placeholderPattern.FindAllStringSubmatch(tmpl, -1) {
+		if !placeholders[m[1]] {
+			var names []string
+			for name := range placeholders {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown placeholder %s, must be one of %s", m[0], strings.Join(names, ", "))
+		}
+	}
+	if strings.Contains(placeholderPattern.ReplaceAllString(tmpl, ""), "{{") {
+		return errors.New("unclosed placeholder")
+	}
+	return nil
+}
+
+// RenderTemplate replaces placeholders of given template with given values.
+// Unknown placeholders are left as they are
+func RenderTemplate(tmpl string, values map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(tmpl, func(s string) string {
+		if v, ok := values[placeholderPattern.FindStringSubmatch(s)[1]]; ok {
+			return v
+		}
+		return s
+	})
+}
+
+// templateValues returns live values of placeholders of given alert observed in given market
+func templateValues(alert *model.Alert, m Market) map[string]string {
+	values := map[string]string{
+		PlaceholderSymbol:    missingValue,
+		PlaceholderPrice:     missingValue,
+		PlaceholderThreshold: missingValue,
+		PlaceholderChange:    missingValue,
+		PlaceholderPair:      strings.ToLower(alert.PairAddress),
+	}
+	if alert.AlertType != TypeExpression {
+		values[PlaceholderThreshold] = strings.TrimSpace(strings.SplitN(alert.AlertValue, ":", 2)[0])
+	}
+	q, err := m.Quote(alert.PairAddress)
+	if err != nil {
+		return values
+	}
+	values[PlaceholderSymbol] = q.Symbol
+	values[PlaceholderPrice] = formatNumber(q.USDPrice())
+
+	window := 24 * time.Hour
+	if w, ok := windows[alert.AlertOption]; ok && alert.AlertType == TypePercentChange {
+		window = w
+	}
+	if baseline, err := m.Baseline(alert.PairAddress, window); err == nil && baseline > 0 {
+		values[PlaceholderChange] = fmt.Sprintf("%+.2f", (q.USDPrice()-baseline)/baseline*100)
+	}
+	return values
+}
+
+// formatNumber formats given number with 6 significant digits without exponent
+// such as "1520.12" and "0.0000123457"
+func formatNumber(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return missingValue
+	}
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 6, 64), 64)
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
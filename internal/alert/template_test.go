@@ -0,0 +1,101 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	cases := []struct {
+		Template string
+		Message  string
+	}{
+		{Template: "WETH below 1500"},
+		{Template: "{{symbol}} is {{ price }} USD ({{change}}%) over {{threshold}} at {{pair}}"},
+		{Template: "{{volume}} is high", Message: "unknown placeholder {{volume}}, must be one of change, pair, price, symbol, threshold"},
+		{Template: "{{}} is high", Message: "unknown placeholder {{}}, must be one of change, pair, price, symbol, threshold"},
+		{Template: "{{symbol is high", Message: "unclosed placeholder"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Template, func(t *testing.T) {
+			err := ValidateTemplate(tc.Template)
+
+			if tc.Message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.Message)
+		})
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	values := map[string]string{PlaceholderSymbol: "WETH", PlaceholderPrice: "2000"}
+
+	rendered := RenderTemplate("{{symbol}} is {{ price }} USD, {{volume}}", values)
+
+	assert.Equal(t, "WETH is 2000 USD, {{volume}}", rendered)
+}
+
+func TestTemplateValues(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Address   string
+		AlertType string
+		Option    string
+		Value     string
+		Baselines map[time.Duration]float64
+		Expected  map[string]string
+	}{
+		{
+			Name: "price", Address: wethAddress, AlertType: TypePrice, Option: OptionAbove, Value: "1500",
+			Baselines: map[time.Duration]float64{24 * time.Hour: 1600},
+			Expected: map[string]string{
+				PlaceholderSymbol: "WETH", PlaceholderPrice: "2000", PlaceholderThreshold: "1500",
+				PlaceholderChange: "+25.00", PlaceholderPair: wethAddress,
+			},
+		},
+		{
+			Name: "equal without tolerance", Address: wethAddress, AlertType: TypePrice, Option: OptionEqual, Value: "2000:5",
+			Expected: map[string]string{
+				PlaceholderSymbol: "WETH", PlaceholderPrice: "2000", PlaceholderThreshold: "2000",
+				PlaceholderChange: missingValue, PlaceholderPair: wethAddress,
+			},
+		},
+		{
+			Name: "change over window", Address: wethAddress, AlertType: TypePercentChange, Option: "1h", Value: "-5",
+			Baselines: map[time.Duration]float64{time.Hour: 2500, 24 * time.Hour: 1600},
+			Expected: map[string]string{
+				PlaceholderSymbol: "WETH", PlaceholderPrice: "2000", PlaceholderThreshold: "-5",
+				PlaceholderChange: "-20.00", PlaceholderPair: wethAddress,
+			},
+		},
+		{
+			Name: "no quote", Address: "0x0000000000000000000000000000000000000001", AlertType: TypePrice, Option: OptionAbove, Value: "1500",
+			Expected: map[string]string{
+				PlaceholderSymbol: missingValue, PlaceholderPrice: missingValue, PlaceholderThreshold: "1500",
+				PlaceholderChange: missingValue, PlaceholderPair: "0x0000000000000000000000000000000000000001",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			alert := newConditionAlert(tc.Address, tc.AlertType, tc.Option, tc.Value)
+			m := &testMarket{Quotes: cannedQuotes(t), baselines: tc.Baselines}
+
+			values := templateValues(alert, m)
+
+			assert.Equal(t, tc.Expected, values)
+		})
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	assert.Equal(t, "2000", formatNumber(2000))
+	assert.Equal(t, "1520.12", formatNumber(1520.123456))
+	assert.Equal(t, "0.0000123457", formatNumber(0.0000123456789))
+}
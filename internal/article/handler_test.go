@@ -11,6 +11,7 @@ import (
 	articleDBMock "kek-backend/internal/article/database/mocks"
 	"kek-backend/internal/article/model"
 	"kek-backend/internal/config"
+	"kek-backend/internal/notify"
 	"kek-backend/pkg/logging"
 	"net/http"
 	"net/http/httptest"
@@ -95,7 +96,7 @@ func (s *HandlerSuite) SetupTest() {
 
 	RouteV1(cfg, s.handler, s.r, jwtMiddleware)
 
-	accountHandler := account.NewHandler(s.accountDB)
+	accountHandler := account.NewHandler(s.accountDB, notify.NewRegistry())
 	account.RouteV1(cfg, accountHandler, s.r, jwtMiddleware)
 }
 
@@ -18,6 +18,12 @@ type Config struct {
 	JwtConfig     JWTConfig     `json:"jwt"`
 	DBConfig      DBConfig      `json:"db"`
 	MetricsConfig MetricsConfig `json:"metrics"`
+	AlertConfig   AlertConfig   `json:"alert"`
+	NotifyConfig  NotifyConfig  `json:"notify"`
+	MailConfig    MailConfig    `json:"mail"`
+	FCMConfig     FCMConfig     `json:"fcm"`
+	StreamConfig  StreamConfig  `json:"stream"`
+	PriceConfig   PriceConfig   `json:"price"`
 }
 
 type ServerConfig struct {
@@ -50,6 +56,95 @@ type MetricsConfig struct {
 	Subsystem string `json:"subsystem"`
 }
 
+type AlertConfig struct {
+	Cron      string `json:"cron"`
+	BatchSize int    `json:"batchSize"`
+	Workers   int    `json:"workers"`
+}
+
+type NotifyConfig struct {
+	Webhook WebhookConfig `json:"webhook"`
+	Outbox  OutboxConfig  `json:"outbox"`
+}
+
+// WebhookConfig is the config of webhook requests. Failed requests are retried by the outbox
+type WebhookConfig struct {
+	TimeoutSecs int `json:"timeoutSecs"`
+}
+
+type OutboxConfig struct {
+	Cron      string `json:"cron"`
+	BatchSize int    `json:"batchSize"`
+	// MaxAttempts is the number of attempts before a notification is dead-lettered,
+	// waiting BackoffSecs doubled after each failed attempt
+	MaxAttempts int `json:"maxAttempts"`
+	BackoffSecs int `json:"backoffSecs"`
+	// LeaseSecs is how long a claimed notification is hidden from other dispatchers,
+	// after which a notification of a crashed dispatcher is claimed again
+	LeaseSecs int `json:"leaseSecs"`
+}
+
+type MailConfig struct {
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	From        string `json:"from"`
+	TimeoutSecs int    `json:"timeoutSecs"`
+}
+
+type FCMConfig struct {
+	// Key is the legacy server key of the FCM project. It has no default and
+	// must be set with KEK_SERVER_FCM_KEY or the config file
+	Key string `json:"key"`
+	// Endpoint is the FCM send endpoint, overridden to point at a fake FCM server
+	Endpoint    string `json:"endpoint"`
+	TimeoutSecs int    `json:"timeoutSecs"`
+}
+
+type StreamConfig struct {
+	HeartbeatSecs int `json:"heartbeatSecs"`
+	// BufferSize is the number of events buffered for a client before it is dropped as too slow
+	BufferSize   int `json:"bufferSize"`
+	MaxAddresses int `json:"maxAddresses"`
+	// TicketSecs is the lifetime of a ticket authenticating a stream request in query
+	TicketSecs int `json:"ticketSecs"`
+}
+
+type PriceConfig struct {
+	// Source is the price source of the alert engine, one of uniswap and fixed
+	Source  string           `json:"source"`
+	Fixed   FixedPriceConfig `json:"fixed"`
+	Uniswap UniswapConfig    `json:"uniswap"`
+	// RetentionHours is how long price snapshots are kept, forever if not positive
+	RetentionHours int `json:"retentionHours"`
+	// PruneCron is the cron spec of deleting price snapshots older than the retention
+	PruneCron string `json:"pruneCron"`
+}
+
+// FixedPriceConfig is quotes of the fixed price source keyed by token address
+type FixedPriceConfig struct {
+	EthPrice float64                     `json:"ethPrice"`
+	Tokens   map[string]FixedTokenConfig `json:"tokens"`
+}
+
+type FixedTokenConfig struct {
+	Name           string  `json:"name"`
+	Symbol         string  `json:"symbol"`
+	DerivedETH     float64 `json:"derivedETH"`
+	TotalLiquidity float64 `json:"totalLiquidity"`
+}
+
+// UniswapConfig is the config of the GraphQL client of the uniswap subgraph
+type UniswapConfig struct {
+	URL         string `json:"url"`
+	TimeoutSecs int    `json:"timeoutSecs"`
+	// MaxRetries is the number of retries after the first attempt,
+	// waiting BackoffMillis doubled with jitter on each retry
+	MaxRetries    int `json:"maxRetries"`
+	BackoffMillis int `json:"backoffMillis"`
+}
+
 func (c *DBConfig) MarshalJSON() ([]byte, error) {
 	m := map[string]interface{}{
 		"dataSourceName": "[PROTECTED]", // TODO : masking
@@ -63,6 +158,27 @@ func (c *DBConfig) MarshalJSON() ([]byte, error) {
 	return json.Marshal(m)
 }
 
+func (c *MailConfig) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{
+		"host":        c.Host,
+		"port":        c.Port,
+		"username":    c.Username,
+		"password":    "[PROTECTED]",
+		"from":        c.From,
+		"timeoutSecs": c.TimeoutSecs,
+	}
+	return json.Marshal(m)
+}
+
+func (c *FCMConfig) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{
+		"key":         "[PROTECTED]",
+		"endpoint":    c.Endpoint,
+		"timeoutSecs": c.TimeoutSecs,
+	}
+	return json.Marshal(m)
+}
+
 func Load(configPath string) (*Config, error) {
 	k := koanf.New(".")
 
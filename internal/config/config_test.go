@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -24,13 +25,97 @@ func TestLoad(t *testing.T) {
 	// db configs
 	assert.Equal(t, defaultConfig["db.dataSourceName"].(string), cfg.DBConfig.DataSourceName)
 	assert.Equal(t, defaultConfig["db.migrate.enable"].(bool), cfg.DBConfig.Migrate.Enable)
-	assert.Equal(t, defaultConfig["db.migrate.dir"].(bool), cfg.DBConfig.Migrate.Dir)
+	assert.Equal(t, defaultConfig["db.migrate.dir"].(string), cfg.DBConfig.Migrate.Dir)
 	assert.Equal(t, defaultConfig["db.pool.maxOpen"].(int), cfg.DBConfig.Pool.MaxOpen)
 	assert.Equal(t, defaultConfig["db.pool.maxIdle"].(int), cfg.DBConfig.Pool.MaxIdle)
 	assert.Equal(t, defaultConfig["db.pool.maxLifetime"].(int), cfg.DBConfig.Pool.MaxLifetime)
 	// metrics configs
 	assert.Equal(t, defaultConfig["metrics.namespace"].(string), cfg.MetricsConfig.Namespace)
 	assert.Equal(t, defaultConfig["metrics.subsystem"].(string), cfg.MetricsConfig.Subsystem)
+
+	// alert configs
+	assert.Equal(t, defaultConfig["alert.cron"].(string), cfg.AlertConfig.Cron)
+	assert.Equal(t, defaultConfig["alert.batchSize"].(int), cfg.AlertConfig.BatchSize)
+	assert.Equal(t, defaultConfig["alert.workers"].(int), cfg.AlertConfig.Workers)
+
+	// notify configs
+	assert.Equal(t, defaultConfig["notify.webhook.timeoutSecs"].(int), cfg.NotifyConfig.Webhook.TimeoutSecs)
+	assert.Equal(t, defaultConfig["notify.outbox.cron"].(string), cfg.NotifyConfig.Outbox.Cron)
+	assert.Equal(t, defaultConfig["notify.outbox.batchSize"].(int), cfg.NotifyConfig.Outbox.BatchSize)
+	assert.Equal(t, defaultConfig["notify.outbox.maxAttempts"].(int), cfg.NotifyConfig.Outbox.MaxAttempts)
+	assert.Equal(t, defaultConfig["notify.outbox.backoffSecs"].(int), cfg.NotifyConfig.Outbox.BackoffSecs)
+	assert.Equal(t, defaultConfig["notify.outbox.leaseSecs"].(int), cfg.NotifyConfig.Outbox.LeaseSecs)
+
+	// mail configs
+	assert.Equal(t, defaultConfig["mail.host"].(string), cfg.MailConfig.Host)
+	assert.Equal(t, defaultConfig["mail.port"].(int), cfg.MailConfig.Port)
+	assert.Equal(t, defaultConfig["mail.from"].(string), cfg.MailConfig.From)
+	assert.Equal(t, defaultConfig["mail.timeoutSecs"].(int), cfg.MailConfig.TimeoutSecs)
+
+	// fcm configs
+	assert.Empty(t, cfg.FCMConfig.Key)
+	assert.Equal(t, defaultConfig["fcm.endpoint"].(string), cfg.FCMConfig.Endpoint)
+	assert.Equal(t, defaultConfig["fcm.timeoutSecs"].(int), cfg.FCMConfig.TimeoutSecs)
+
+	// stream configs
+	assert.Equal(t, defaultConfig["stream.heartbeatSecs"].(int), cfg.StreamConfig.HeartbeatSecs)
+	assert.Equal(t, defaultConfig["stream.bufferSize"].(int), cfg.StreamConfig.BufferSize)
+	assert.Equal(t, defaultConfig["stream.maxAddresses"].(int), cfg.StreamConfig.MaxAddresses)
+	assert.Equal(t, defaultConfig["stream.ticketSecs"].(int), cfg.StreamConfig.TicketSecs)
+
+	// price configs
+	assert.Equal(t, defaultConfig["price.source"].(string), cfg.PriceConfig.Source)
+	assert.Equal(t, defaultConfig["price.uniswap.url"].(string), cfg.PriceConfig.Uniswap.URL)
+	assert.Equal(t, defaultConfig["price.uniswap.timeoutSecs"].(int), cfg.PriceConfig.Uniswap.TimeoutSecs)
+	assert.Equal(t, defaultConfig["price.uniswap.maxRetries"].(int), cfg.PriceConfig.Uniswap.MaxRetries)
+	assert.Equal(t, defaultConfig["price.uniswap.backoffMillis"].(int), cfg.PriceConfig.Uniswap.BackoffMillis)
+	assert.Equal(t, defaultConfig["price.retentionHours"].(int), cfg.PriceConfig.RetentionHours)
+	assert.Equal(t, defaultConfig["price.pruneCron"].(string), cfg.PriceConfig.PruneCron)
+}
+
+func TestLoad_FixedPriceSource(t *testing.T) {
+	path := t.TempDir() + "/config.yaml"
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`
+price:
+  source: fixed
+  fixed:
+    ethPrice: 2000
+    tokens:
+      "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2":
+        symbol: WETH
+        derivedETH: 1
+        totalLiquidity: 150000
+`), 0644))
+
+	cfg, err := Load(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "fixed", cfg.PriceConfig.Source)
+	assert.Equal(t, 2000.0, cfg.PriceConfig.Fixed.EthPrice)
+	assert.Equal(t, map[string]FixedTokenConfig{
+		"0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2": {Symbol: "WETH", DerivedETH: 1, TotalLiquidity: 150000},
+	}, cfg.PriceConfig.Fixed.Tokens)
+}
+
+func TestMailConfig_MarshalJSON(t *testing.T) {
+	cfg := MailConfig{Host: "smtp.example.com", Port: 587, Username: "user", Password: "secret"}
+
+	b, err := json.Marshal(&cfg)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "secret")
+	assert.Contains(t, string(b), "[PROTECTED]")
+}
+
+func TestFCMConfig_MarshalJSON(t *testing.T) {
+	cfg := FCMConfig{Key: "secret", Endpoint: "http://localhost:4000/fcm/send"}
+
+	b, err := json.Marshal(&cfg)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "secret")
+	assert.Contains(t, string(b), "[PROTECTED]")
+	assert.Contains(t, string(b), "http://localhost:4000/fcm/send")
 }
 
 func TestLoadWithEnv(t *testing.T) {
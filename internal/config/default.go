@@ -18,4 +18,39 @@ var defaultConfig = map[string]interface{}{
 
 	"metrics.namespace": "kek_server",
 	"metrics.subsystem": "",
+
+	"alert.cron":      "@every 5s",
+	"alert.batchSize": 100,
+	"alert.workers":   8,
+
+	"notify.webhook.timeoutSecs": 5,
+	"notify.outbox.cron":         "@every 5s",
+	"notify.outbox.batchSize":    100,
+	"notify.outbox.maxAttempts":  5,
+	"notify.outbox.backoffSecs":  30,
+	"notify.outbox.leaseSecs":    300,
+
+	"mail.host":        "localhost",
+	"mail.port":        25,
+	"mail.username":    "",
+	"mail.password":    "",
+	"mail.from":        "alerts@kek.local",
+	"mail.timeoutSecs": 10,
+
+	"fcm.key":         "",
+	"fcm.endpoint":    "https://fcm.googleapis.com/fcm/send",
+	"fcm.timeoutSecs": 10,
+
+	"stream.heartbeatSecs": 15,
+	"stream.bufferSize":    64,
+	"stream.maxAddresses":  50,
+	"stream.ticketSecs":    60,
+
+	"price.source":                "uniswap",
+	"price.uniswap.url":           "https://api.thegraph.com/subgraphs/name/uniswap/uniswap-v2",
+	"price.uniswap.timeoutSecs":   10,
+	"price.uniswap.maxRetries":    2,
+	"price.uniswap.backoffMillis": 200,
+	"price.retentionHours":        168,
+	"price.pruneCron":             "@every 1h",
 }
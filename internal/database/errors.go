@@ -3,9 +3,13 @@ package database
 import (
 	"errors"
 	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgconn"
 	"gorm.io/gorm"
 )
 
+// pgUniqueViolation is the SQLSTATE of a unique constraint violation in postgres
+const pgUniqueViolation = "23505"
+
 var (
 	ErrNotFound    = errors.New("record not found")
 	ErrKeyConflict = errors.New("key conflict")
@@ -16,11 +20,16 @@ func IsRecordNotFoundErr(err error) bool {
 	return err == gorm.ErrRecordNotFound || err == ErrNotFound
 }
 
-// IsKeyConflictErr returns true if err is ErrKeyConflict or MySQLError with 1062 code number
+// IsKeyConflictErr returns true if err is ErrKeyConflict, MySQLError with 1062 code number
+// or postgres PgError with 23505 code
 func IsKeyConflictErr(err error) bool {
 	if err == ErrKeyConflict {
 		return true
 	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgUniqueViolation
+	}
 	switch err.(type) {
 	case *mysql.MySQLError:
 		e := err.(*mysql.MySQLError)
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsKeyConflictErr(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Err      error
+		Conflict bool
+	}{
+		{Name: "key conflict", Err: ErrKeyConflict, Conflict: true},
+		{Name: "mysql duplicate entry", Err: &mysql.MySQLError{Number: 1062}, Conflict: true},
+		{Name: "postgres unique violation", Err: &pgconn.PgError{Code: "23505"}, Conflict: true},
+		{Name: "wrapped postgres unique violation", Err: fmt.Errorf("create: %w", &pgconn.PgError{Code: "23505"}), Conflict: true},
+		{Name: "postgres not null violation", Err: &pgconn.PgError{Code: "23502"}},
+		{Name: "mysql other error", Err: &mysql.MySQLError{Number: 1048}},
+		{Name: "other error", Err: errors.New("connection refused")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Conflict, IsKeyConflictErr(tc.Err))
+		})
+	}
+}
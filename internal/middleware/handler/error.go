@@ -13,11 +13,15 @@ const (
 	InvalidUriValue   = ErrorCode("InvalidUriValue")
 	InvalidBodyValue  = ErrorCode("InvalidBodyValue")
 
+	// 403 forbidden
+	PermissionDenied = ErrorCode("PermissionDenied")
+
 	// 404 not found
 	NotFoundEntity = ErrorCode("NotFoundEntity")
 
-	// 409 duplicate
-	DuplicateEntry = ErrorCode("DuplicateEntry")
+	// 409 conflict
+	DuplicateEntry          = ErrorCode("DuplicateEntry")
+	InvalidStatusTransition = ErrorCode("InvalidStatusTransition")
 
 	// 500
 	InternalServerError = ErrorCode("InternalServerError")
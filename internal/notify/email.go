@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"embed"
+	"fmt"
+	htmlTemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	textTemplate "text/template"
+	"time"
+
+	accountModel "kek-backend/internal/account/model"
+	"kek-backend/internal/config"
+
+	"github.com/pkg/errors"
+)
+
+// ActionEmail is the action name of email notifications
+const ActionEmail = "email"
+
+//go:embed templates/email.html templates/email.txt
+var emailTemplates embed.FS
+
+var (
+	emailHTML = htmlTemplate.Must(htmlTemplate.ParseFS(emailTemplates, "templates/email.html"))
+	emailText = textTemplate.Must(textTemplate.ParseFS(emailTemplates, "templates/email.txt"))
+)
+
+// emailNotifier sends a multipart HTML and plaintext mail to the email of an account over SMTP
+type emailNotifier struct {
+	cfg config.MailConfig
+}
+
+func (n *emailNotifier) Notify(ctx context.Context, account *accountModel.Account, msg *Message) error {
+	if account.Email == "" {
+		return ErrNoRecipient
+	}
+	mail, err := composeMail(n.cfg.From, account.Email, msg)
+	if err != nil {
+		return err
+	}
+	return n.send(ctx, account.Email, mail)
+}
+
+// send delivers given mail to given address in a SMTP session.
+// STARTTLS and authentication are used if the server supports them
+func (n *emailNotifier) send(ctx context.Context, to string, mail []byte) error {
+	timeout := time.Duration(n.cfg.TimeoutSecs) * time.Second
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return errors.Wrap(err, "dial smtp server")
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, "create smtp client")
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.cfg.Host}); err != nil {
+			return errors.Wrap(err, "start tls")
+		}
+	}
+	if ok, _ := c.Extension("AUTH"); ok && n.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return errors.Wrap(err, "authenticate smtp")
+		}
+	}
+	if err := c.Mail(n.cfg.From); err != nil {
+		return errors.Wrap(err, "send mail from")
+	}
+	if err := c.Rcpt(to); err != nil {
+		return errors.Wrap(err, "send rcpt to")
+	}
+	w, err := c.Data()
+	if err != nil {
+		return errors.Wrap(err, "send data")
+	}
+	if _, err := w.Write(mail); err != nil {
+		return errors.Wrap(err, "write mail")
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "send mail")
+	}
+	return c.Quit()
+}
+
+// composeMail returns a MIME mail of given message with plaintext and HTML alternatives
+func composeMail(from, to string, msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Title))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	parts := []struct {
+		contentType string
+		execute     func(w *quotedprintable.Writer) error
+	}{
+		{contentType: "text/plain", execute: func(w *quotedprintable.Writer) error { return emailText.Execute(w, msg) }},
+		{contentType: "text/html", execute: func(w *quotedprintable.Writer) error { return emailHTML.Execute(w, msg) }},
+	}
+	for _, part := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "create mail part")
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if err := part.execute(qw); err != nil {
+			return nil, errors.Wrap(err, "render mail template")
+		}
+		if err := qw.Close(); err != nil {
+			return nil, errors.Wrap(err, "encode mail part")
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, errors.Wrap(err, "close mail")
+	}
+	return buf.Bytes(), nil
+}
+
+// NewEmailNotifier creates a notifier sending mails with given config
+func NewEmailNotifier(cfg config.MailConfig) Notifier {
+	return &emailNotifier{cfg: cfg}
+}
@@ -0,0 +1,182 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	accountModel "kek-backend/internal/account/model"
+	"kek-backend/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSMTPServer is an in-process SMTP server accepting one mail per session
+type fakeSMTPServer struct {
+	ln    net.Listener
+	mails chan fakeMail
+}
+
+type fakeMail struct {
+	From string
+	To   []string
+	Data string
+}
+
+func newFakeSMTPServer(t *testing.T, rejectRcpt bool) *fakeSMTPServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	s := &fakeSMTPServer{ln: ln, mails: make(chan fakeMail, 1)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, rejectRcpt)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn, rejectRcpt bool) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost fake ESMTP")
+	var m fakeMail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			m.From = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case "RCPT":
+			if rejectRcpt {
+				reply("550 no such user")
+				continue
+			}
+			m.To = append(m.To, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			m.Data = data.String()
+			s.mails <- m
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) config() config.MailConfig {
+	addr := s.ln.Addr().(*net.TCPAddr)
+	return config.MailConfig{Host: "127.0.0.1", Port: addr.Port, From: "alerts@kek.local", TimeoutSecs: 1}
+}
+
+func TestEmailNotifier_Notify(t *testing.T) {
+	// given
+	srv := newFakeSMTPServer(t, false)
+	defer srv.ln.Close()
+	n := NewEmailNotifier(srv.config())
+	msg := Message{
+		Title:         "WETH above 1500",
+		Body:          "WETH <is> up",
+		Slug:          "weth-above-1500",
+		Condition:     "price above 1500",
+		ObservedPrice: 1520.5,
+		TriggeredAt:   time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	// when
+	err := n.Notify(context.Background(), &accountModel.Account{Email: "user1@gmail.com"}, &msg)
+
+	// then
+	assert.NoError(t, err)
+	var m fakeMail
+	select {
+	case m = <-srv.mails:
+	case <-time.After(time.Second):
+		t.Fatal("mail is not received")
+	}
+	assert.Equal(t, "alerts@kek.local", m.From)
+	assert.Equal(t, []string{"user1@gmail.com"}, m.To)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(m.Data))
+	assert.NoError(t, err)
+	assert.Equal(t, "WETH above 1500", parsed.Header.Get("Subject"))
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	parts := map[string]string{}
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		b, _ := ioutil.ReadAll(p)
+		contentType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		parts[contentType] = string(b)
+	}
+	assert.Contains(t, parts["text/plain"], "WETH <is> up")
+	assert.Contains(t, parts["text/plain"], "Condition: price above 1500")
+	assert.Contains(t, parts["text/plain"], "Observed price: 1520.5")
+	assert.Contains(t, parts["text/plain"], "Triggered at: 2021-06-01 12:00:00 UTC")
+	assert.Contains(t, parts["text/html"], "<p>WETH &lt;is&gt; up</p>")
+	assert.Contains(t, parts["text/html"], "weth-above-1500")
+}
+
+func TestEmailNotifier_FailIfRejected(t *testing.T) {
+	// given
+	srv := newFakeSMTPServer(t, true)
+	defer srv.ln.Close()
+	n := NewEmailNotifier(srv.config())
+
+	// when
+	err := n.Notify(context.Background(), &accountModel.Account{Email: "unknown@gmail.com"}, &Message{Title: "title"})
+
+	// then
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "send rcpt to: 550")
+}
+
+func TestEmailNotifier_FailIfNoEmail(t *testing.T) {
+	// given
+	n := NewEmailNotifier(config.MailConfig{})
+
+	// when
+	err := n.Notify(context.Background(), &accountModel.Account{}, &Message{})
+
+	// then
+	assert.Equal(t, ErrNoRecipient, err)
+}
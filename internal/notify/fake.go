@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"context"
+	"sync"
+
+	accountModel "kek-backend/internal/account/model"
+)
+
+// Sent is a message delivered by a Fake
+type Sent struct {
+	Account *accountModel.Account
+	Message Message
+}
+
+// Fake is an in-memory notifier for tests. It records messages and
+// returns Errs of the account username if given
+type Fake struct {
+	Errs map[string]error
+
+	mu   sync.Mutex
+	sent []Sent
+}
+
+func (f *Fake) Notify(ctx context.Context, account *accountModel.Account, msg *Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err, ok := f.Errs[account.Username]; ok {
+		return err
+	}
+	f.sent = append(f.sent, Sent{Account: account, Message: *msg})
+	return nil
+}
+
+// Sent returns delivered messages in order
+func (f *Fake) Sent() []Sent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Sent(nil), f.sent...)
+}
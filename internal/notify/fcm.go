@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	accountModel "kek-backend/internal/account/model"
+	"kek-backend/internal/config"
+	"kek-backend/pkg/logging"
+
+	"github.com/appleboy/go-fcm"
+	"github.com/pkg/errors"
+)
+
+// ActionPush is the action name of FCM push notifications
+const ActionPush = "push"
+
+// ErrPushDisabled is returned for push notifications if no FCM key is configured
+var ErrPushDisabled = errors.New("push notifications are disabled, fcm.key is not configured")
+
+// FCMSender sends a message to FCM. *fcm.Client is a FCMSender
+type FCMSender interface {
+	Send(msg *fcm.Message) (*fcm.Response, error)
+}
+
+// DeviceStore finds and prunes push devices of accounts. accountDB.AccountDB is a DeviceStore
+type DeviceStore interface {
+	FindDevices(ctx context.Context, accountId uint) ([]*accountModel.AccountDevice, error)
+	DeleteDevicesByToken(ctx context.Context, tokens []string) error
+}
+
+// fcmNotifier sends push notifications to the devices of an account
+type fcmNotifier struct {
+	sender  FCMSender
+	devices DeviceStore
+}
+
+// Notify sends a message to all devices of the account at once and prunes devices of tokens
+// rejected by FCM as invalid or unregistered. It succeeds if any device received the message
+func (n *fcmNotifier) Notify(ctx context.Context, account *accountModel.Account, msg *Message) error {
+	devices, err := n.devices.FindDevices(ctx, account.ID)
+	if err != nil {
+		return errors.Wrap(err, "find devices")
+	}
+	if len(devices) == 0 {
+		return ErrNoRecipient
+	}
+	var tokens []string
+	for _, device := range devices {
+		tokens = append(tokens, device.Token)
+	}
+
+	response, err := n.sender.Send(&fcm.Message{
+		RegistrationIDs: tokens,
+		Notification: &fcm.Notification{
+			Title: msg.Title,
+			Body:  msg.Body,
+		},
+		Data: fcmData(msg),
+	})
+	if err != nil {
+		return errors.Wrap(err, "send fcm message")
+	}
+
+	var (
+		invalid []string
+		failed  error
+	)
+	for i, result := range response.Results {
+		if result.Error == nil || i >= len(tokens) {
+			continue
+		}
+		if result.Unregistered() {
+			invalid = append(invalid, tokens[i])
+		} else if failed == nil {
+			failed = result.Error
+		}
+	}
+	if len(invalid) > 0 {
+		logging.FromContext(ctx).Infow("notify.fcm prune invalid devices", "accountId", account.ID, "count", len(invalid))
+		if err := n.devices.DeleteDevicesByToken(ctx, invalid); err != nil {
+			logging.FromContext(ctx).Errorw("notify.fcm failed to prune invalid devices", "err", err)
+		}
+	}
+
+	if response.Success > 0 {
+		return nil
+	}
+	if failed != nil {
+		return errors.Wrap(failed, "send fcm message")
+	}
+	if len(invalid) == len(tokens) {
+		return ErrNoRecipient
+	}
+	return errors.New("send fcm message: failed")
+}
+
+// fcmData returns the data payload of given message for the app to open the alert.
+// FCM requires values of the payload to be strings
+func fcmData(msg *Message) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "alert",
+		"slug":        msg.Slug,
+		"price":       strconv.FormatFloat(msg.ObservedPrice, 'f', -1, 64),
+		"condition":   msg.Condition,
+		"triggeredAt": msg.TriggeredAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// NewFCMNotifier creates a notifier sending push notifications with given sender to devices of given store
+func NewFCMNotifier(sender FCMSender, devices DeviceStore) Notifier {
+	return &fcmNotifier{sender: sender, devices: devices}
+}
+
+// NewFCMClient creates a FCM client with the key and endpoint of given config.
+// It returns a nil client if no key is configured, which disables push notifications
+func NewFCMClient(cfg *config.Config) (*fcm.Client, error) {
+	if cfg.FCMConfig.Key == "" {
+		logging.DefaultLogger().Warn("fcm.key is not configured, push notifications are disabled. " +
+			"Set KEK_SERVER_FCM_KEY or fcm.key in the config file to enable them")
+		return nil, nil
+	}
+	client, err := fcm.NewClient(cfg.FCMConfig.Key,
+		fcm.WithEndpoint(cfg.FCMConfig.Endpoint),
+		fcm.WithTimeout(time.Duration(cfg.FCMConfig.TimeoutSecs)*time.Second))
+	if err != nil {
+		return nil, errors.Wrap(err, "create fcm client")
+	}
+	return client, nil
+}
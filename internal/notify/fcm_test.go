@@ -0,0 +1,210 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	accountModel "kek-backend/internal/account/model"
+	"kek-backend/internal/config"
+
+	"github.com/appleboy/go-fcm"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeFCMSender records messages and returns given response
+type fakeFCMSender struct {
+	messages []*fcm.Message
+	response *fcm.Response
+	err      error
+}
+
+func (s *fakeFCMSender) Send(msg *fcm.Message) (*fcm.Response, error) {
+	s.messages = append(s.messages, msg)
+	return s.response, s.err
+}
+
+// fakeDeviceStore returns devices of given tokens and records deleted tokens
+type fakeDeviceStore struct {
+	tokens  []string
+	deleted []string
+}
+
+func (s *fakeDeviceStore) FindDevices(ctx context.Context, accountId uint) ([]*accountModel.AccountDevice, error) {
+	var ret []*accountModel.AccountDevice
+	for _, token := range s.tokens {
+		ret = append(ret, &accountModel.AccountDevice{AccountID: accountId, Platform: accountModel.PlatformAndroid, Token: token})
+	}
+	return ret, nil
+}
+
+func (s *fakeDeviceStore) DeleteDevicesByToken(ctx context.Context, tokens []string) error {
+	s.deleted = append(s.deleted, tokens...)
+	return nil
+}
+
+func TestFCMNotifier_Notify(t *testing.T) {
+	// given
+	sender := &fakeFCMSender{response: &fcm.Response{Success: 2, Results: []fcm.Result{{}, {}}}}
+	devices := &fakeDeviceStore{tokens: []string{"token1", "token2"}}
+	n := NewFCMNotifier(sender, devices)
+
+	// when
+	msg := Message{
+		Title:         "title",
+		Body:          "body",
+		Slug:          "weth-above-1500",
+		Condition:     "price above 1500",
+		ObservedPrice: 1520.5,
+		TriggeredAt:   time.Date(2021, 11, 1, 9, 30, 0, 0, time.UTC),
+	}
+	err := n.Notify(context.Background(), &accountModel.Account{ID: 1}, &msg)
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, sender.messages, 1)
+	assert.Equal(t, []string{"token1", "token2"}, sender.messages[0].RegistrationIDs)
+	assert.Equal(t, "title", sender.messages[0].Notification.Title)
+	assert.Equal(t, "body", sender.messages[0].Notification.Body)
+	assert.Equal(t, map[string]interface{}{
+		"type":        "alert",
+		"slug":        "weth-above-1500",
+		"price":       "1520.5",
+		"condition":   "price above 1500",
+		"triggeredAt": "2021-11-01T09:30:00Z",
+	}, sender.messages[0].Data)
+	assert.Empty(t, devices.deleted)
+}
+
+func TestFCMNotifier_FailIfNoDevice(t *testing.T) {
+	// given
+	sender := &fakeFCMSender{}
+	n := NewFCMNotifier(sender, &fakeDeviceStore{})
+
+	// when
+	err := n.Notify(context.Background(), &accountModel.Account{ID: 1}, &Message{Title: "title"})
+
+	// then
+	assert.Equal(t, ErrNoRecipient, err)
+	assert.Empty(t, sender.messages)
+}
+
+func TestFCMNotifier_PruneInvalidDevices(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Results []fcm.Result
+		Success int
+		Deleted []string
+		Err     error
+	}{
+		{Name: "partially delivered", Results: []fcm.Result{{Error: fcm.ErrNotRegistered}, {}, {Error: fcm.ErrInvalidRegistration}},
+			Success: 1, Deleted: []string{"token1", "token3"}},
+		{Name: "all invalid", Results: []fcm.Result{{Error: fcm.ErrNotRegistered}, {Error: fcm.ErrNotRegistered}, {Error: fcm.ErrInvalidRegistration}},
+			Deleted: []string{"token1", "token2", "token3"}, Err: ErrNoRecipient},
+		{Name: "keep devices of other errors", Results: []fcm.Result{{Error: fcm.ErrUnavailable}, {Error: fcm.ErrNotRegistered}, {Error: fcm.ErrUnavailable}},
+			Deleted: []string{"token2"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// given
+			sender := &fakeFCMSender{response: &fcm.Response{Success: tc.Success, Failure: len(tc.Results) - tc.Success, Results: tc.Results}}
+			devices := &fakeDeviceStore{tokens: []string{"token1", "token2", "token3"}}
+			n := NewFCMNotifier(sender, devices)
+
+			// when
+			err := n.Notify(context.Background(), &accountModel.Account{ID: 1}, &Message{Title: "title"})
+
+			// then
+			assert.Equal(t, tc.Deleted, devices.deleted)
+			if tc.Success == 0 {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tc.Err != nil {
+				assert.Equal(t, tc.Err, err)
+			}
+		})
+	}
+}
+
+func TestFCMNotifier_FailIfRejected(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Response *fcm.Response
+		Err      error
+		Message  string
+	}{
+		{Name: "send error", Err: errors.New("timeout"), Message: "send fcm message: timeout"},
+		{Name: "result error", Response: &fcm.Response{Failure: 1, Results: []fcm.Result{{Error: fcm.ErrUnavailable}}},
+			Message: "send fcm message: " + fcm.ErrUnavailable.Error()},
+		{Name: "failure without result", Response: &fcm.Response{Failure: 1}, Message: "send fcm message: failed"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// given
+			n := NewFCMNotifier(&fakeFCMSender{response: tc.Response, err: tc.Err}, &fakeDeviceStore{tokens: []string{"token1"}})
+
+			// when
+			err := n.Notify(context.Background(), &accountModel.Account{ID: 1}, &Message{Title: "title"})
+
+			// then
+			assert.EqualError(t, err, tc.Message)
+		})
+	}
+}
+
+func TestNewFCMClient(t *testing.T) {
+	// given
+	var (
+		authorization string
+		message       fcm.Message
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":1,"results":[{"message_id":"1"}]}`))
+	}))
+	defer srv.Close()
+	cfg := config.Config{FCMConfig: config.FCMConfig{Key: "key1", Endpoint: srv.URL, TimeoutSecs: 1}}
+
+	// when
+	client, err := NewFCMClient(&cfg)
+	assert.NoError(t, err)
+	err = NewFCMNotifier(client, &fakeDeviceStore{tokens: []string{"token1"}}).Notify(context.Background(), &accountModel.Account{ID: 1}, &Message{Title: "title"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "key=key1", authorization)
+	assert.Equal(t, []string{"token1"}, message.RegistrationIDs)
+	assert.Equal(t, "title", message.Notification.Title)
+}
+
+func TestNewFCMClient_NilIfNoKey(t *testing.T) {
+	// when
+	client, err := NewFCMClient(&config.Config{})
+
+	// then
+	assert.NoError(t, err)
+	assert.Nil(t, client)
+}
+
+func TestNewNotifiers_PushDisabledIfNoClient(t *testing.T) {
+	// given
+	r := NewNotifiers(&config.Config{}, nil, nil)
+
+	// when
+	n, err := r.Notifier(ActionPush)
+	assert.NoError(t, err)
+	err = n.Notify(context.Background(), &accountModel.Account{}, &Message{})
+
+	// then
+	assert.ErrorIs(t, err, ErrPushDisabled)
+}
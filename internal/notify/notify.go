@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	accountDB "kek-backend/internal/account/database"
+	accountModel "kek-backend/internal/account/model"
+	"kek-backend/internal/config"
+
+	"github.com/appleboy/go-fcm"
+	"github.com/pkg/errors"
+)
+
+// ErrNoRecipient is returned by a notifier if an account has no address on its channel
+var ErrNoRecipient = errors.New("no recipient")
+
+// Message is a notification of a triggered alert
+type Message struct {
+	Title         string
+	Body          string
+	Slug          string
+	Condition     string
+	ObservedPrice float64
+	TriggeredAt   time.Time
+}
+
+// Notifier delivers a message to an account over a channel
+type Notifier interface {
+	// Notify sends given message to given account.
+	// ErrNoRecipient is returned if the account has no address on the channel
+	Notify(ctx context.Context, account *accountModel.Account, msg *Message) error
+}
+
+// Registry is notifiers keyed by alert action name such as "push"
+type Registry struct {
+	notifiers map[string]Notifier
+}
+
+// Register registers given notifier with given action name, replacing a notifier of the same name
+func (r *Registry) Register(action string, n Notifier) {
+	r.notifiers[action] = n
+}
+
+// Notifier returns the notifier of given action name
+func (r *Registry) Notifier(action string) (Notifier, error) {
+	n, ok := r.notifiers[action]
+	if !ok {
+		return nil, fmt.Errorf("unknown alert action %q, must be one of %s", action, strings.Join(r.Actions(), ", "))
+	}
+	return n, nil
+}
+
+// Actions returns registered action names in order
+func (r *Registry) Actions() []string {
+	var ret []string
+	for action := range r.notifiers {
+		ret = append(ret, action)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{notifiers: make(map[string]Notifier)}
+}
+
+// disabledNotifier fails all notifications of a channel which is not configured
+type disabledNotifier struct {
+	err error
+}
+
+// Notify returns the error of the disabled channel
+func (n *disabledNotifier) Notify(_ context.Context, _ *accountModel.Account, _ *Message) error {
+	return n.err
+}
+
+// NewNotifiers creates a registry of notifiers of all supported alert actions.
+// Push notifications fail with ErrPushDisabled if given FCM client is nil
+func NewNotifiers(cfg *config.Config, fcmClient *fcm.Client, accountDB accountDB.AccountDB) *Registry {
+	r := NewRegistry()
+	if fcmClient != nil {
+		r.Register(ActionPush, NewFCMNotifier(fcmClient, accountDB))
+	} else {
+		r.Register(ActionPush, &disabledNotifier{err: ErrPushDisabled})
+	}
+	r.Register(ActionWebhook, NewWebhookNotifier(cfg.NotifyConfig.Webhook))
+	r.Register(ActionEmail, NewEmailNotifier(cfg.MailConfig))
+	return r
+}
@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	accountModel "kek-backend/internal/account/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	// given
+	r := NewRegistry()
+	push, webhook := &Fake{}, &Fake{}
+	r.Register("push", push)
+	r.Register("webhook", webhook)
+
+	// when
+	n, err := r.Notifier("webhook")
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, n.Notify(context.Background(), &accountModel.Account{Username: "user1"}, &Message{Title: "title"}))
+	assert.Len(t, webhook.Sent(), 1)
+	assert.Empty(t, push.Sent())
+	assert.Equal(t, []string{"push", "webhook"}, r.Actions())
+}
+
+func TestRegistry_FailIfUnknownAction(t *testing.T) {
+	// given
+	r := NewRegistry()
+	r.Register("push", &Fake{})
+
+	// when
+	n, err := r.Notifier("sms")
+
+	// then
+	assert.Nil(t, n)
+	assert.EqualError(t, err, `unknown alert action "sms", must be one of push`)
+}
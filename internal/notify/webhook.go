@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	accountModel "kek-backend/internal/account/model"
+	"kek-backend/internal/config"
+
+	"github.com/pkg/errors"
+)
+
+// ActionWebhook is the action name of webhook notifications
+const ActionWebhook = "webhook"
+
+// SignatureHeader is the header of a webhook request with "sha256=" and
+// the hex encoded HMAC-SHA256 of the request body keyed by the webhook secret of the account
+const SignatureHeader = "X-Kek-Signature"
+
+// ErrForbiddenWebhook is returned if a webhook url is not https or resolves to a non public address
+var ErrForbiddenWebhook = errors.New("forbidden webhook address")
+
+// WebhookPayload is the JSON body of a webhook request
+type WebhookPayload struct {
+	Slug          string    `json:"slug"`
+	Title         string    `json:"title"`
+	Body          string    `json:"body"`
+	Condition     string    `json:"condition"`
+	ObservedPrice float64   `json:"observedPrice"`
+	TriggeredAt   time.Time `json:"triggeredAt"`
+}
+
+// webhookNotifier posts a signed payload to the webhook url of an account.
+// A request is attempted once and a transport error or a non-2xx response is returned
+// so that the outbox retries the notification with backoff.
+// Only https urls are posted and connections to loopback, private and link-local addresses
+// are refused when dialed, so that a webhook cannot reach internal services
+type webhookNotifier struct {
+	client *http.Client
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, account *accountModel.Account, msg *Message) error {
+	if account.WebhookURL == "" {
+		return ErrNoRecipient
+	}
+	if u, err := url.Parse(account.WebhookURL); err != nil || u.Scheme != "https" {
+		return ErrForbiddenWebhook
+	}
+	body, err := json.Marshal(&WebhookPayload{
+		Slug:          msg.Slug,
+		Title:         msg.Title,
+		Body:          msg.Body,
+		Condition:     msg.Condition,
+		ObservedPrice: msg.ObservedPrice,
+		TriggeredAt:   msg.TriggeredAt,
+	})
+	if err != nil {
+		return errors.Wrap(err, "encode webhook payload")
+	}
+	return n.post(ctx, account.WebhookURL, body, Sign(account.WebhookSecret, body))
+}
+
+// post sends a request of given body and returns an error if the response is not 2xx
+func (n *webhookNotifier) post(ctx context.Context, url string, body []byte, signature string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "create webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, signature)
+	res, err := n.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "send webhook request")
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("send webhook request: status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// checkRedirect follows up to 10 redirects to https urls only
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "https" {
+		return ErrForbiddenWebhook
+	}
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
+
+// dialControl refuses connections to addresses other than public unicast addresses.
+// It runs after the host is resolved, so a public name resolving to an internal address is refused too
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return errors.Wrapf(ErrForbiddenWebhook, "dial %s", address)
+	}
+	return nil
+}
+
+// isPublicIP returns true if given ip is not a loopback, private, link-local, multicast or unspecified address
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// Sign returns the signature of given body keyed by given secret as sent in SignatureHeader
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewWebhookNotifier creates a notifier posting to webhooks of accounts with given config
+func NewWebhookNotifier(cfg config.WebhookConfig) Notifier {
+	timeout := time.Duration(cfg.TimeoutSecs) * time.Second
+	dialer := &net.Dialer{Timeout: timeout, Control: dialControl}
+	return &webhookNotifier{
+		client: &http.Client{
+			// no proxy so that every connection is dialed with the address check
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: checkRedirect,
+			Timeout:       timeout,
+		},
+	}
+}
@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	accountModel "kek-backend/internal/account/model"
+	"kek-backend/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var webhookConfig = config.WebhookConfig{TimeoutSecs: 1}
+
+// newTestWebhookNotifier creates a notifier trusting given TLS test server on the loopback address
+func newTestWebhookNotifier(srv *httptest.Server) *webhookNotifier {
+	return &webhookNotifier{client: srv.Client()}
+}
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	// given
+	var (
+		payload   WebhookPayload
+		signature string
+		body      []byte
+	)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		json.Unmarshal(body, &payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	n := newTestWebhookNotifier(srv)
+	account := accountModel.Account{WebhookURL: srv.URL, WebhookSecret: "0123456789abcdef"}
+	triggeredAt := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	msg := Message{
+		Title:         "title",
+		Body:          "body",
+		Slug:          "weth-above-1500",
+		Condition:     "price above 1500",
+		ObservedPrice: 1520.5,
+		TriggeredAt:   triggeredAt,
+	}
+
+	// when
+	err := n.Notify(context.Background(), &account, &msg)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "weth-above-1500", payload.Slug)
+	assert.Equal(t, "price above 1500", payload.Condition)
+	assert.Equal(t, 1520.5, payload.ObservedPrice)
+	assert.True(t, triggeredAt.Equal(payload.TriggeredAt))
+	assert.Equal(t, Sign(account.WebhookSecret, body), signature)
+}
+
+func TestWebhookNotifier_FailIfNot2xx(t *testing.T) {
+	// given
+	var calls int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	n := newTestWebhookNotifier(srv)
+
+	// when
+	err := n.Notify(context.Background(), &accountModel.Account{WebhookURL: srv.URL}, &Message{})
+
+	// then
+	assert.EqualError(t, err, "send webhook request: status 503")
+	// the outbox retries the notification instead of the notifier
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestWebhookNotifier_FailIfNoURL(t *testing.T) {
+	// given
+	n := NewWebhookNotifier(webhookConfig)
+
+	// when
+	err := n.Notify(context.Background(), &accountModel.Account{}, &Message{})
+
+	// then
+	assert.Equal(t, ErrNoRecipient, err)
+}
+
+func TestWebhookNotifier_FailIfNotHTTPS(t *testing.T) {
+	// given
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer srv.Close()
+	n := newTestWebhookNotifier(srv)
+
+	// when
+	err := n.Notify(context.Background(), &accountModel.Account{WebhookURL: srv.URL}, &Message{})
+
+	// then
+	assert.Equal(t, ErrForbiddenWebhook, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestWebhookNotifier_FailIfInternalAddress(t *testing.T) {
+	// given
+	var calls int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer srv.Close()
+	n := NewWebhookNotifier(webhookConfig)
+
+	// when
+	err := n.Notify(context.Background(), &accountModel.Account{WebhookURL: srv.URL}, &Message{})
+
+	// then
+	assert.True(t, errors.Is(err, ErrForbiddenWebhook))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestIsPublicIP(t *testing.T) {
+	cases := []struct {
+		ip       string
+		expected bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.1", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, isPublicIP(net.ParseIP(tc.ip)), tc.ip)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"slug":"a"}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=f6142147f4feeaa7ee1bf4bbfcbaf8778aee978a615491e0e960f4b955db38e8", Sign("secret", []byte(`{"slug":"a"}`)))
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"kek-backend/internal/database"
+	"kek-backend/internal/price/model"
+	"kek-backend/pkg/logging"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IterateSnapshotCriteria struct {
+	TokenAddress string
+	From         time.Time
+	To           time.Time
+	// Cursor is the observed time of the last snapshot of the previous page, the first page if zero
+	Cursor time.Time
+	Limit  uint
+}
+
+//go:generate mockery --name PriceDB --filename price_mock.go
+type PriceDB interface {
+	// SaveSnapshots saves given price snapshots
+	SaveSnapshots(ctx context.Context, snapshots []*model.PriceSnapshot) error
+
+	// FindSnapshots returns price snapshots of a token observed in [From, To] after the cursor
+	// in ascending observed order
+	FindSnapshots(ctx context.Context, criteria IterateSnapshotCriteria) ([]*model.PriceSnapshot, error)
+
+	// FindSnapshotBefore returns the latest price snapshot of a token observed at or before given time
+	// database.ErrNotFound error is returned if not exist
+	FindSnapshotBefore(ctx context.Context, tokenAddress string, before time.Time) (*model.PriceSnapshot, error)
+
+	// DeleteSnapshotsBefore deletes price snapshots of all tokens observed before given time
+	// and returns deleted records count
+	DeleteSnapshotsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type priceDB struct {
+	db *gorm.DB
+}
+
+func (p *priceDB) SaveSnapshots(ctx context.Context, snapshots []*model.PriceSnapshot) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, p.db)
+	logger.Debugw("price.db.SaveSnapshots", "count", len(snapshots))
+
+	if len(snapshots) == 0 {
+		return nil
+	}
+	if err := db.WithContext(ctx).Create(snapshots).Error; err != nil {
+		logger.Errorw("price.db.SaveSnapshots failed to save snapshots", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (p *priceDB) FindSnapshots(ctx context.Context, criteria IterateSnapshotCriteria) ([]*model.PriceSnapshot, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, p.db)
+	logger.Debugw("price.db.FindSnapshots", "criteria", criteria)
+
+	chain := db.WithContext(ctx).
+		Where("token_address = ?", criteria.TokenAddress).
+		Where("observed_at BETWEEN ? AND ?", criteria.From, criteria.To)
+	if !criteria.Cursor.IsZero() {
+		chain = chain.Where("observed_at > ?", criteria.Cursor)
+	}
+	var ret []*model.PriceSnapshot
+	err := chain.
+		Order("observed_at ASC").
+		Limit(int(criteria.Limit)).
+		Find(&ret).Error
+	if err != nil {
+		logger.Errorw("failed to find price snapshots", "err", err)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (p *priceDB) FindSnapshotBefore(ctx context.Context, tokenAddress string, before time.Time) (*model.PriceSnapshot, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, p.db)
+	logger.Debugw("price.db.FindSnapshotBefore", "tokenAddress", tokenAddress, "before", before)
+
+	var ret model.PriceSnapshot
+	err := db.WithContext(ctx).
+		Where("token_address = ? AND observed_at <= ?", tokenAddress, before).
+		Order("observed_at DESC").
+		First(&ret).Error
+	if err != nil {
+		logger.Errorw("failed to find price snapshot", "err", err)
+		if database.IsRecordNotFoundErr(err) {
+			return nil, database.ErrNotFound
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (p *priceDB) DeleteSnapshotsBefore(ctx context.Context, before time.Time) (int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, p.db)
+	logger.Debugw("price.db.DeleteSnapshotsBefore", "before", before)
+
+	chain := db.WithContext(ctx).
+		Where("observed_at < ?", before).
+		Delete(&model.PriceSnapshot{})
+	if chain.Error != nil {
+		logger.Errorw("failed to delete price snapshots", "err", chain.Error)
+		return 0, chain.Error
+	}
+	return chain.RowsAffected, nil
+}
+
+// NewPriceDB creates a new price db with given db
+func NewPriceDB(db *gorm.DB) PriceDB {
+	return &priceDB{
+		db: db,
+	}
+}
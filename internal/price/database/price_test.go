@@ -0,0 +1,200 @@
+package database
+
+import (
+	"kek-backend/internal/database"
+	"kek-backend/internal/price/model"
+	"kek-backend/pkg/logging"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
+)
+
+type DBSuite struct {
+	suite.Suite
+	db       PriceDB
+	originDB *gorm.DB
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(DBSuite))
+}
+
+func (s *DBSuite) SetupSuite() {
+	logging.SetLevel(zapcore.FatalLevel)
+	s.originDB = database.NewTestDatabase(s.T(), true)
+	s.db = &priceDB{db: s.originDB}
+}
+
+func (s *DBSuite) SetupTest() {
+	s.NoError(database.DeleteRecordAll(s.T(), s.originDB, []string{
+		"price_snapshots", "id > 0",
+	}))
+}
+
+func (s *DBSuite) TestSaveSnapshots() {
+	// given
+	now := time.Now().Truncate(time.Second)
+	snapshots := []*model.PriceSnapshot{
+		newSnapshot("0x1", 10, now),
+		newSnapshot("0x2", 20, now),
+	}
+
+	// when
+	err := s.db.SaveSnapshots(nil, snapshots)
+
+	// then
+	s.NoError(err)
+	for _, snapshot := range snapshots {
+		s.NotEqual(uint(0), snapshot.ID)
+		find, err := s.db.FindSnapshots(nil, IterateSnapshotCriteria{
+			TokenAddress: snapshot.TokenAddress,
+			From:         now.Add(-time.Second),
+			To:           now.Add(time.Second),
+			Limit:        10,
+		})
+		s.NoError(err)
+		s.Equal(1, len(find))
+		s.assertSnapshot(snapshot, find[0])
+	}
+}
+
+func (s *DBSuite) TestSaveSnapshots_Empty() {
+	s.NoError(s.db.SaveSnapshots(nil, nil))
+}
+
+func (s *DBSuite) TestFindSnapshots() {
+	// given
+	// 0x1 - now-3m
+	// 0x1 - now-2m   <- [0]
+	// 0x1 - now-1m   <- [1]
+	// 0x1 - now
+	// 0x2 - now-2m
+	now := time.Now().Truncate(time.Second)
+	s1 := newSnapshot("0x1", 1, now.Add(-3*time.Minute))
+	s2 := newSnapshot("0x1", 2, now.Add(-2*time.Minute))
+	s3 := newSnapshot("0x1", 3, now.Add(-time.Minute))
+	s4 := newSnapshot("0x1", 4, now)
+	s5 := newSnapshot("0x2", 5, now.Add(-2*time.Minute))
+	s.NoError(s.db.SaveSnapshots(nil, []*model.PriceSnapshot{s4, s3, s2, s1, s5}))
+
+	// when
+	find, err := s.db.FindSnapshots(nil, IterateSnapshotCriteria{
+		TokenAddress: "0x1",
+		From:         now.Add(-2 * time.Minute),
+		To:           now.Add(-time.Minute),
+		Limit:        10,
+	})
+
+	// then
+	s.NoError(err)
+	s.Equal(2, len(find))
+	s.assertSnapshot(s2, find[0])
+	s.assertSnapshot(s3, find[1])
+}
+
+func (s *DBSuite) TestFindSnapshots_WithCursor() {
+	// given
+	now := time.Now().Truncate(time.Second)
+	s1 := newSnapshot("0x1", 1, now.Add(-3*time.Minute))
+	s2 := newSnapshot("0x1", 2, now.Add(-2*time.Minute))
+	s3 := newSnapshot("0x1", 3, now.Add(-time.Minute))
+	s.NoError(s.db.SaveSnapshots(nil, []*model.PriceSnapshot{s3, s2, s1}))
+	criteria := IterateSnapshotCriteria{
+		TokenAddress: "0x1",
+		From:         now.Add(-time.Hour),
+		To:           now,
+		Limit:        2,
+	}
+
+	// when
+	first, err1 := s.db.FindSnapshots(nil, criteria)
+	criteria.Cursor = first[len(first)-1].ObservedAt
+	second, err2 := s.db.FindSnapshots(nil, criteria)
+
+	// then
+	s.NoError(err1)
+	s.Equal(2, len(first))
+	s.assertSnapshot(s1, first[0])
+	s.assertSnapshot(s2, first[1])
+	s.NoError(err2)
+	s.Equal(1, len(second))
+	s.assertSnapshot(s3, second[0])
+}
+
+func (s *DBSuite) TestDeleteSnapshotsBefore() {
+	// given
+	now := time.Now().Truncate(time.Second)
+	s1 := newSnapshot("0x1", 1, now.Add(-2*time.Hour))
+	s2 := newSnapshot("0x2", 2, now.Add(-2*time.Hour))
+	s3 := newSnapshot("0x1", 3, now)
+	s.NoError(s.db.SaveSnapshots(nil, []*model.PriceSnapshot{s1, s2, s3}))
+
+	// when
+	deleted, err := s.db.DeleteSnapshotsBefore(nil, now.Add(-time.Hour))
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(2), deleted)
+	find, err := s.db.FindSnapshots(nil, IterateSnapshotCriteria{
+		TokenAddress: "0x1",
+		From:         now.Add(-3 * time.Hour),
+		To:           now,
+		Limit:        10,
+	})
+	s.NoError(err)
+	s.Equal(1, len(find))
+	s.assertSnapshot(s3, find[0])
+}
+
+func (s *DBSuite) TestFindSnapshotBefore() {
+	// given
+	now := time.Now().Truncate(time.Second)
+	s1 := newSnapshot("0x1", 1, now.Add(-3*time.Minute))
+	s2 := newSnapshot("0x1", 2, now.Add(-2*time.Minute))
+	s3 := newSnapshot("0x1", 3, now.Add(-time.Minute))
+	s4 := newSnapshot("0x2", 4, now.Add(-90*time.Second))
+	s.NoError(s.db.SaveSnapshots(nil, []*model.PriceSnapshot{s1, s2, s3, s4}))
+
+	// when
+	find, err := s.db.FindSnapshotBefore(nil, "0x1", now.Add(-90*time.Second))
+
+	// then
+	s.NoError(err)
+	s.assertSnapshot(s2, find)
+}
+
+func (s *DBSuite) TestFindSnapshotBefore_FailIfNotExist() {
+	// given
+	now := time.Now().Truncate(time.Second)
+	s.NoError(s.db.SaveSnapshots(nil, []*model.PriceSnapshot{newSnapshot("0x1", 1, now)}))
+
+	// when
+	find, err := s.db.FindSnapshotBefore(nil, "0x1", now.Add(-time.Minute))
+
+	// then
+	s.Nil(find)
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) assertSnapshot(expected, actual *model.PriceSnapshot) {
+	s.Equal(expected.TokenAddress, actual.TokenAddress)
+	s.Equal(expected.DerivedETH, actual.DerivedETH)
+	s.Equal(expected.EthPrice, actual.EthPrice)
+	s.Equal(expected.USDPrice, actual.USDPrice)
+	s.Equal(expected.TotalLiquidity, actual.TotalLiquidity)
+	s.WithinDuration(expected.ObservedAt, actual.ObservedAt, time.Second)
+}
+
+func newSnapshot(address string, usdPrice float64, observedAt time.Time) *model.PriceSnapshot {
+	return &model.PriceSnapshot{
+		TokenAddress:   address,
+		DerivedETH:     usdPrice / 2000,
+		EthPrice:       2000,
+		USDPrice:       usdPrice,
+		TotalLiquidity: 1000,
+		ObservedAt:     observedAt,
+	}
+}
@@ -0,0 +1,115 @@
+package price
+
+import (
+	"kek-backend/internal/config"
+	"kek-backend/internal/middleware"
+	"kek-backend/internal/middleware/handler"
+	priceDB "kek-backend/internal/price/database"
+	"kek-backend/pkg/logging"
+	"kek-backend/pkg/validate"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	defaultPricesRange = 24 * time.Hour
+	maxPricesLimit     = 1000
+)
+
+type Handler struct {
+	priceDB priceDB.PriceDB
+}
+
+// prices handles GET /v1/api/tokens/:address/prices.
+// A range with more prices than the limit is paged with nextCursor of the response
+func (h *Handler) prices(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		// bind
+		type RequestUri struct {
+			Address string `uri:"address" binding:"required"`
+		}
+		type QueryParameter struct {
+			From time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+			To   time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+			// Cursor is the observed time of the last price of the previous page
+			Cursor time.Time `form:"cursor" time_format:"2006-01-02T15:04:05.999999999Z07:00"`
+			Limit  string    `form:"limit,default=500" binding:"numeric"`
+		}
+		var (
+			uri   RequestUri
+			query QueryParameter
+		)
+		if err := c.ShouldBindUri(&uri); err != nil {
+			logger.Errorw("price.handler.prices failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&uri, "uri", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid price request in uri", details)
+		}
+		if err := c.ShouldBindQuery(&query); err != nil {
+			logger.Errorw("price.handler.prices failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&query, "form", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidQueryValue, "invalid price request in query", details)
+		}
+
+		to := query.To
+		if to.IsZero() {
+			to = time.Now()
+		}
+		from := query.From
+		if from.IsZero() {
+			from = to.Add(-defaultPricesRange)
+		}
+		if from.After(to) {
+			details := validate.NewValidationErrorDetails("from", "from must be before to", c.Query("from"))
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidQueryValue, "invalid price request in query", details)
+		}
+		limit, err := strconv.ParseUint(query.Limit, 10, 64)
+		if err != nil || limit > maxPricesLimit {
+			limit = maxPricesLimit
+		}
+
+		criteria := priceDB.IterateSnapshotCriteria{
+			TokenAddress: strings.ToLower(uri.Address),
+			From:         from,
+			To:           to,
+			Cursor:       query.Cursor,
+			Limit:        uint(limit),
+		}
+		snapshots, err := h.priceDB.FindSnapshots(c.Request.Context(), criteria)
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, NewPricesResponse(snapshots, uint(limit)))
+	})
+}
+
+func RouteV1(cfg *config.Config, h *Handler, r *gin.Engine, auth *jwt.GinJWTMiddleware) {
+	v1 := r.Group("v1/api")
+	timeout := time.Duration(cfg.ServerConfig.WriteTimeoutSecs) * time.Second
+	v1.Use(middleware.RequestIDMiddleware(), middleware.TimeoutMiddleware(timeout))
+
+	tokenV1 := v1.Group("tokens")
+	// anonymous
+	tokenV1.Use()
+	{
+		tokenV1.GET(":address/prices", h.prices)
+	}
+}
+
+func NewHandler(priceDB priceDB.PriceDB) *Handler {
+	return &Handler{
+		priceDB: priceDB,
+	}
+}
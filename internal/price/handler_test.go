@@ -0,0 +1,173 @@
+package price
+
+import (
+	"fmt"
+	"kek-backend/internal/account"
+	accountDBMock "kek-backend/internal/account/database/mocks"
+	"kek-backend/internal/config"
+	"kek-backend/internal/price/database"
+	priceDBMock "kek-backend/internal/price/database/mocks"
+	"kek-backend/internal/price/model"
+	"kek-backend/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap/zapcore"
+)
+
+const dAddress = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+
+var dSnapshot = model.PriceSnapshot{
+	ID:             1,
+	TokenAddress:   dAddress,
+	DerivedETH:     1,
+	EthPrice:       2000,
+	USDPrice:       2000,
+	TotalLiquidity: 150000,
+	ObservedAt:     time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC),
+	CreatedAt:      time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC),
+}
+
+type HandlerSuite struct {
+	suite.Suite
+	r       *gin.Engine
+	handler *Handler
+	db      *priceDBMock.PriceDB
+}
+
+func (s *HandlerSuite) SetupSuite() {
+	logging.SetLevel(zapcore.FatalLevel)
+}
+
+func (s *HandlerSuite) SetupTest() {
+	cfg, err := config.Load("")
+	s.NoError(err)
+
+	s.db = &priceDBMock.PriceDB{}
+	s.handler = NewHandler(s.db)
+
+	jwtMiddleware, err := account.NewAuthMiddleware(cfg, &accountDBMock.AccountDB{})
+	s.NoError(err)
+
+	gin.SetMode(gin.TestMode)
+	s.r = gin.Default()
+
+	RouteV1(cfg, s.handler, s.r, jwtMiddleware)
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(HandlerSuite))
+}
+
+func (s *HandlerSuite) TestPrices() {
+	// given
+	from := time.Date(2021, 10, 31, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 11, 2, 0, 0, 0, 0, time.UTC)
+	criteria := database.IterateSnapshotCriteria{
+		TokenAddress: dAddress,
+		From:         from,
+		To:           to,
+		Limit:        500,
+	}
+	s.db.On("FindSnapshots", mock.Anything, mock.MatchedBy(func(c database.IterateSnapshotCriteria) bool {
+		return c.TokenAddress == criteria.TokenAddress && c.From.Equal(from) && c.To.Equal(to) && c.Limit == criteria.Limit
+	})).Return([]*model.PriceSnapshot{&dSnapshot}, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	path := fmt.Sprintf("/v1/api/tokens/%s/prices?from=%s&to=%s",
+		"0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", url.QueryEscape(from.Format(time.RFC3339)), url.QueryEscape(to.Format(time.RFC3339)))
+	req, _ := http.NewRequest("GET", path, nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	result := gjson.Parse(res.Body.String())
+	s.Equal(int64(1), result.Get("pricesCount").Int())
+	price := result.Get("prices").Array()[0]
+	s.Equal(dAddress, price.Get("tokenAddress").String())
+	s.Equal(dSnapshot.USDPrice, price.Get("usdPrice").Float())
+	s.Equal(dSnapshot.EthPrice, price.Get("ethPrice").Float())
+	s.Equal(dSnapshot.DerivedETH, price.Get("derivedETH").Float())
+	s.Equal(dSnapshot.TotalLiquidity, price.Get("totalLiquidity").Float())
+	s.Equal(dSnapshot.ObservedAt.Format(time.RFC3339), price.Get("observedAt").String())
+}
+
+func (s *HandlerSuite) TestPrices_DefaultRange() {
+	// given
+	s.db.On("FindSnapshots", mock.Anything, mock.Anything).Return([]*model.PriceSnapshot{}, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/tokens/"+dAddress+"/prices", nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.db.AssertCalled(s.T(), "FindSnapshots", mock.Anything, mock.MatchedBy(func(c database.IterateSnapshotCriteria) bool {
+		return c.To.Sub(c.From) == defaultPricesRange && time.Since(c.To) < time.Minute
+	}))
+	s.JSONEq(`{"prices": [], "pricesCount": 0}`, res.Body.String())
+}
+
+func (s *HandlerSuite) TestPrices_NextCursor() {
+	// given
+	second := dSnapshot
+	second.ID = 2
+	second.ObservedAt = dSnapshot.ObservedAt.Add(5*time.Second + 250*time.Millisecond)
+	s.db.On("FindSnapshots", mock.Anything, mock.Anything).Return([]*model.PriceSnapshot{&dSnapshot, &second}, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/tokens/"+dAddress+"/prices?limit=2", nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal("2021-11-01T00:00:05.25Z", gjson.Get(res.Body.String(), "nextCursor").String())
+}
+
+func (s *HandlerSuite) TestPrices_WithCursor() {
+	// given
+	cursor := time.Date(2021, 11, 1, 0, 0, 5, 250000000, time.UTC)
+	s.db.On("FindSnapshots", mock.Anything, mock.Anything).Return([]*model.PriceSnapshot{&dSnapshot}, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	path := fmt.Sprintf("/v1/api/tokens/%s/prices?limit=2&cursor=%s", dAddress, url.QueryEscape(cursor.Format(time.RFC3339Nano)))
+	req, _ := http.NewRequest("GET", path, nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.db.AssertCalled(s.T(), "FindSnapshots", mock.Anything, mock.MatchedBy(func(c database.IterateSnapshotCriteria) bool {
+		return c.Cursor.Equal(cursor) && c.Limit == 2
+	}))
+	s.False(gjson.Get(res.Body.String(), "nextCursor").Exists())
+}
+
+func (s *HandlerSuite) TestPrices_FailIfInvalidRange() {
+	// when
+	res := httptest.NewRecorder()
+	path := fmt.Sprintf("/v1/api/tokens/%s/prices?from=%s&to=%s", dAddress,
+		url.QueryEscape("2021-11-02T00:00:00Z"), url.QueryEscape("2021-11-01T00:00:00Z"))
+	req, _ := http.NewRequest("GET", path, nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "FindSnapshots", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.Equal("InvalidQueryValue", gjson.Get(res.Body.String(), "code").String())
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+type PriceSnapshot struct {
+	ID             uint      `gorm:"column:id"`
+	TokenAddress   string    `gorm:"column:token_address"`
+	DerivedETH     float64   `gorm:"column:derived_eth"`
+	EthPrice       float64   `gorm:"column:eth_price"`
+	USDPrice       float64   `gorm:"column:usd_price"`
+	TotalLiquidity float64   `gorm:"column:total_liquidity"`
+	ObservedAt     time.Time `gorm:"column:observed_at"`
+	CreatedAt      time.Time `gorm:"column:created_at"`
+}
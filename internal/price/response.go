@@ -0,0 +1,45 @@
+package price
+
+import (
+	"kek-backend/internal/price/model"
+	"time"
+)
+
+type PricesResponse struct {
+	Prices      []Price `json:"prices"`
+	PricesCount int     `json:"pricesCount"`
+	// NextCursor is the cursor of the next page if the range may have more prices than the limit
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+type Price struct {
+	TokenAddress   string    `json:"tokenAddress"`
+	DerivedETH     float64   `json:"derivedETH"`
+	EthPrice       float64   `json:"ethPrice"`
+	USDPrice       float64   `json:"usdPrice"`
+	TotalLiquidity float64   `json:"totalLiquidity"`
+	ObservedAt     time.Time `json:"observedAt"`
+}
+
+// NewPricesResponse converts price snapshot models of a page with given limit to PricesResponse
+func NewPricesResponse(snapshots []*model.PriceSnapshot, limit uint) *PricesResponse {
+	prices := make([]Price, 0, len(snapshots))
+	for _, s := range snapshots {
+		prices = append(prices, Price{
+			TokenAddress:   s.TokenAddress,
+			DerivedETH:     s.DerivedETH,
+			EthPrice:       s.EthPrice,
+			USDPrice:       s.USDPrice,
+			TotalLiquidity: s.TotalLiquidity,
+			ObservedAt:     s.ObservedAt,
+		})
+	}
+	res := PricesResponse{
+		Prices:      prices,
+		PricesCount: len(prices),
+	}
+	if len(snapshots) > 0 && uint(len(snapshots)) == limit {
+		res.NextCursor = snapshots[len(snapshots)-1].ObservedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return &res
+}
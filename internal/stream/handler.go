@@ -0,0 +1,146 @@
+package stream
+
+import (
+	"fmt"
+	"kek-backend/internal/account"
+	"kek-backend/internal/config"
+	"kek-backend/internal/middleware"
+	"kek-backend/internal/middleware/handler"
+	"kek-backend/pkg/logging"
+	"kek-backend/pkg/validate"
+	"net/http"
+	"strings"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// stream event types sent by the handler itself
+const (
+	eventReady     = "ready"
+	eventHeartbeat = "heartbeat"
+	eventDropped   = "dropped"
+)
+
+type Handler struct {
+	hub          *Hub
+	tickets      *account.StreamAuthMiddleware
+	heartbeat    time.Duration
+	maxAddresses int
+}
+
+// ticket handles POST /v1/api/stream/ticket
+// and issues a short-lived ticket of current user to open the stream with
+func (h *Handler) ticket(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		currentUser := account.MustCurrentUser(c)
+		ticket, expire, err := h.tickets.TokenGenerator(currentUser)
+		if err != nil {
+			logger.Errorw("stream.handler.ticket failed to issue a ticket", "err", err)
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusCreated, gin.H{"ticket": ticket, "expire": expire})
+	})
+}
+
+// stream handles GET /v1/api/stream?ticket= as server-sent events.
+// Price ticks of tokens in address query parameters and alert triggers of current user are pushed as they happen
+func (h *Handler) stream(c *gin.Context) {
+	logger := logging.FromContext(c)
+	type QueryParameter struct {
+		Address []string `form:"address" binding:"omitempty,dive,len=42,startswith=0x"`
+	}
+	var query QueryParameter
+	if err := c.ShouldBindQuery(&query); err != nil {
+		logger.Errorw("stream.handler.stream failed to bind", "err", err)
+		var details []*validate.ValidationErrDetail
+		if vErrs, ok := err.(validator.ValidationErrors); ok {
+			details = validate.ValidationErrorDetails(&query, "form", vErrs)
+		}
+		abort(c, handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid stream request in query", details))
+		return
+	}
+	if len(query.Address) > h.maxAddresses {
+		message := fmt.Sprintf("at most %d addresses can be subscribed", h.maxAddresses)
+		details := validate.NewValidationErrorDetails("address", message, strings.Join(query.Address, ","))
+		abort(c, handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid stream request in query", details))
+		return
+	}
+
+	for i, address := range query.Address {
+		query.Address[i] = strings.ToLower(address)
+	}
+	currentUser := account.MustCurrentUser(c)
+	sub := h.hub.Subscribe(currentUser.ID, query.Address)
+	defer h.hub.Unsubscribe(sub)
+	logger.Debugw("stream.handler.stream subscribed", "accountId", currentUser.ID, "addresses", query.Address)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	send(c, eventReady, gin.H{"addresses": query.Address})
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-sub.Dropped():
+			logger.Infow("stream.handler.stream dropped a slow client", "accountId", currentUser.ID)
+			send(c, eventDropped, gin.H{"message": "client is too slow to receive events"})
+			return
+		case e := <-sub.Events():
+			send(c, e.Type, e.Data)
+		case now := <-heartbeat.C:
+			send(c, eventHeartbeat, gin.H{"time": now.UTC()})
+		}
+	}
+}
+
+// send writes an event of given type and data to the stream and flushes it
+func send(c *gin.Context, event string, data interface{}) {
+	c.SSEvent(event, data)
+	c.Writer.Flush()
+}
+
+// abort responds given error response before the stream starts
+func abort(c *gin.Context, res *handler.Response) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		return res
+	})
+}
+
+// RouteV1 routes stream api given config and gin.Engine.
+// The stream is authenticated with a ticket in query instead of the session token
+// and is not bound to the write timeout of other apis
+func RouteV1(cfg *config.Config, h *Handler, r *gin.Engine, auth *jwt.GinJWTMiddleware) {
+	v1 := r.Group("v1/api/stream")
+	v1.Use(middleware.RequestIDMiddleware())
+	{
+		v1.POST("ticket", auth.MiddlewareFunc(), h.ticket)
+		v1.GET("", h.tickets.MiddlewareFunc(), h.stream)
+	}
+}
+
+func NewHandler(cfg *config.Config, hub *Hub, tickets *account.StreamAuthMiddleware) *Handler {
+	heartbeat := time.Duration(cfg.StreamConfig.HeartbeatSecs) * time.Second
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	maxAddresses := cfg.StreamConfig.MaxAddresses
+	if maxAddresses <= 0 {
+		maxAddresses = 50
+	}
+	return &Handler{
+		hub:          hub,
+		tickets:      tickets,
+		heartbeat:    heartbeat,
+		maxAddresses: maxAddresses,
+	}
+}
@@ -0,0 +1,261 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"kek-backend/internal/account"
+	accountDBMock "kek-backend/internal/account/database/mocks"
+	accountModel "kek-backend/internal/account/model"
+	"kek-backend/internal/config"
+	"kek-backend/internal/notify"
+	"kek-backend/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	dUser = accountModel.Account{
+		ID:       1,
+		Username: "user1",
+		Email:    "user1@gmail.com",
+		Password: "$2a$10$lsYsLv8nGPM0.R.ft4sgpe3OP7..KL3ZJqqhSVCKTEnSCMUztoUcW",
+	}
+	dUserRawPass = "user1"
+	wethAddress  = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+)
+
+type HandlerSuite struct {
+	suite.Suite
+	r       *gin.Engine
+	srv     *httptest.Server
+	hub     *Hub
+	handler *Handler
+}
+
+func (s *HandlerSuite) SetupSuite() {
+	logging.SetLevel(zapcore.FatalLevel)
+}
+
+func (s *HandlerSuite) SetupTest() {
+	cfg, err := config.Load("")
+	s.NoError(err)
+
+	accountDB := &accountDBMock.AccountDB{}
+	accountDB.On("FindByEmail", mock.Anything, dUser.Email).Return(&dUser, nil)
+	jwtMiddleware, err := account.NewAuthMiddleware(cfg, accountDB)
+	s.NoError(err)
+	streamMiddleware, err := account.NewStreamAuthMiddleware(cfg, accountDB)
+	s.NoError(err)
+
+	gin.SetMode(gin.TestMode)
+	s.r = gin.Default()
+	s.hub = NewHub(cfg)
+	s.handler = NewHandler(cfg, s.hub, streamMiddleware)
+	RouteV1(cfg, s.handler, s.r, jwtMiddleware)
+	account.RouteV1(cfg, account.NewHandler(accountDB, notify.NewRegistry()), s.r, jwtMiddleware)
+	s.srv = httptest.NewServer(s.r)
+}
+
+func (s *HandlerSuite) TearDownTest() {
+	s.srv.Close()
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(HandlerSuite))
+}
+
+func (s *HandlerSuite) TestStream() {
+	// given
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := s.openStream(ctx, "/v1/api/stream?address=0x"+strings.ToUpper(wethAddress[2:]), http.StatusOK)
+	s.Equal(eventReady, (<-events).Type)
+
+	// when
+	s.hub.PublishPrices([]*PriceTick{{Address: wethAddress, Symbol: "WETH", USDPrice: 2000}})
+	s.hub.PublishAlert(dUser.ID, &AlertTrigger{Slug: "weth-above-1500", Title: "WETH above 1500"})
+	s.hub.PublishAlert(dUser.ID+1, &AlertTrigger{Slug: "other"})
+
+	// then
+	price := <-events
+	s.Equal(EventPrice, price.Type)
+	s.Equal("WETH", gjson.Get(price.Data, "symbol").String())
+	s.Equal(2000.0, gjson.Get(price.Data, "usdPrice").Float())
+	alert := <-events
+	s.Equal(EventAlert, alert.Type)
+	s.Equal("weth-above-1500", gjson.Get(alert.Data, "slug").String())
+}
+
+func (s *HandlerSuite) TestStream_Heartbeat() {
+	// given
+	s.handler.heartbeat = 10 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// when
+	events := s.openStream(ctx, "/v1/api/stream", http.StatusOK)
+
+	// then
+	s.Equal(eventReady, (<-events).Type)
+	heartbeat := <-events
+	s.Equal(eventHeartbeat, heartbeat.Type)
+	s.True(gjson.Get(heartbeat.Data, "time").Exists())
+}
+
+func (s *HandlerSuite) TestStream_DropSlowClient() {
+	// given
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := s.openStream(ctx, "/v1/api/stream", http.StatusOK)
+	s.Equal(eventReady, (<-events).Type)
+
+	// when
+	s.hub.mu.RLock()
+	var subscribers []*Subscriber
+	for sub := range s.hub.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	s.hub.mu.RUnlock()
+	s.Len(subscribers, 1)
+	// as the hub does once the buffer of the client is full
+	s.hub.Unsubscribe(subscribers[0])
+
+	// then
+	var last *sse
+	for e := range events {
+		last = e
+	}
+	s.NotNil(last)
+	s.Equal(eventDropped, last.Type)
+}
+
+func (s *HandlerSuite) TestStream_FailIfInvalidAddress() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s.openStream(ctx, "/v1/api/stream?address=0x1234", http.StatusBadRequest)
+	s.openStream(ctx, "/v1/api/stream?address="+strings.Repeat(wethAddress+"&address=", 50)+wethAddress, http.StatusBadRequest)
+}
+
+func (s *HandlerSuite) TestStream_FailIfAnonymous() {
+	// when
+	res, err := http.Get(s.srv.URL + "/v1/api/stream")
+
+	// then
+	s.NoError(err)
+	defer res.Body.Close()
+	s.Equal(http.StatusUnauthorized, res.StatusCode)
+}
+
+func (s *HandlerSuite) TestStream_FailIfSessionTokenInQuery() {
+	// when
+	res, err := http.Get(s.srv.URL + "/v1/api/stream?ticket=" + s.getToken())
+
+	// then
+	s.NoError(err)
+	defer res.Body.Close()
+	s.Equal(http.StatusUnauthorized, res.StatusCode)
+}
+
+func (s *HandlerSuite) TestTicket_FailIfUsedAsSessionToken() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/user/me", nil)
+	req.Header.Set("Authorization", "Bearer "+s.getTicket())
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusUnauthorized, res.Code)
+}
+
+func (s *HandlerSuite) TestTicket_FailIfAnonymous() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/stream/ticket", nil)
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusUnauthorized, res.Code)
+}
+
+type sse struct {
+	Type string
+	Data string
+}
+
+// openStream requests given path with a ticket in query as an EventSource does, asserts the status code
+// and returns a channel of events closed at the end of the stream
+func (s *HandlerSuite) openStream(ctx context.Context, path string, status int) <-chan *sse {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	req, _ := http.NewRequestWithContext(ctx, "GET", s.srv.URL+path+sep+"ticket="+s.getTicket(), nil)
+	res, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	s.Require().Equal(status, res.StatusCode)
+
+	events := make(chan *sse)
+	go func() {
+		defer close(events)
+		defer res.Body.Close()
+		scanner := bufio.NewScanner(res.Body)
+		var e sse
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				e.Type = strings.TrimPrefix(line, "event:")
+			case strings.HasPrefix(line, "data:"):
+				e.Data = strings.TrimPrefix(line, "data:")
+			case line == "" && e.Type != "":
+				event := e
+				select {
+				case events <- &event:
+				case <-ctx.Done():
+					return
+				}
+				e = sse{}
+			}
+		}
+	}()
+	return events
+}
+
+func (s *HandlerSuite) getTicket() string {
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/stream/ticket", nil)
+	req.Header.Set("Authorization", "Bearer "+s.getToken())
+	s.r.ServeHTTP(res, req)
+
+	s.Equal(http.StatusCreated, res.Code)
+	s.True(gjson.Get(res.Body.String(), "expire").Exists())
+	return gjson.Get(res.Body.String(), "ticket").String()
+}
+
+func (s *HandlerSuite) getToken() string {
+	body := map[string]interface{}{
+		"user": map[string]interface{}{
+			"email":    dUser.Email,
+			"password": dUserRawPass,
+		},
+	}
+	b, _ := json.Marshal(body)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/users/login", bytes.NewBuffer(b))
+	s.r.ServeHTTP(res, req)
+
+	s.Equal(http.StatusOK, res.Code)
+	return gjson.Get(res.Body.String(), "token").String()
+}
@@ -0,0 +1,150 @@
+package stream
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"kek-backend/internal/config"
+)
+
+// event types
+const (
+	EventPrice = "price"
+	EventAlert = "alert"
+)
+
+// Event is a message pushed to subscribers
+type Event struct {
+	Type string
+	Data interface{}
+}
+
+// PriceTick is a price of a token computed by the alert cron
+type PriceTick struct {
+	Address    string    `json:"address"`
+	Symbol     string    `json:"symbol"`
+	USDPrice   float64   `json:"usdPrice"`
+	EthPrice   float64   `json:"ethPrice"`
+	ObservedAt time.Time `json:"observedAt"`
+}
+
+// AlertTrigger is an event of an alert triggered for its owner
+type AlertTrigger struct {
+	Slug          string    `json:"slug"`
+	Title         string    `json:"title"`
+	Body          string    `json:"body"`
+	Condition     string    `json:"condition"`
+	ObservedPrice float64   `json:"observedPrice"`
+	TriggeredAt   time.Time `json:"triggeredAt"`
+}
+
+// Subscriber receives price ticks of its token addresses and alert triggers of its account
+type Subscriber struct {
+	accountId uint
+	addresses map[string]bool
+	events    chan *Event
+	dropped   chan struct{}
+}
+
+// Events returns the channel of events published to the subscriber
+func (s *Subscriber) Events() <-chan *Event {
+	return s.events
+}
+
+// Dropped returns a channel closed once the subscriber is removed from the hub
+func (s *Subscriber) Dropped() <-chan struct{} {
+	return s.dropped
+}
+
+// Hub fans out published events to subscribers without blocking publishers.
+// A subscriber whose buffer is full is dropped as too slow
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscriber]struct{}
+	bufferSize  int
+}
+
+// Subscribe adds a subscriber of an account with given id to price ticks of given token addresses
+func (h *Hub) Subscribe(accountId uint, addresses []string) *Subscriber {
+	s := &Subscriber{
+		accountId: accountId,
+		addresses: make(map[string]bool, len(addresses)),
+		events:    make(chan *Event, h.bufferSize),
+		dropped:   make(chan struct{}),
+	}
+	for _, address := range addresses {
+		s.addresses[strings.ToLower(address)] = true
+	}
+	h.mu.Lock()
+	h.subscribers[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+// Unsubscribe removes given subscriber from the hub
+func (h *Hub) Unsubscribe(s *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[s]; ok {
+		delete(h.subscribers, s)
+		close(s.dropped)
+	}
+}
+
+// PublishPrices publishes given ticks to subscribers of their token addresses
+func (h *Hub) PublishPrices(ticks []*PriceTick) {
+	h.publish(func(s *Subscriber) []*Event {
+		var events []*Event
+		for _, tick := range ticks {
+			if s.addresses[strings.ToLower(tick.Address)] {
+				events = append(events, &Event{Type: EventPrice, Data: tick})
+			}
+		}
+		return events
+	})
+}
+
+// PublishAlert publishes given alert trigger to subscribers of an account with given id
+func (h *Hub) PublishAlert(accountId uint, trigger *AlertTrigger) {
+	h.publish(func(s *Subscriber) []*Event {
+		if s.accountId != accountId {
+			return nil
+		}
+		return []*Event{{Type: EventAlert, Data: trigger}}
+	})
+}
+
+// publish sends events returned by match to each subscriber and drops subscribers which cannot keep up
+func (h *Hub) publish(match func(s *Subscriber) []*Event) {
+	var slow []*Subscriber
+	h.mu.RLock()
+	for s := range h.subscribers {
+	events:
+		for _, e := range match(s) {
+			select {
+			case s.events <- e:
+			default:
+				slow = append(slow, s)
+				break events
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, s := range slow {
+		h.Unsubscribe(s)
+	}
+}
+
+// NewHub creates a new hub with the buffer size of given config
+func NewHub(cfg *config.Config) *Hub {
+	bufferSize := cfg.StreamConfig.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = 64
+	}
+	return &Hub{
+		subscribers: make(map[*Subscriber]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
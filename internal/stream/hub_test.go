@@ -0,0 +1,90 @@
+package stream
+
+import (
+	"kek-backend/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub_PublishPrices(t *testing.T) {
+	// given
+	hub := NewHub(&config.Config{})
+	weth := hub.Subscribe(1, []string{"0xC02AAA39B223FE8D0A0E5C4F27EAD9083C756CC2"})
+	none := hub.Subscribe(2, nil)
+	tick := &PriceTick{Address: "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", Symbol: "WETH", USDPrice: 2000, ObservedAt: time.Now()}
+
+	// when
+	hub.PublishPrices([]*PriceTick{tick, {Address: "0x6b175474e89094c44da98b954eedeac495271d0f", Symbol: "DAI"}})
+
+	// then
+	assert.Len(t, weth.Events(), 1)
+	assert.Equal(t, &Event{Type: EventPrice, Data: tick}, <-weth.Events())
+	assert.Empty(t, none.Events())
+}
+
+func TestHub_PublishAlert(t *testing.T) {
+	// given
+	hub := NewHub(&config.Config{})
+	owner := hub.Subscribe(1, nil)
+	other := hub.Subscribe(2, nil)
+	trigger := &AlertTrigger{Slug: "weth-above-1500", Title: "WETH above 1500", TriggeredAt: time.Now()}
+
+	// when
+	hub.PublishAlert(1, trigger)
+
+	// then
+	assert.Len(t, owner.Events(), 1)
+	assert.Equal(t, &Event{Type: EventAlert, Data: trigger}, <-owner.Events())
+	assert.Empty(t, other.Events())
+}
+
+func TestHub_DropSlowSubscriber(t *testing.T) {
+	// given
+	hub := NewHub(&config.Config{StreamConfig: config.StreamConfig{BufferSize: 2}})
+	slow := hub.Subscribe(1, nil)
+	fast := hub.Subscribe(1, nil)
+
+	// when
+	for i := 0; i < 3; i++ {
+		hub.PublishAlert(1, &AlertTrigger{Slug: "weth-above-1500"})
+		if i < 2 {
+			<-fast.Events()
+		}
+	}
+
+	// then
+	select {
+	case <-slow.Dropped():
+	default:
+		assert.Fail(t, "slow subscriber is not dropped")
+	}
+	select {
+	case <-fast.Dropped():
+		assert.Fail(t, "fast subscriber is dropped")
+	default:
+	}
+	assert.Len(t, fast.Events(), 1)
+	// a dropped subscriber receives nothing more
+	<-slow.Events()
+	<-slow.Events()
+	hub.PublishAlert(1, &AlertTrigger{Slug: "weth-above-1500"})
+	assert.Empty(t, slow.Events())
+}
+
+func TestHub_Unsubscribe(t *testing.T) {
+	// given
+	hub := NewHub(&config.Config{})
+	sub := hub.Subscribe(1, nil)
+
+	// when
+	hub.Unsubscribe(sub)
+	hub.Unsubscribe(sub)
+
+	// then
+	hub.PublishAlert(1, &AlertTrigger{Slug: "weth-above-1500"})
+	assert.Empty(t, sub.Events())
+	_, open := <-sub.Dropped()
+	assert.False(t, open)
+}
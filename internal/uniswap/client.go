@@ -0,0 +1,152 @@
+package uniswap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"kek-backend/internal/config"
+	"kek-backend/pkg/logging"
+
+	"github.com/pkg/errors"
+)
+
+// Error is an error in the errors of a GraphQL response
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// Errors is returned if a GraphQL response has errors
+type Errors []Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Message
+	}
+	return "graphql: " + strings.Join(msgs, "; ")
+}
+
+// HTTPError is returned if a GraphQL response is not 2xx
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("graphql: status %d: %s", e.StatusCode, e.Body)
+}
+
+// maxErrorBody is the max length of a response body kept in HTTPError
+const maxErrorBody = 512
+
+type request struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type response struct {
+	Data   interface{} `json:"data"`
+	Errors Errors      `json:"errors"`
+}
+
+// Client is a GraphQL client of the uniswap subgraph.
+// A request is retried with jittered exponential backoff on a transport error, a 429 or a 5xx response.
+// It is safe for concurrent use
+type Client struct {
+	url        string
+	client     *http.Client
+	maxRetries int
+	backoff    time.Duration
+
+	// rngMu guards rng which is not safe for concurrent use
+	rngMu sync.Mutex
+	rng   *rand.Rand
+}
+
+// Query posts given query with variables and decodes the data of the response into result.
+// It returns Errors if the response has GraphQL errors and HTTPError if the response is not 2xx
+func (c *Client) Query(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	body, err := json.Marshal(&request{Query: query, Variables: variables})
+	if err != nil {
+		return errors.Wrap(err, "encode graphql request")
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := c.post(ctx, body, result)
+		if err == nil || !retry || attempt >= c.maxRetries {
+			return err
+		}
+		wait := c.jitter(backoff)
+		logging.FromContext(ctx).Debugw("uniswap.client retry a request", "attempt", attempt+1, "backoff", wait, "err", err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// post sends a request of given body and decodes the response into result.
+// It returns whether the request may succeed on a retry if failed
+func (c *Client) post(ctx context.Context, body []byte, result interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "create graphql request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, errors.Wrap(err, "send graphql request")
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return true, errors.Wrap(err, "read graphql response")
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		if len(data) > maxErrorBody {
+			data = data[:maxErrorBody]
+		}
+		retry := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+		return retry, &HTTPError{StatusCode: res.StatusCode, Body: string(data)}
+	}
+
+	gqlRes := response{Data: result}
+	if err := json.Unmarshal(data, &gqlRes); err != nil {
+		return false, errors.Wrap(err, "decode graphql response")
+	}
+	if len(gqlRes.Errors) > 0 {
+		return false, gqlRes.Errors
+	}
+	return false, nil
+}
+
+// jitter returns a random duration between the half of given backoff and the backoff
+func (c *Client) jitter(backoff time.Duration) time.Duration {
+	half := int64(backoff / 2)
+	c.rngMu.Lock()
+	defer c.rngMu.Unlock()
+	return time.Duration(half + c.rng.Int63n(half+1))
+}
+
+// NewClient creates a new client of the uniswap subgraph with given config
+func NewClient(cfg config.UniswapConfig) *Client {
+	return &Client{
+		url:        cfg.URL,
+		client:     &http.Client{Timeout: time.Duration(cfg.TimeoutSecs) * time.Second},
+		maxRetries: cfg.MaxRetries,
+		backoff:    time.Duration(cfg.BackoffMillis) * time.Millisecond,
+		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
@@ -0,0 +1,205 @@
+package uniswap
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kek-backend/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// graphStub is a GraphQL server stub responding by given handle with the number of calls,
+// recording received requests
+type graphStub struct {
+	*httptest.Server
+	calls    int32
+	requests chan request
+}
+
+func newGraphStub(t *testing.T, handle func(calls int32, w http.ResponseWriter)) *graphStub {
+	stub := &graphStub{requests: make(chan request, 10)}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req request
+		assert.NoError(t, json.Unmarshal(body, &req))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		stub.requests <- req
+		handle(atomic.AddInt32(&stub.calls, 1), w)
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+func newTestClient(url string) *Client {
+	return NewClient(config.UniswapConfig{URL: url, TimeoutSecs: 1, MaxRetries: 2, BackoffMillis: 1})
+}
+
+func TestClient_Bundles(t *testing.T) {
+	// given
+	stub := newGraphStub(t, func(_ int32, w http.ResponseWriter) {
+		w.Write([]byte(`{"data":{"bundles":[{"ethPrice":"2000.5"}]}}`))
+	})
+
+	// when
+	bundles, err := newTestClient(stub.URL).Bundles(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, bundles.Bundles, 1)
+	assert.Equal(t, "2000.5", bundles.Bundles[0].EthPrice)
+	req := <-stub.requests
+	assert.Contains(t, req.Query, "bundles")
+	assert.Nil(t, req.Variables)
+}
+
+func TestClient_Tokens(t *testing.T) {
+	// given
+	stub := newGraphStub(t, func(_ int32, w http.ResponseWriter) {
+		w.Write([]byte(`{"data":{"tokens":[{"id":"0xabc","name":"Token","symbol":"TKN","derivedETH":"0.5","totalLiquidity":"100"}]}}`))
+	})
+
+	// when
+	tokens, err := newTestClient(stub.URL).Tokens(context.Background(), []string{"0xABC", "0xdef"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []Token{{Id: "0xabc", Name: "Token", Symbol: "TKN", DerivedETH: "0.5", TotalLiquidity: "100"}}, tokens.Tokens)
+	req := <-stub.requests
+	assert.Contains(t, req.Query, "tokens")
+	assert.Equal(t, []interface{}{"0xabc", "0xdef"}, req.Variables["ids"])
+	assert.Equal(t, 2.0, req.Variables["first"])
+}
+
+func TestClient_Tokens_FailIfTooMany(t *testing.T) {
+	_, err := newTestClient("http://localhost").Tokens(context.Background(), make([]string, MaxTokensPerQuery+1))
+
+	assert.Error(t, err)
+}
+
+func TestClient_FailIfGraphQLErrors(t *testing.T) {
+	// given
+	stub := newGraphStub(t, func(_ int32, w http.ResponseWriter) {
+		w.Write([]byte(`{"data":null,"errors":[{"message":"indexing error","path":["bundles"]},{"message":"timeout"}]}`))
+	})
+
+	// when
+	_, err := newTestClient(stub.URL).Bundles(context.Background())
+
+	// then
+	gqlErrs, ok := err.(Errors)
+	assert.True(t, ok)
+	assert.Equal(t, Errors{{Message: "indexing error", Path: []interface{}{"bundles"}}, {Message: "timeout"}}, gqlErrs)
+	assert.EqualError(t, err, "graphql: indexing error; timeout")
+	// not retried
+	assert.Equal(t, int32(1), atomic.LoadInt32(&stub.calls))
+}
+
+func TestClient_Retry(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Status int
+	}{
+		{Name: "5xx", Status: http.StatusBadGateway},
+		{Name: "429", Status: http.StatusTooManyRequests},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// given
+			stub := newGraphStub(t, func(calls int32, w http.ResponseWriter) {
+				if calls < 3 {
+					w.WriteHeader(tc.Status)
+					return
+				}
+				w.Write([]byte(`{"data":{"bundles":[{"ethPrice":"2000"}]}}`))
+			})
+
+			// when
+			bundles, err := newTestClient(stub.URL).Bundles(context.Background())
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, "2000", bundles.Bundles[0].EthPrice)
+			assert.Equal(t, int32(3), atomic.LoadInt32(&stub.calls))
+		})
+	}
+}
+
+func TestClient_FailIfRetriesExhausted(t *testing.T) {
+	// given
+	stub := newGraphStub(t, func(_ int32, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("unavailable"))
+	})
+
+	// when
+	_, err := newTestClient(stub.URL).Bundles(context.Background())
+
+	// then
+	assert.Equal(t, &HTTPError{StatusCode: http.StatusServiceUnavailable, Body: "unavailable"}, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&stub.calls))
+}
+
+func TestClient_NoRetryIf4xx(t *testing.T) {
+	// given
+	stub := newGraphStub(t, func(_ int32, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	// when
+	_, err := newTestClient(stub.URL).Bundles(context.Background())
+
+	// then
+	httpErr, ok := err.(*HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&stub.calls))
+}
+
+func TestClient_FailIfTransportError(t *testing.T) {
+	// given
+	stub := newGraphStub(t, func(_ int32, w http.ResponseWriter) {})
+	stub.Close()
+
+	// when
+	_, err := newTestClient(stub.URL).Bundles(context.Background())
+
+	// then
+	assert.Error(t, err)
+}
+
+func TestClient_StopRetryIfContextDone(t *testing.T) {
+	// given
+	stub := newGraphStub(t, func(_ int32, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	client := NewClient(config.UniswapConfig{URL: stub.URL, TimeoutSecs: 1, MaxRetries: 5, BackoffMillis: 60000})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// when
+	start := time.Now()
+	_, err := client.Bundles(ctx)
+
+	// then
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&stub.calls))
+}
+
+func TestJitter(t *testing.T) {
+	client := NewClient(config.UniswapConfig{})
+	for i := 0; i < 100; i++ {
+		d := client.jitter(100 * time.Millisecond)
+		assert.GreaterOrEqual(t, int64(d), int64(50*time.Millisecond))
+		assert.LessOrEqual(t, int64(d), int64(100*time.Millisecond))
+	}
+	assert.Equal(t, time.Duration(0), client.jitter(0))
+}
@@ -1,32 +1,57 @@
 package uniswap
 
 import (
+	"context"
 	"fmt"
+	"strings"
 )
 
-func QueryBundles() map[string]string {
-	return map[string]string{
-		"query": `
-			query bundles {
-				bundles(where: { id: "1" }) {
-					ethPrice
-				}
-			}
-		`,
+// MaxTokensPerQuery is the max number of tokens fetched by a query
+const MaxTokensPerQuery = 100
+
+const bundlesQuery = `
+	query bundles {
+		bundles(where: { id: "1" }) {
+			ethPrice
+		}
 	}
-}
+`
 
-func QueryToken(address string) map[string]string {
-	query := fmt.Sprintf(`
-		query tokens {
-			tokens(where: { id: "%s" }) {
-				id
-				name
-				symbol
-				derivedETH
-				totalLiquidity
-			}
+const tokensQuery = `
+	query tokens($ids: [ID!]!, $first: Int!) {
+		tokens(first: $first, where: { id_in: $ids }) {
+			id
+			name
+			symbol
+			derivedETH
+			totalLiquidity
 		}
-	`, address)
-	return map[string]string{"query": query}
+	}
+`
+
+// Bundles fetches the bundle of the ETH/USD price
+func (c *Client) Bundles(ctx context.Context) (*Bundles, error) {
+	var bundles Bundles
+	if err := c.Query(ctx, bundlesQuery, nil, &bundles); err != nil {
+		return nil, err
+	}
+	return &bundles, nil
+}
+
+// Tokens fetches tokens with given addresses.
+// addresses must not be greater than MaxTokensPerQuery
+func (c *Client) Tokens(ctx context.Context, addresses []string) (*Tokens, error) {
+	if len(addresses) > MaxTokensPerQuery {
+		return nil, fmt.Errorf("too many tokens %d, must not be greater than %d", len(addresses), MaxTokensPerQuery)
+	}
+	ids := make([]string, len(addresses))
+	for i, address := range addresses {
+		ids[i] = strings.ToLower(address)
+	}
+	var tokens Tokens
+	variables := map[string]interface{}{"ids": ids, "first": len(ids)}
+	if err := c.Query(ctx, tokensQuery, variables, &tokens); err != nil {
+		return nil, err
+	}
+	return &tokens, nil
 }
@@ -1,21 +1,21 @@
 package uniswap
 
+// Bundles is the data of a bundles query
 type Bundles struct {
-	Data struct {
-		Bundles []struct {
-			EthPrice string `json:"ethPrice"`
-		} `json:"bundles"`
-	} `json:"data"`
+	Bundles []struct {
+		EthPrice string `json:"ethPrice"`
+	} `json:"bundles"`
 }
 
+// Tokens is the data of a tokens query
 type Tokens struct {
-	Data struct {
-		Tokens []struct {
-			Id             string `json:"id"`
-			Name           string `json:"name"`
-			Symbol         string `json:"symbol"`
-			DerivedETH     string `json:"derivedETH"`
-			TotalLiquidity string `json:"totalLiquidity"`
-		} `json:"tokens"`
-	} `json:"data"`
+	Tokens []Token `json:"tokens"`
+}
+
+type Token struct {
+	Id             string `json:"id"`
+	Name           string `json:"name"`
+	Symbol         string `json:"symbol"`
+	DerivedETH     string `json:"derivedETH"`
+	TotalLiquidity string `json:"totalLiquidity"`
 }
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS price_snapshots;
\ No newline at end of file
@@ -0,0 +1,13 @@
+-- price snapshot
+CREATE TABLE price_snapshots (
+	id serial PRIMARY KEY,
+	token_address VARCHAR ( 100 ) NOT NULL,
+	derived_eth DOUBLE PRECISION NOT NULL,
+	eth_price DOUBLE PRECISION NOT NULL,
+	usd_price DOUBLE PRECISION NOT NULL,
+	total_liquidity DOUBLE PRECISION NOT NULL,
+	observed_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX price_snapshots_token_address_observed_at ON price_snapshots (token_address, observed_at);
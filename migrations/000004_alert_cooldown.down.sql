@@ -0,0 +1,4 @@
+ALTER TABLE alerts
+	DROP COLUMN cooldown_secs,
+	DROP COLUMN rearm_margin,
+	DROP COLUMN last_triggered_at;
@@ -0,0 +1,5 @@
+-- alert cooldown and re-arm hysteresis
+ALTER TABLE alerts
+	ADD COLUMN cooldown_secs BIGINT NOT NULL DEFAULT 0,
+	ADD COLUMN rearm_margin DOUBLE PRECISION NOT NULL DEFAULT 0,
+	ADD COLUMN last_triggered_at TIMESTAMP NULL;
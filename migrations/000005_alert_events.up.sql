@@ -0,0 +1,14 @@
+-- alert event
+CREATE TABLE alert_events (
+	id serial PRIMARY KEY,
+	alert_id INTEGER NOT NULL,
+	triggered_at TIMESTAMP NOT NULL,
+	observed_price DOUBLE PRECISION NOT NULL,
+	condition TEXT NOT NULL,
+	delivery_status VARCHAR ( 10 ) NOT NULL,
+	delivery_error TEXT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX alert_events_alert_id_triggered_at ON alert_events (alert_id, triggered_at);
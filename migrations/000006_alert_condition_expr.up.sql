@@ -0,0 +1,2 @@
+-- alert condition expression
+ALTER TABLE alerts ADD COLUMN condition_expr TEXT NULL;
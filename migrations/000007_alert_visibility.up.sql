@@ -0,0 +1,2 @@
+-- alert visibility
+ALTER TABLE alerts ADD COLUMN visibility VARCHAR ( 10 ) NOT NULL DEFAULT 'private';
@@ -0,0 +1,8 @@
+-- alert subscription
+CREATE TABLE alert_subscriptions (
+	id serial PRIMARY KEY,
+	alert_id INTEGER NOT NULL,
+	account_id INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	UNIQUE ( alert_id, account_id )
+);
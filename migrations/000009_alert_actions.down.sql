@@ -0,0 +1 @@
+ALTER TABLE alerts ALTER COLUMN alert_actions TYPE VARCHAR ( 20 );
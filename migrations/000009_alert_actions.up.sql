@@ -0,0 +1,6 @@
+-- alert actions list notification channels separated by comma
+ALTER TABLE alerts ALTER COLUMN alert_actions TYPE VARCHAR ( 255 );
+-- every alert was pushed before actions were dispatched by name, so unknown legacy values become push
+UPDATE alerts SET alert_actions = 'push'
+WHERE NOT ( string_to_array(replace(lower(alert_actions), ' ', ''), ',') <@ ARRAY['push', 'email', 'webhook'] )
+   OR replace(alert_actions, ' ', '') = '';
@@ -0,0 +1,3 @@
+ALTER TABLE accounts
+	DROP COLUMN webhook_url,
+	DROP COLUMN webhook_secret;
@@ -0,0 +1,4 @@
+-- account webhook for alert notifications
+ALTER TABLE accounts
+	ADD COLUMN webhook_url VARCHAR ( 255 ) NULL,
+	ADD COLUMN webhook_secret VARCHAR ( 255 ) NULL;
@@ -0,0 +1,34 @@
+-- notification outbox of alert events
+CREATE TABLE notifications (
+	id serial PRIMARY KEY,
+	alert_event_id INTEGER NOT NULL,
+	alert_id INTEGER NOT NULL,
+	account_id INTEGER NOT NULL,
+	action VARCHAR ( 20 ) NOT NULL,
+	title VARCHAR ( 100 ) NOT NULL,
+	body TEXT NULL,
+	slug VARCHAR ( 100 ) NOT NULL,
+	condition TEXT NOT NULL,
+	observed_price DOUBLE PRECISION NOT NULL,
+	triggered_at TIMESTAMP NOT NULL,
+	status VARCHAR ( 10 ) NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMP NOT NULL,
+	last_error TEXT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX notifications_status_next_attempt_at ON notifications (status, next_attempt_at);
+CREATE INDEX notifications_alert_event_id ON notifications (alert_event_id);
+
+-- delivery attempts of notifications
+CREATE TABLE notification_attempts (
+	id serial PRIMARY KEY,
+	notification_id INTEGER NOT NULL,
+	attempted_at TIMESTAMP NOT NULL,
+	error TEXT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX notification_attempts_notification_id ON notification_attempts (notification_id);
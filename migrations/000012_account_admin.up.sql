@@ -0,0 +1,2 @@
+-- admin accounts may manage notifications of all accounts
+ALTER TABLE accounts ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,10 @@
+ALTER TABLE accounts ADD COLUMN token VARCHAR ( 255 ) NULL;
+
+-- keep the last seen device of accounts
+UPDATE accounts SET token = d.token
+FROM (
+	SELECT DISTINCT ON ( account_id ) account_id, token FROM account_devices ORDER BY account_id, last_seen_at DESC
+) d
+WHERE accounts.id = d.account_id;
+
+DROP TABLE IF EXISTS account_devices;
@@ -0,0 +1,18 @@
+-- devices of accounts receiving push notifications
+CREATE TABLE account_devices (
+	id serial PRIMARY KEY,
+	account_id INTEGER NOT NULL,
+	platform VARCHAR ( 10 ) NOT NULL,
+	token VARCHAR ( 255 ) NOT NULL UNIQUE,
+	created_at TIMESTAMP NOT NULL,
+	last_seen_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX account_devices_account_id ON account_devices (account_id);
+
+-- move the single token of accounts to devices of unknown platform
+INSERT INTO account_devices (account_id, platform, token, created_at, last_seen_at)
+SELECT id, 'unknown', token, now(), now() FROM accounts WHERE token IS NOT NULL AND token <> ''
+ON CONFLICT ( token ) DO NOTHING;
+
+ALTER TABLE accounts DROP COLUMN token;
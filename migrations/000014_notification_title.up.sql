@@ -0,0 +1,2 @@
+-- titles of notifications are rendered from alert title templates with live values
+ALTER TABLE notifications ALTER COLUMN title TYPE TEXT;
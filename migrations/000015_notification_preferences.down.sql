@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS notifications_account_id_status;
+
+ALTER TABLE notifications DROP COLUMN critical;
+ALTER TABLE alerts DROP COLUMN critical;
+
+ALTER TABLE accounts
+	DROP COLUMN default_channels,
+	DROP COLUMN timezone,
+	DROP COLUMN quiet_hours,
+	DROP COLUMN max_per_hour;
@@ -0,0 +1,12 @@
+-- notification preferences of accounts
+ALTER TABLE accounts
+	ADD COLUMN default_channels VARCHAR ( 255 ) NULL,
+	ADD COLUMN timezone VARCHAR ( 64 ) NULL,
+	ADD COLUMN quiet_hours VARCHAR ( 255 ) NULL,
+	ADD COLUMN max_per_hour INTEGER NOT NULL DEFAULT 0;
+
+-- critical alerts are delivered in quiet hours and over the rate limit
+ALTER TABLE alerts ADD COLUMN critical BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE notifications ADD COLUMN critical BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX notifications_account_id_status ON notifications (account_id, status, updated_at);
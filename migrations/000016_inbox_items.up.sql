@@ -0,0 +1,18 @@
+-- delivered alert notifications kept in the in-app inbox of accounts
+CREATE TABLE inbox_items (
+	id serial PRIMARY KEY,
+	account_id INTEGER NOT NULL,
+	alert_event_id INTEGER NOT NULL,
+	alert_id INTEGER NOT NULL,
+	slug VARCHAR ( 255 ) NOT NULL,
+	title TEXT NOT NULL,
+	body TEXT NOT NULL,
+	condition TEXT NOT NULL,
+	observed_price DOUBLE PRECISION NOT NULL,
+	triggered_at TIMESTAMP NOT NULL,
+	read_at TIMESTAMP NULL,
+	created_at TIMESTAMP NOT NULL,
+	UNIQUE ( account_id, alert_event_id )
+);
+
+CREATE INDEX inbox_items_account_id_unread ON inbox_items (account_id, id) WHERE read_at IS NULL;
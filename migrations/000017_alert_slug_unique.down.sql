@@ -0,0 +1 @@
+DROP INDEX IF EXISTS alerts_slug_not_deleted;
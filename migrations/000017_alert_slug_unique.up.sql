@@ -0,0 +1,9 @@
+-- suffix duplicated slugs of not deleted alerts with their id to keep the oldest alert on the slug
+UPDATE alerts a SET slug = LEFT(a.slug, 90) || '-' || a.id
+WHERE a.deleted_at_unix = 0 AND EXISTS (
+	SELECT 1 FROM alerts b WHERE b.slug = a.slug AND b.deleted_at_unix = 0 AND b.id < a.id
+);
+
+-- a slug identifies an alert of any account, so it is unique among not deleted alerts and reused once deleted.
+-- a new alert whose slug is taken is saved with a random suffix
+CREATE UNIQUE INDEX alerts_slug_not_deleted ON alerts (slug) WHERE deleted_at_unix = 0;